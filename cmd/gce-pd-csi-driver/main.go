@@ -21,17 +21,21 @@ import (
 	"flag"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"os"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/strings/slices"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
 	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
 	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
 	driver "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-pd-csi-driver"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/metrics"
@@ -95,6 +99,59 @@ var (
 
 	extraTagsStr = flag.String("extra-tags", "", "Extra tags to attach to each Compute Disk, Image, Snapshot created. It is a comma separated list of parent id, key and value like '<parent_id1>/<tag_key1>/<tag_value1>,...,<parent_idN>/<tag_keyN>/<tag_valueN>'. parent_id is the Organization or the Project ID or Project name where the tag key and the tag value resources exist. A maximum of 50 tags bindings is allowed for a resource. See https://cloud.google.com/resource-manager/docs/tags/tags-overview, https://cloud.google.com/resource-manager/docs/tags/tags-creating-and-managing for details")
 
+	enableTagReconcilerFlag       = flag.Bool("enable-tag-reconciler", false, "If set to true, resource manager tags are bound to snapshots and images asynchronously, and the tag bindings of created disks, snapshots and images are retried and re-checked until they match the requested tags")
+	tagReconcilerQueueFileFlag    = flag.String("tag-reconciler-queue-file", "", "Path of the file where the tag reconciler persists its retry queue. If empty, the queue is kept in memory and lost on restart")
+	tagReconcilerResyncPeriodFlag = flag.Duration("tag-reconciler-resync-period", 5*time.Minute, "How often the tag reconciler re-checks the tag bindings of queued resources")
+	tagReconcilerListPeriodFlag   = flag.Duration("tag-reconciler-list-period", time.Hour, "How often the tag reconciler lists the disks, snapshots and images created by the driver, and binds the --extra-tags they miss. If 0, only the resources queued when created are reconciled")
+
+	computeAPIReadQPSFlag            = flag.Float64("compute-api-read-qps", 0, "Client-side budget of read calls per second to the compute API, for each project and method class (e.g. disks, instances or operations). 0 disables the read budget")
+	computeAPIReadBurstFlag          = flag.Int("compute-api-read-burst", 20, "Burst of the client-side budget of read calls to the compute API")
+	computeAPIMutateQPSFlag          = flag.Float64("compute-api-mutate-qps", 0, "Client-side budget of mutating calls per second to the compute API, for each project and method class. 0 disables the mutate budget")
+	computeAPIMutateBurstFlag        = flag.Int("compute-api-mutate-burst", 10, "Burst of the client-side budget of mutating calls to the compute API")
+	computeAPIClassBudgetsFlag       = flag.String("compute-api-class-budgets", "", "Comma separated budgets of specific compute API method classes, overriding the read and mutate budgets, like '<method class>.<read|mutate>=<qps>[:<burst>]', e.g. 'instances.mutate=5:10,operations.read=20'")
+	computeAPILowPriorityReserveFlag = flag.Float64("compute-api-low-priority-reserve", 0.5, "Fraction of each compute API budget that low priority calls, e.g. those listing volumes, leave to attach, detach and other calls")
+
+	computeAPIMaxAttemptsFlag      = flag.Int("compute-api-max-attempts", 3, "Maximum number of attempts of compute API calls failing with transient or throttling errors. Only reads, waits on operations and mutations with a request ID are retried. 1 disables retries")
+	computeAPIRetryBackoffFlag     = flag.Duration("compute-api-retry-initial-backoff", 500*time.Millisecond, "Delay before the first retry of a compute API call, doubled for each following retry")
+	computeAPIRetryMaxBackoffFlag  = flag.Duration("compute-api-retry-max-backoff", 8*time.Second, "Maximum delay between retries of a compute API call")
+	computeAPIBreakerThresholdFlag = flag.Int("compute-api-circuit-breaker-threshold", 0, "Number of consecutive transient compute API failures in a zone or region after which calls to it fail fast with Unavailable. 0 disables the circuit breakers")
+	computeAPIBreakerCooldownFlag  = flag.Duration("compute-api-circuit-breaker-cooldown", 30*time.Second, "How long calls to a zone or region fail fast before a call is let through to probe it")
+
+	operationJournalNamespaceFlag = flag.String("operation-journal-namespace", "", "Namespace of the ConfigMaps, one per in-flight operation, where the controller records its in-flight GCE operations, so that a restarted controller waits for them instead of starting conflicting ones. If empty, in-flight operations are kept in memory and lost on restart")
+
+	volumeLocksLeaseNamespaceFlag = flag.String("volume-locks-lease-namespace", "", "Namespace of the coordination.k8s.io Leases used as volume locks shared by the replicas of the controller, allowing the leader elected sidecars of different replicas to operate on the same volumes. Each sidecar must still be leader elected. If empty, volume locks are in-process and only one replica of the controller may be active")
+	volumeLocksLeaseDurationFlag  = flag.Duration("volume-locks-lease-duration", 30*time.Second, "How long the volume lock of a replica that stopped renewing it, e.g. because it crashed, blocks the volume before another replica takes it over")
+
+	volumeLockMaxWaitFlag           = flag.Duration("volume-lock-max-wait", 0, "How long an operation on a volume waits, in FIFO order, for the lock held by another operation on the volume before failing with Aborted. The wait is also bounded by half of the time left before the deadline of the operation. 0 fails the operation right away")
+	volumeLockLongHeldThresholdFlag = flag.Duration("volume-lock-long-held-threshold", 5*time.Minute, "Hold time past which the lock of a volume is logged as held too long. 0 disables the logs")
+
+	allowedVolumeProjectsFlag = flag.String("allowed-volume-projects", "", "Comma separated list of the projects of the disks and instances the controller operates on. Requests for volume handles or node IDs in other projects fail with PermissionDenied. If empty, all projects are allowed")
+	allowedVolumeZonesFlag    = flag.String("allowed-volume-zones", "", "Comma separated list of the zones of the disks and instances the controller operates on. Regional disks are allowed if one of their zones is listed. If empty along with --allowed-volume-regions, all locations are allowed")
+	allowedVolumeRegionsFlag  = flag.String("allowed-volume-regions", "", "Comma separated list of the regions of the disks and instances the controller operates on, including the zones of the regions. If empty along with --allowed-volume-zones, all locations are allowed")
+	requiredDiskLabelsFlag    = flag.String("required-disk-labels", "", "Labels the disks the controller attaches, detaches, expands, modifies, snapshots or clones must have. It is a comma separated list of key value pairs like '<key1>=<value1>,<key2>=<value2>'")
+
+	defaultFsckPolicyFlag = flag.String("default-fsck-policy", common.FsckPolicyOnDirty, "Filesystem check run by the node service when it stages a formatted volume, unless the StorageClass parameter or mount flag fsck-policy is set. One of never, on-dirty (fsck -a on read-write mounts), always-readonly-check (fsck -n, errors are reported as a volume condition) or repair (fsck -y on read-write mounts)")
+
+	diskTuningProfilesFileFlag = flag.String("disk-tuning-profiles-file", "", "Path of a JSON file mapping disk types to the mount flags applied by default to their volumes by the node service, like '{\"hyperdisk-extreme\": [\"read_ahead_kb=0\", \"queue/scheduler=none\", \"noatime\"]}'. The mount flags of the volume take precedence. If empty, no tuning profile is applied")
+
+	enableFstrimSchedulerFlag = flag.Bool("enable-fstrim-scheduler", false, "If set to true, the node service periodically trims the filesystems of the staged volumes with the fstrim-interval StorageClass parameter or mount flag, and on demand on the --debug-http-endpoint")
+	fstrimMaxConcurrentFlag   = flag.Int("fstrim-max-concurrent", 1, "The maximum number of filesystem trims running at the same time on a node. Used only if --enable-fstrim-scheduler")
+
+	debugHttpEndpointFlag = flag.String("debug-http-endpoint", "", "The TCP network address where the node service returns the holders of the device of a staged volume on GET requests to /device-holders?volume_id=<volume ID>, and, if --enable-fstrim-scheduler is set, trims the filesystem of a staged volume on POST requests to /fstrim?volume_id=<volume ID> (example: `127.0.0.1:9810`). Requests are not authenticated, so it should not be reachable from outside of the node. If empty, these requests are not served")
+
+	kubeletRootDirFlag              = flag.String("kubelet-root-dir", "/var/lib/kubelet", "The root directory of kubelet, in which the node service looks for stale staging and publish directories of volumes")
+	enableStaleMountReconcilerFlag  = flag.Bool("enable-stale-mount-reconciler", false, "If set to true, the node service lazily unmounts and removes the staging and publish directories of volumes whose disk is no longer attached in --kubelet-root-dir, on startup and every --stale-mount-reconcile-interval")
+	staleMountReconcileIntervalFlag = flag.Duration("stale-mount-reconcile-interval", 10*time.Minute, "How often the node service looks for stale staging and publish directories. If 0, it only looks for them on startup. Used only if --enable-stale-mount-reconciler")
+	staleMountDryRunFlag            = flag.Bool("stale-mount-reconciler-dry-run", true, "If set to true, the stale staging and publish directories are only logged, and left as is. Set it to false to clean them up. Used only if --enable-stale-mount-reconciler")
+
+	nodeIDIncludesInstanceIDFlag = flag.Bool("node-id-include-instance-id", false, "If set to true, the node ID reported by the node service is qualified with the numeric ID of the instance, so that the controller does not attach disks to, or detach them from, another instance recreated with the same name. It must be set on the controller too, so that ListVolumes reports the same node IDs. Changing it requires the CSINode object of the node to be re-registered")
+
+	enableExtraMetadataReconcilerFlag      = flag.Bool("enable-extra-metadata-reconciler", false, "If set to true, the --extra-labels and --extra-tags missing from existing disks, snapshots and images created by the driver are periodically added, see --extra-metadata-reconcile-update-values for the ones set to other values")
+	extraMetadataReconcileIntervalFlag     = flag.Duration("extra-metadata-reconcile-interval", time.Hour, "How often existing resources are checked for stale extra labels and tags")
+	extraMetadataReconcileDryRunFlag       = flag.Bool("extra-metadata-reconcile-dry-run", true, "If set to true, stale extra labels and tags are only logged, and no resource is updated")
+	extraMetadataReconcileUpdateValuesFlag = flag.Bool("extra-metadata-reconcile-update-values", false, "If set to true, the labels and tags of existing resources set to other values than --extra-labels and --extra-tags are overwritten. Otherwise they are only logged, as they may be set on purpose, e.g. by the labels parameter of a StorageClass")
+	extraMetadataReconcileQPSFlag          = flag.Float64("extra-metadata-reconcile-qps", 1, "Maximum rate of the label and tag API calls made to reconcile extra labels and tags")
+
 	diskTopology = flag.Bool("disk-topology", false, "If set to true, the driver will add a disk-type.gke.io/[disk-type] topology label when the StorageClass has the use-allowed-disk-topology parameter set to true. That topology label is included in the Topologies returned in CreateVolumeResponse. This flag is disabled by default.")
 
 	version string
@@ -160,6 +217,8 @@ func handle() {
 		switch {
 		case *runControllerService:
 			mm.RegisterPDCSIMetric()
+			mm.GetRegistry().MustRegister(gce.CloudProviderMetrics()...)
+			mm.RegisterVolumeLockMetrics()
 			if metrics.IsGKEComponentVersionAvailable() {
 				mm.EmitGKEComponentVersion()
 			}
@@ -168,6 +227,9 @@ func handle() {
 				klog.Errorf("Failed to emit process start time: %v", err.Error())
 			}
 			mm.RegisterMountMetric()
+			mm.RegisterFsckMetrics()
+			mm.RegisterFstrimMetrics()
+			mm.RegisterVolumeLockMetrics()
 		}
 		metricsManager = &mm
 	}
@@ -213,6 +275,37 @@ func handle() {
 		UseInstancesAPIForDiskTypes: useInstanceAPIOnWaitForAttachDiskTypes,
 	}
 
+	tagReconcilerConfig := gce.TagReconcilerConfig{
+		Enabled:      *enableTagReconcilerFlag,
+		QueueFile:    *tagReconcilerQueueFileFlag,
+		ResyncPeriod: *tagReconcilerResyncPeriodFlag,
+		ListPeriod:   *tagReconcilerListPeriodFlag,
+		DriverName:   driverName,
+		ExtraTags:    extraTags,
+	}
+
+	computeAPIClassBudgets, err := gce.ParseAPIClassBudgets(*computeAPIClassBudgetsFlag)
+	if err != nil {
+		klog.Fatalf("Bad compute-api-class-budgets: %v", err.Error())
+	}
+	if *computeAPILowPriorityReserveFlag < 0 || *computeAPILowPriorityReserveFlag > 1 {
+		klog.Fatalf("compute-api-low-priority-reserve must be between 0 and 1, got %v", *computeAPILowPriorityReserveFlag)
+	}
+	apiBudgetConfig := gce.APIBudgetConfig{
+		Read:               gce.APIBudget{QPS: *computeAPIReadQPSFlag, Burst: *computeAPIReadBurstFlag},
+		Mutate:             gce.APIBudget{QPS: *computeAPIMutateQPSFlag, Burst: *computeAPIMutateBurstFlag},
+		ClassBudgets:       computeAPIClassBudgets,
+		LowPriorityReserve: *computeAPILowPriorityReserveFlag,
+	}
+
+	retryConfig := gce.RetryConfig{
+		MaxAttempts:      *computeAPIMaxAttemptsFlag,
+		InitialBackoff:   *computeAPIRetryBackoffFlag,
+		MaxBackoff:       *computeAPIRetryMaxBackoffFlag,
+		BreakerThreshold: *computeAPIBreakerThresholdFlag,
+		BreakerCooldown:  *computeAPIBreakerCooldownFlag,
+	}
+
 	// Initialize listVolumes config
 	instancesListFilters := parseCSVFlag(*instancesListFiltersFlag)
 	listInstancesConfig := gce.ListInstancesConfig{
@@ -230,10 +323,15 @@ func handle() {
 		SupportsThroughputChange: supportsThroughputChange,
 	}
 
+	volumeLockWait := common.VolumeLockWaitConfig{
+		MaxWait:           *volumeLockMaxWaitFlag,
+		LongHeldThreshold: *volumeLockLongHeldThresholdFlag,
+	}
+
 	// Initialize requirements for the controller service
 	var controllerServer *driver.GCEControllerServer
 	if *runControllerService {
-		cloudProvider, err := gce.CreateCloudProvider(ctx, version, *cloudConfigFilePath, computeEndpoint, computeEnvironment, waitForAttachConfig, listInstancesConfig, *enableMultitenancyFlag)
+		cloudProvider, err := gce.CreateCloudProvider(ctx, version, *cloudConfigFilePath, computeEndpoint, computeEnvironment, waitForAttachConfig, listInstancesConfig, tagReconcilerConfig, apiBudgetConfig, retryConfig, *enableMultitenancyFlag)
 		if err != nil {
 			klog.Fatalf("Failed to get cloud provider: %v", err.Error())
 		}
@@ -243,12 +341,56 @@ func handle() {
 			defer cancel()
 			go cloudProvider.TenantInformer.Run(ctx.Done())
 		}
+		if cloudProvider.TagReconciler != nil {
+			go cloudProvider.TagReconciler.Run(ctx)
+		}
+		if *operationJournalNamespaceFlag != "" {
+			journal, err := newConfigMapOperationJournal(*operationJournalNamespaceFlag)
+			if err != nil {
+				klog.Fatalf("Failed to set up the operation journal: %v", err.Error())
+			}
+			cloudProvider.OperationJournal = journal
+		}
+		if *enableExtraMetadataReconcilerFlag {
+			reconciler := driver.NewExtraMetadataReconciler(cloudProvider, driverName, extraVolumeLabels, extraTags, driver.ExtraMetadataReconcilerConfig{
+				Interval:     *extraMetadataReconcileIntervalFlag,
+				DryRun:       *extraMetadataReconcileDryRunFlag,
+				UpdateValues: *extraMetadataReconcileUpdateValuesFlag,
+				QPS:          *extraMetadataReconcileQPSFlag,
+			})
+			go reconciler.Run(ctx)
+		}
 
 		initialBackoffDuration := time.Duration(*errorBackoffInitialDurationMs) * time.Millisecond
 		maxBackoffDuration := time.Duration(*errorBackoffMaxDurationMs) * time.Millisecond
+		if err := common.ValidateFsckPolicy(*defaultFsckPolicyFlag); err != nil {
+			klog.Fatalf("Bad default fsck policy: %v", err.Error())
+		}
+
 		// TODO(2042): Move more of the constructor args into this struct
+		accessPolicy, err := newVolumeAccessPolicy()
+		if err != nil {
+			klog.Fatalf("Bad volume access policy: %v", err.Error())
+		}
 		args := &driver.GCEControllerServerArgs{
-			EnableDiskTopology: *diskTopology,
+			EnableDiskTopology:       *diskTopology,
+			VolumeLockWait:           volumeLockWait,
+			AccessPolicy:             accessPolicy,
+			NodeIDIncludesInstanceID: *nodeIDIncludesInstanceIDFlag,
+		}
+		if *volumeLocksLeaseNamespaceFlag != "" {
+			volumeLocks, err := newLeaseVolumeLocks(*volumeLocksLeaseNamespaceFlag, *volumeLocksLeaseDurationFlag)
+			if err != nil {
+				klog.Fatalf("Failed to set up the volume locks: %v", err.Error())
+			}
+			args.VolumeLocks = volumeLocks
+		}
+		if *enableMultitenancyFlag {
+			kubeClient, err := kubernetes.NewForConfig(tenancy.GetKubeConfig())
+			if err != nil {
+				klog.Fatalf("Failed to set up the tenant resolver: %v", err.Error())
+			}
+			args.TenantResolver = tenancy.NewNamespaceResolver(kubeClient.CoreV1().Namespaces())
 		}
 
 		controllerServer = driver.NewControllerServer(gceDriver, cloudProvider, initialBackoffDuration, maxBackoffDuration, fallbackRequisiteZones, *enableStoragePoolsFlag, *enableDataCacheFlag, multiZoneVolumeHandleConfig, listVolumesConfig, provisionableDisksConfig, *enableHdHAFlag, args)
@@ -275,6 +417,18 @@ func handle() {
 			klog.Fatalf("Failed to get node info from API server: %v", err.Error())
 		}
 
+		var diskTuningProfiles driver.DiskTuningProfiles
+		if *diskTuningProfilesFileFlag != "" {
+			data, err := os.ReadFile(*diskTuningProfilesFileFlag)
+			if err != nil {
+				klog.Fatalf("Failed to read disk tuning profiles: %v", err.Error())
+			}
+			diskTuningProfiles, err = driver.ParseDiskTuningProfiles(data)
+			if err != nil {
+				klog.Fatalf("Bad disk tuning profiles: %v", err.Error())
+			}
+		}
+
 		// TODO(2042): Move more of the constructor args into this struct
 		nsArgs := &driver.NodeServerArgs{
 			EnableDeviceInUseCheck:   *enableDeviceInUseCheck,
@@ -283,12 +437,43 @@ func handle() {
 			DataCacheEnabledNodePool: isDataCacheEnabledNodePool,
 			SysfsPath:                "/sys",
 			MetricsManager:           metricsManager,
+			VolumeLockWait:           volumeLockWait,
+			NodeIDIncludesInstanceID: *nodeIDIncludesInstanceIDFlag,
+			DefaultFsckPolicy:        *defaultFsckPolicyFlag,
+			DiskTuningProfiles:       diskTuningProfiles,
+			EnableFstrimScheduler:    *enableFstrimSchedulerFlag,
+			FstrimMaxConcurrent:      *fstrimMaxConcurrentFlag,
+			KubeletRootDir:           *kubeletRootDirFlag,
+			StaleMountDryRun:         *staleMountDryRunFlag,
 		}
 		nodeServer = driver.NewNodeServer(gceDriver, mounter, deviceUtils, meta, statter, nsArgs)
 
+		if *enableFstrimSchedulerFlag {
+			go nodeServer.RunFstrimScheduler(ctx)
+		}
+
+		if *enableStaleMountReconcilerFlag {
+			go nodeServer.RunStaleMountReconciler(ctx, *staleMountReconcileIntervalFlag)
+		}
+
+		if *debugHttpEndpointFlag != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/device-holders", nodeServer.DeviceHoldersHandler())
+			if *enableFstrimSchedulerFlag {
+				mux.Handle("/fstrim", nodeServer.FstrimHandler())
+			}
+			go func() {
+				klog.Infof("Debug server listening at %q", *debugHttpEndpointFlag)
+				if err := http.ListenAndServe(*debugHttpEndpointFlag, mux); err != nil {
+					klog.Fatalf("Failed to start debug server at specified address (%q): %v", *debugHttpEndpointFlag, err.Error())
+				}
+			}()
+		}
+
 		if *maxConcurrentFormatAndMount > 0 {
 			nodeServer = nodeServer.WithSerializedFormatAndMount(*formatAndMountTimeout, *maxConcurrentFormatAndMount)
 		}
+		nodeServer = nodeServer.WithMaxConcurrentFormat(*concurrentFormatTimeout, *maxConcurrentFormat)
 		if *enableDataCacheFlag {
 			if nodeName == nil || *nodeName == "" {
 				klog.Errorf("Data Cache enabled, but --node-name not passed")
@@ -326,6 +511,25 @@ func notEmpty(v string) bool {
 	return v != ""
 }
 
+// newVolumeAccessPolicy returns the volume access policy of the flags, or nil
+// if they are not set.
+func newVolumeAccessPolicy() (*driver.VolumeAccessPolicy, error) {
+	requiredDiskLabels, err := common.ConvertLabelsStringToMap(*requiredDiskLabelsFlag)
+	if err != nil {
+		return nil, fmt.Errorf("bad required disk labels: %w", err)
+	}
+	policy := &driver.VolumeAccessPolicy{
+		AllowedProjects:    parseCSVFlag(*allowedVolumeProjectsFlag),
+		AllowedZones:       parseCSVFlag(*allowedVolumeZonesFlag),
+		AllowedRegions:     parseCSVFlag(*allowedVolumeRegionsFlag),
+		RequiredDiskLabels: requiredDiskLabels,
+	}
+	if len(policy.AllowedProjects) == 0 && len(policy.AllowedZones) == 0 && len(policy.AllowedRegions) == 0 && len(policy.RequiredDiskLabels) == 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
 func parseCSVFlag(list string) []string {
 	return slices.Filter(nil, strings.Split(list, ","), notEmpty)
 }
@@ -451,3 +655,30 @@ func setupDataCache(ctx context.Context, nodeName string, nodeId string) error {
 	klog.V(4).Infof("LSSD caching is setup for the Data Cache enabled node %s", nodeName)
 	return nil
 }
+
+// newConfigMapOperationJournal returns the operation journal stored in the
+// ConfigMaps of namespace.
+func newConfigMapOperationJournal(namespace string) (gce.OperationJournal, error) {
+	kubeClient, err := kubernetes.NewForConfig(tenancy.GetKubeConfig())
+	if err != nil {
+		return nil, err
+	}
+	return gce.NewConfigMapOperationJournal(kubeClient.CoreV1().ConfigMaps(namespace)), nil
+}
+
+// newLeaseVolumeLocks returns volume locks stored as Leases in namespace,
+// identifying the replica by its host name.
+func newLeaseVolumeLocks(namespace string, leaseDuration time.Duration) (*common.LeaseVolumeLocks, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(tenancy.GetKubeConfig())
+	if err != nil {
+		return nil, err
+	}
+	// A restarted replica must not resume the Leases of its previous run.
+	identity := hostname + "_" + uuid.NewString()
+	klog.V(2).Infof("Using Lease based volume locks in namespace %s as %s", namespace, identity)
+	return common.NewLeaseVolumeLocks(kubeClient.CoordinationV1().Leases(namespace), identity, leaseDuration), nil
+}
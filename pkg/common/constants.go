@@ -51,6 +51,12 @@ const (
 
 	// Keys in the publish context
 	ContexLocalSsdCacheSize = "local-ssd-cache-size"
+	// Type, provisioned IOPS and provisioned throughput in MiB/s of the disk,
+	// used by the node to select its tuning profile. The provisioned values
+	// are only set for disk types which support them.
+	ContextDiskType              = "disk-type"
+	ContextProvisionedIOPS       = "provisioned-iops"
+	ContextProvisionedThroughput = "provisioned-throughput"
 	// Node name for E2E tests
 	TestNode = "test-node-csi-e2e"
 
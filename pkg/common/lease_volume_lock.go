@@ -0,0 +1,312 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// volumeLockLeasePrefix prefixes the names of the volume lock Leases.
+	volumeLockLeasePrefix = "pd-csi-volume-lock-"
+	// volumeLockVolumeIDAnnotation records the volume ID of a volume lock
+	// Lease, whose name is a hash of it.
+	volumeLockVolumeIDAnnotation = "pd.csi.storage.gke.io/volume-id"
+	// leaseLockAPITimeout bounds the Lease API calls of acquiring, renewing
+	// and releasing a lock.
+	leaseLockAPITimeout = 5 * time.Second
+)
+
+// errVolumeLockHeld is returned when the Lease of a volume is held by another
+// replica.
+var errVolumeLockHeld = errors.New("volume lock is held by another replica")
+
+// LeaseClient is the subset of the Lease client of a namespace used by
+// LeaseVolumeLocks.
+type LeaseClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error)
+	Create(ctx context.Context, lease *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error)
+	Update(ctx context.Context, lease *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// LeaseVolumeLocks is a VolumeLocker shared by the replicas of the
+// controller. The lock of each volume is a coordination.k8s.io Lease, renewed
+// while held and deleted on release. The Lease of a replica that stopped
+// renewing it, e.g. because it crashed, is stolen once it expired. A Lease
+// that is taken over, or that could not be renewed before it expired, is
+// lost, which is signalled by Lost.
+type LeaseVolumeLocks struct {
+	client   LeaseClient
+	identity string
+	// leaseDuration is how long a Lease stays held without being renewed.
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	now           func() time.Time
+
+	mux sync.Mutex
+	// held are the locks held by this replica. A nil entry is a lock being
+	// acquired.
+	held map[string]*heldLease
+}
+
+// heldLease is a Lease held by this replica.
+type heldLease struct {
+	// stop stops the renewal of the Lease, done is closed once it stopped.
+	stop func()
+	done chan struct{}
+	// lost is closed if the Lease was lost while held.
+	lost chan struct{}
+}
+
+var _ LosableVolumeLocker = &LeaseVolumeLocks{}
+
+// NewLeaseVolumeLocks returns volume locks stored as Leases by client,
+// identifying this replica as identity. Leases are renewed every third of
+// leaseDuration.
+func NewLeaseVolumeLocks(client LeaseClient, identity string, leaseDuration time.Duration) *LeaseVolumeLocks {
+	return &LeaseVolumeLocks{
+		client:        client,
+		identity:      identity,
+		leaseDuration: leaseDuration,
+		renewInterval: leaseDuration / 3,
+		now:           time.Now,
+		held:          map[string]*heldLease{},
+	}
+}
+
+// volumeLockLeaseName returns the name of the Lease of a volume. Volume IDs
+// are not valid object names, so the name is a hash of the volume ID.
+func volumeLockLeaseName(volumeID string) string {
+	sum := sha256.Sum256([]byte(volumeID))
+	return volumeLockLeasePrefix + hex.EncodeToString(sum[:16])
+}
+
+// TryAcquire tries to acquire the Lease of volumeID and returns true if
+// successful. It returns false if the Lease is held by another replica, or
+// cannot be acquired.
+func (vl *LeaseVolumeLocks) TryAcquire(volumeID string) bool {
+	vl.mux.Lock()
+	if _, ok := vl.held[volumeID]; ok {
+		vl.mux.Unlock()
+		return false
+	}
+	vl.held[volumeID] = nil
+	vl.mux.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaseLockAPITimeout)
+	defer cancel()
+	lease, err := vl.acquire(ctx, volumeID)
+
+	vl.mux.Lock()
+	defer vl.mux.Unlock()
+	if err != nil {
+		delete(vl.held, volumeID)
+		if errors.Is(err, errVolumeLockHeld) {
+			klog.V(4).Infof("Volume lock of %s is held by another replica", volumeID)
+		} else {
+			klog.Warningf("Failed to acquire the volume lock of %s: %v", volumeID, err)
+		}
+		return false
+	}
+	renewCtx, stop := context.WithCancel(context.Background())
+	h := &heldLease{stop: stop, done: make(chan struct{}), lost: make(chan struct{})}
+	vl.held[volumeID] = h
+	go vl.renew(renewCtx, h, lease)
+	return true
+}
+
+// acquire creates the Lease of volumeID, or takes it over if it expired.
+func (vl *LeaseVolumeLocks) acquire(ctx context.Context, volumeID string) (*coordinationv1.Lease, error) {
+	name := volumeLockLeaseName(volumeID)
+	now := metav1.NewMicroTime(vl.now())
+	leaseDurationSeconds := int32(vl.leaseDuration.Seconds())
+	lease, err := vl.client.Create(ctx, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{volumeLockVolumeIDAnnotation: volumeID},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &vl.identity,
+			LeaseDurationSeconds: &leaseDurationSeconds,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}, metav1.CreateOptions{})
+	if err == nil || !apierrors.IsAlreadyExists(err) {
+		return lease, err
+	}
+
+	lease, err = vl.client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !vl.expired(lease) {
+		return nil, errVolumeLockHeld
+	}
+	holder := leaseHolder(lease)
+	transitions := int32(1)
+	if lease.Spec.LeaseTransitions != nil {
+		transitions += *lease.Spec.LeaseTransitions
+	}
+	lease.Spec.HolderIdentity = &vl.identity
+	lease.Spec.LeaseDurationSeconds = &leaseDurationSeconds
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseTransitions = &transitions
+	// The resource version of the Lease makes the update fail if another
+	// replica took the Lease over first.
+	lease, err = vl.client.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return nil, errVolumeLockHeld
+	}
+	if err != nil {
+		return nil, err
+	}
+	klog.Warningf("Took over the expired volume lock of %s from %q", volumeID, holder)
+	return lease, nil
+}
+
+// expired returns true if the holder of lease stopped renewing it.
+func (vl *LeaseVolumeLocks) expired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return vl.now().After(expiry)
+}
+
+// leaseHolder returns the holder identity of lease, or "".
+func leaseHolder(lease *coordinationv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+// renew renews lease until ctx is done, or until the Lease is lost.
+func (vl *LeaseVolumeLocks) renew(ctx context.Context, h *heldLease, lease *coordinationv1.Lease) {
+	defer close(h.done)
+	ticker := time.NewTicker(vl.renewInterval)
+	defer ticker.Stop()
+	renewTime := lease.Spec.RenewTime.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		apiCtx, cancel := context.WithTimeout(ctx, leaseLockAPITimeout)
+		renewed := lease.DeepCopy()
+		now := metav1.NewMicroTime(vl.now())
+		renewed.Spec.RenewTime = &now
+		updated, err := vl.client.Update(apiCtx, renewed, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			current, getErr := vl.client.Get(apiCtx, lease.Name, metav1.GetOptions{})
+			switch {
+			case getErr != nil:
+				err = getErr
+			case leaseHolder(current) != vl.identity:
+				cancel()
+				klog.Errorf("Lost the volume lock Lease %s to %q", lease.Name, leaseHolder(current))
+				close(h.lost)
+				return
+			default:
+				// Renew the current version of the Lease at the next tick.
+				// It was not renewed, so renewTime is left alone.
+				lease = current
+			}
+		}
+		cancel()
+		if err != nil {
+			// Another replica may take the Lease over once it expired, so it
+			// is lost if it cannot be renewed before then.
+			if !vl.now().Add(vl.renewInterval).Before(renewTime.Add(vl.leaseDuration)) {
+				klog.Errorf("Lost the volume lock Lease %s, which could not be renewed before it expires: %v", lease.Name, err)
+				close(h.lost)
+				return
+			}
+			klog.Warningf("Failed to renew the volume lock Lease %s: %v", lease.Name, err)
+			continue
+		}
+		lease = updated
+		renewTime = now.Time
+	}
+}
+
+// Lost returns a channel closed if the Lease of volumeID, held by this
+// replica, is lost. It returns nil if the Lease is not held.
+func (vl *LeaseVolumeLocks) Lost(volumeID string) <-chan struct{} {
+	vl.mux.Lock()
+	defer vl.mux.Unlock()
+	if h := vl.held[volumeID]; h != nil {
+		return h.lost
+	}
+	return nil
+}
+
+// Release releases the Lease of volumeID, deleting it.
+func (vl *LeaseVolumeLocks) Release(volumeID string) {
+	vl.mux.Lock()
+	h := vl.held[volumeID]
+	delete(vl.held, volumeID)
+	vl.mux.Unlock()
+	if h == nil {
+		return
+	}
+	h.stop()
+	<-h.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaseLockAPITimeout)
+	defer cancel()
+	if err := vl.release(ctx, volumeLockLeaseName(volumeID)); err != nil {
+		// The Lease expires and is taken over by the next replica locking
+		// the volume.
+		klog.Warningf("Failed to release the volume lock of %s: %v", volumeID, err)
+	}
+}
+
+func (vl *LeaseVolumeLocks) release(ctx context.Context, name string) error {
+	lease, err := vl.client.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if holder := leaseHolder(lease); holder != vl.identity {
+		return fmt.Errorf("Lease %s is held by %q", name, holder)
+	}
+	err = vl.client.Delete(ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var leasesResource = schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}
+
+// fakeLeaseClient stores Leases, enforcing resource versions like the API
+// server.
+type fakeLeaseClient struct {
+	mux     sync.Mutex
+	leases  map[string]*coordinationv1.Lease
+	version int
+	// updateErr, if set, is returned by Update.
+	updateErr error
+}
+
+func newFakeLeaseClient() *fakeLeaseClient {
+	return &fakeLeaseClient{leases: map[string]*coordinationv1.Lease{}}
+}
+
+func (c *fakeLeaseClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*coordinationv1.Lease, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	lease, ok := c.leases[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(leasesResource, name)
+	}
+	return lease.DeepCopy(), nil
+}
+
+func (c *fakeLeaseClient) Create(_ context.Context, lease *coordinationv1.Lease, _ metav1.CreateOptions) (*coordinationv1.Lease, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.leases[lease.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(leasesResource, lease.Name)
+	}
+	return c.storeLocked(lease), nil
+}
+
+func (c *fakeLeaseClient) Update(_ context.Context, lease *coordinationv1.Lease, _ metav1.UpdateOptions) (*coordinationv1.Lease, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.updateErr != nil {
+		return nil, c.updateErr
+	}
+	current, ok := c.leases[lease.Name]
+	if !ok {
+		return nil, apierrors.NewNotFound(leasesResource, lease.Name)
+	}
+	if current.ResourceVersion != lease.ResourceVersion {
+		return nil, apierrors.NewConflict(leasesResource, lease.Name, nil)
+	}
+	return c.storeLocked(lease), nil
+}
+
+func (c *fakeLeaseClient) Delete(_ context.Context, name string, opts metav1.DeleteOptions) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	current, ok := c.leases[name]
+	if !ok {
+		return apierrors.NewNotFound(leasesResource, name)
+	}
+	if opts.Preconditions != nil && opts.Preconditions.ResourceVersion != nil && *opts.Preconditions.ResourceVersion != current.ResourceVersion {
+		return apierrors.NewConflict(leasesResource, name, nil)
+	}
+	delete(c.leases, name)
+	return nil
+}
+
+func (c *fakeLeaseClient) storeLocked(lease *coordinationv1.Lease) *coordinationv1.Lease {
+	c.version++
+	stored := lease.DeepCopy()
+	stored.ResourceVersion = strconv.Itoa(c.version)
+	c.leases[lease.Name] = stored
+	return stored.DeepCopy()
+}
+
+func (c *fakeLeaseClient) holder(volumeID string) string {
+	lease, err := c.Get(context.Background(), volumeLockLeaseName(volumeID), metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	return leaseHolder(lease)
+}
+
+func TestLeaseVolumeLocks(t *testing.T) {
+	client := newFakeLeaseClient()
+	a := NewLeaseVolumeLocks(client, "a", time.Minute)
+	b := NewLeaseVolumeLocks(client, "b", time.Minute)
+	volumeID := "projects/p/zones/z/disks/d"
+
+	if !a.TryAcquire(volumeID) {
+		t.Fatalf("a.TryAcquire() failed on a free volume")
+	}
+	if a.TryAcquire(volumeID) {
+		t.Errorf("a.TryAcquire() succeeded on a volume a holds")
+	}
+	if b.TryAcquire(volumeID) {
+		t.Errorf("b.TryAcquire() succeeded on a volume a holds")
+	}
+	if !b.TryAcquire("projects/p/zones/z/disks/other") {
+		t.Errorf("b.TryAcquire() failed on another volume")
+	}
+	if holder := client.holder(volumeID); holder != "a" {
+		t.Errorf("Lease holder = %q, want a", holder)
+	}
+
+	a.Release(volumeID)
+	if _, ok := client.leases[volumeLockLeaseName(volumeID)]; ok {
+		t.Errorf("Release() left the Lease")
+	}
+	if !b.TryAcquire(volumeID) {
+		t.Errorf("b.TryAcquire() failed on a released volume")
+	}
+	b.Release(volumeID)
+}
+
+func TestLeaseVolumeLocksStealExpiredLease(t *testing.T) {
+	client := newFakeLeaseClient()
+	a := NewLeaseVolumeLocks(client, "a", time.Minute)
+	b := NewLeaseVolumeLocks(client, "b", time.Minute)
+	volumeID := "projects/p/zones/z/disks/d"
+
+	if !a.TryAcquire(volumeID) {
+		t.Fatalf("a.TryAcquire() failed on a free volume")
+	}
+	// a stops renewing the Lease, e.g. because it crashed.
+	a.mux.Lock()
+	a.held[volumeID].stop()
+	<-a.held[volumeID].done
+	a.mux.Unlock()
+
+	b.now = func() time.Time { return time.Now().Add(30 * time.Second) }
+	if b.TryAcquire(volumeID) {
+		t.Fatalf("b.TryAcquire() succeeded before the Lease expired")
+	}
+	b.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if !b.TryAcquire(volumeID) {
+		t.Fatalf("b.TryAcquire() failed on an expired Lease")
+	}
+	lease, _ := client.Get(context.Background(), volumeLockLeaseName(volumeID), metav1.GetOptions{})
+	if holder := leaseHolder(lease); holder != "b" {
+		t.Errorf("Lease holder = %q, want b", holder)
+	}
+	if lease.Spec.LeaseTransitions == nil || *lease.Spec.LeaseTransitions != 1 {
+		t.Errorf("Lease transitions = %v, want 1", lease.Spec.LeaseTransitions)
+	}
+
+	// a releasing its stolen lock leaves the Lease of b.
+	a.Release(volumeID)
+	if holder := client.holder(volumeID); holder != "b" {
+		t.Errorf("Lease holder after a released = %q, want b", holder)
+	}
+	b.Release(volumeID)
+}
+
+func TestLeaseVolumeLocksRenew(t *testing.T) {
+	client := newFakeLeaseClient()
+	a := NewLeaseVolumeLocks(client, "a", time.Minute)
+	a.renewInterval = time.Millisecond
+	start := time.Now()
+	var elapsed atomic.Int64
+	a.now = func() time.Time { return start.Add(time.Duration(elapsed.Load())) }
+	volumeID := "projects/p/zones/z/disks/d"
+
+	if !a.TryAcquire(volumeID) {
+		t.Fatalf("a.TryAcquire() failed on a free volume")
+	}
+	elapsed.Store(int64(time.Hour))
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		lease, err := client.Get(context.Background(), volumeLockLeaseName(volumeID), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get the Lease: %v", err)
+		}
+		if lease.Spec.RenewTime.Time.Equal(start.Add(time.Hour)) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Lease was not renewed, renew time is %v", lease.Spec.RenewTime)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	a.Release(volumeID)
+}
+
+func TestLeaseVolumeLocksLost(t *testing.T) {
+	volumeID := "projects/p/zones/z/disks/d"
+	testCases := []struct {
+		name string
+		// lose makes a lose the Lease of volumeID, held by a.
+		lose func(client *fakeLeaseClient, elapsed *atomic.Int64)
+	}{
+		{
+			name: "Lease taken over by another replica",
+			lose: func(client *fakeLeaseClient, _ *atomic.Int64) {
+				lease, _ := client.Get(context.Background(), volumeLockLeaseName(volumeID), metav1.GetOptions{})
+				holder := "b"
+				lease.Spec.HolderIdentity = &holder
+				if _, err := client.Update(context.Background(), lease, metav1.UpdateOptions{}); err != nil {
+					t.Fatalf("Failed to take the Lease over: %v", err)
+				}
+			},
+		},
+		{
+			name: "Lease not renewed before it expired",
+			lose: func(client *fakeLeaseClient, elapsed *atomic.Int64) {
+				client.mux.Lock()
+				client.updateErr = errors.New("unavailable")
+				client.mux.Unlock()
+				elapsed.Store(int64(time.Minute))
+			},
+		},
+		{
+			name: "Lease renewals conflicting until it expired",
+			lose: func(client *fakeLeaseClient, elapsed *atomic.Int64) {
+				client.mux.Lock()
+				client.updateErr = apierrors.NewConflict(leasesResource, volumeLockLeaseName(volumeID), nil)
+				client.mux.Unlock()
+				elapsed.Store(int64(time.Minute))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeLeaseClient()
+			a := NewLeaseVolumeLocks(client, "a", time.Minute)
+			a.renewInterval = time.Millisecond
+			start := time.Now()
+			var elapsed atomic.Int64
+			a.now = func() time.Time { return start.Add(time.Duration(elapsed.Load())) }
+			locks := NewQueuedVolumeLocks(a, VolumeLockWaitConfig{})
+
+			if !locks.Acquire(context.Background(), volumeID, "test") {
+				t.Fatalf("Acquire() failed on a free volume")
+			}
+			ctx, cancel := locks.WithLock(context.Background(), volumeID)
+			defer cancel()
+			if ctx.Err() != nil {
+				t.Fatalf("WithLock() returned a done context: %v", ctx.Err())
+			}
+
+			tc.lose(client, &elapsed)
+			select {
+			case <-ctx.Done():
+			case <-time.After(10 * time.Second):
+				t.Fatalf("Operation was not cancelled after its lock was lost")
+			}
+			if cause := context.Cause(ctx); !errors.Is(cause, errVolumeLockLost) {
+				t.Errorf("Operation cancelled with %v, want %v", cause, errVolumeLockLost)
+			}
+			locks.Release(volumeID)
+		})
+	}
+}
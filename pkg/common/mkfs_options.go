@@ -0,0 +1,245 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Parameters for StorageClass with the options passed to mkfs when the
+	// volume is formatted with the filesystem type of the suffix.
+	ParameterKeyMkfsOptionsExt4  = "mkfs-options-ext4"
+	ParameterKeyMkfsOptionsXfs   = "mkfs-options-xfs"
+	ParameterKeyMkfsOptionsBtrfs = "mkfs-options-btrfs"
+
+	mkfsOptionsKeyPrefix = "mkfs-options-"
+)
+
+// valueValidator validates the value of a flag or suboption. It is called
+// with "" if the suboption has no value.
+type valueValidator func(value string) error
+
+// mkfsAllowedFlags are the mkfs flags that may be set, by filesystem type. A
+// nil validator means that the flag takes no value. Flags forcing the format,
+// selecting the device, or set by mount-utils (ext4 -F and -m, xfs -f) are
+// deliberately not allowed.
+var mkfsAllowedFlags = map[string]map[string]valueValidator{
+	"ext4": {
+		"-b": oneOf("1024", "2048", "4096"),
+		"-i": uintValue,
+		"-I": oneOf("128", "256", "512", "1024"),
+		"-N": uintValue,
+		"-T": oneOf("default", "small", "big", "huge", "largefile", "largefile4", "news"),
+		"-E": subOptions(map[string]valueValidator{
+			"lazy_itable_init":   optional(oneOf("0", "1")),
+			"lazy_journal_init":  optional(oneOf("0", "1")),
+			"stride":             uintValue,
+			"stripe_width":       uintValue,
+			"discard":            noValue,
+			"nodiscard":          noValue,
+			"packed_meta_blocks": optional(oneOf("0", "1")),
+			"num_backup_sb":      oneOf("0", "1", "2"),
+		}),
+		"-O": featureList("64bit", "bigalloc", "dir_index", "dir_nlink", "extent", "extra_isize", "filetype",
+			"flex_bg", "has_journal", "huge_file", "inline_data", "large_dir", "large_file", "metadata_csum",
+			"metadata_csum_seed", "orphan_file", "project", "quota", "resize_inode", "sparse_super", "uninit_bg"),
+		"-J": subOptions(map[string]valueValidator{
+			"size": uintValue,
+		}),
+		"-j": nil,
+	},
+	"xfs": {
+		"-b": subOptions(map[string]valueValidator{
+			"size": sizeValue,
+		}),
+		"-m": subOptions(map[string]valueValidator{
+			"crc":        oneOf("0", "1"),
+			"reflink":    oneOf("0", "1"),
+			"finobt":     oneOf("0", "1"),
+			"rmapbt":     oneOf("0", "1"),
+			"bigtime":    oneOf("0", "1"),
+			"inobtcount": oneOf("0", "1"),
+		}),
+		"-i": subOptions(map[string]valueValidator{
+			"size":   sizeValue,
+			"maxpct": uintValue,
+			"sparse": oneOf("0", "1"),
+			"align":  oneOf("0", "1"),
+		}),
+		"-d": subOptions(map[string]valueValidator{
+			"agcount": uintValue,
+			"su":      sizeValue,
+			"sw":      uintValue,
+			"sunit":   uintValue,
+			"swidth":  uintValue,
+		}),
+		"-n": subOptions(map[string]valueValidator{
+			"size":  sizeValue,
+			"ftype": oneOf("0", "1"),
+		}),
+		"-l": subOptions(map[string]valueValidator{
+			"size":       sizeValue,
+			"lazy-count": oneOf("0", "1"),
+			"su":         sizeValue,
+			"version":    oneOf("1", "2"),
+		}),
+		"-K": nil,
+	},
+	"btrfs": {
+		"-m":           oneOf("single", "dup"),
+		"--metadata":   oneOf("single", "dup"),
+		"-d":           oneOf("single", "dup"),
+		"--data":       oneOf("single", "dup"),
+		"-n":           sizeValue,
+		"--nodesize":   sizeValue,
+		"-s":           sizeValue,
+		"--sectorsize": sizeValue,
+		"-O": featureList("mixed-bg", "extref", "skinny-metadata", "no-holes", "free-space-tree",
+			"block-group-tree", "quota", "squota"),
+		"--features": featureList("mixed-bg", "extref", "skinny-metadata", "no-holes", "free-space-tree",
+			"block-group-tree", "quota", "squota"),
+		"--csum":      oneOf("crc32c", "xxhash", "sha256", "blake2"),
+		"--checksum":  oneOf("crc32c", "xxhash", "sha256", "blake2"),
+		"-K":          nil,
+		"--nodiscard": nil,
+		"-M":          nil,
+		"--mixed":     nil,
+	},
+}
+
+// MkfsOptionsKey returns the StorageClass parameter and volume context key of
+// the mkfs options of fsType.
+func MkfsOptionsKey(fsType string) string {
+	return mkfsOptionsKeyPrefix + fsType
+}
+
+// ParseMkfsOptions splits the mkfs options of fsType on whitespace and
+// validates them against the flags allowed for fsType. It returns the
+// arguments to pass to mkfs, or nil if options is empty.
+func ParseMkfsOptions(fsType, options string) ([]string, error) {
+	args := strings.Fields(options)
+	if len(args) == 0 {
+		return nil, nil
+	}
+	allowedFlags, ok := mkfsAllowedFlags[fsType]
+	if !ok {
+		return nil, fmt.Errorf("mkfs options are not supported for filesystem type %q", fsType)
+	}
+	for i := 0; i < len(args); i++ {
+		flag, value, hasValue := args[i], "", false
+		if strings.HasPrefix(flag, "--") {
+			flag, value, hasValue = strings.Cut(flag, "=")
+		}
+		validate, ok := allowedFlags[flag]
+		if !ok {
+			return nil, fmt.Errorf("mkfs option %q is not allowed for filesystem type %q", flag, fsType)
+		}
+		if validate == nil {
+			if hasValue {
+				return nil, fmt.Errorf("mkfs option %q does not take a value", flag)
+			}
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("mkfs option %q requires a value", flag)
+			}
+			i++
+			value = args[i]
+		}
+		if err := validate(value); err != nil {
+			return nil, fmt.Errorf("invalid value %q of mkfs option %q: %w", value, flag, err)
+		}
+	}
+	return args, nil
+}
+
+var sizeValueRegexp = regexp.MustCompile(`^[0-9]+[kmgtKMGT]?$`)
+
+func oneOf(values ...string) valueValidator {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("must be one of %v", values)
+		}
+		return nil
+	}
+}
+
+func optional(validate valueValidator) valueValidator {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		return validate(value)
+	}
+}
+
+func noValue(value string) error {
+	if value != "" {
+		return fmt.Errorf("does not take a value")
+	}
+	return nil
+}
+
+func uintValue(value string) error {
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return fmt.Errorf("must be an unsigned integer")
+	}
+	return nil
+}
+
+func sizeValue(value string) error {
+	if !sizeValueRegexp.MatchString(value) {
+		return fmt.Errorf("must be a size, optionally suffixed with k, m, g or t")
+	}
+	return nil
+}
+
+// subOptions validates a comma separated list of name[=value] suboptions.
+func subOptions(allowed map[string]valueValidator) valueValidator {
+	return func(value string) error {
+		for _, option := range strings.Split(value, ",") {
+			name, optionValue, _ := strings.Cut(option, "=")
+			validate, ok := allowed[name]
+			if !ok {
+				return fmt.Errorf("suboption %q is not allowed", name)
+			}
+			if err := validate(optionValue); err != nil {
+				return fmt.Errorf("suboption %q: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// featureList validates a comma separated list of features, each optionally
+// prefixed with ^ to disable it.
+func featureList(features ...string) valueValidator {
+	return func(value string) error {
+		for _, feature := range strings.Split(value, ",") {
+			if !slices.Contains(features, strings.TrimPrefix(feature, "^")) {
+				return fmt.Errorf("feature %q is not allowed", feature)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMkfsOptions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		fsType    string
+		options   string
+		expArgs   []string
+		expectErr bool
+	}{
+		{
+			name:    "empty",
+			fsType:  "ext4",
+			options: "  ",
+		},
+		{
+			name:    "ext4 inode ratio and features",
+			fsType:  "ext4",
+			options: "-i 4096  -I 512 -O ^has_journal,metadata_csum -E lazy_itable_init=0,discard",
+			expArgs: []string{"-i", "4096", "-I", "512", "-O", "^has_journal,metadata_csum", "-E", "lazy_itable_init=0,discard"},
+		},
+		{
+			name:    "xfs reflink and crc",
+			fsType:  "xfs",
+			options: "-m crc=1,reflink=1 -i size=512 -d su=64k,sw=4 -K",
+			expArgs: []string{"-m", "crc=1,reflink=1", "-i", "size=512", "-d", "su=64k,sw=4", "-K"},
+		},
+		{
+			name:    "btrfs profiles",
+			fsType:  "btrfs",
+			options: "-m dup --data=single --csum xxhash -O no-holes,^quota",
+			expArgs: []string{"-m", "dup", "--data=single", "--csum", "xxhash", "-O", "no-holes,^quota"},
+		},
+		{
+			name:      "unsupported filesystem",
+			fsType:    "ext3",
+			options:   "-i 4096",
+			expectErr: true,
+		},
+		{
+			name:      "force flag",
+			fsType:    "xfs",
+			options:   "-f",
+			expectErr: true,
+		},
+		{
+			name:      "reserved blocks set by mount-utils",
+			fsType:    "ext4",
+			options:   "-m 5",
+			expectErr: true,
+		},
+		{
+			name:      "positional argument",
+			fsType:    "ext4",
+			options:   "/dev/sdc",
+			expectErr: true,
+		},
+		{
+			name:      "missing value",
+			fsType:    "ext4",
+			options:   "-i",
+			expectErr: true,
+		},
+		{
+			name:      "invalid value",
+			fsType:    "ext4",
+			options:   "-i -F",
+			expectErr: true,
+		},
+		{
+			name:      "unknown suboption",
+			fsType:    "xfs",
+			options:   "-d file=1,name=/tmp/img",
+			expectErr: true,
+		},
+		{
+			name:      "unknown feature",
+			fsType:    "btrfs",
+			options:   "--features=raid56",
+			expectErr: true,
+		},
+		{
+			name:      "value of a flag without one",
+			fsType:    "btrfs",
+			options:   "--mixed=1",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := ParseMkfsOptions(tc.fsType, tc.options)
+			if gotErr := err != nil; gotErr != tc.expectErr {
+				t.Fatalf("ParseMkfsOptions(%q, %q) = %v; expectedErr: %v", tc.fsType, tc.options, err, tc.expectErr)
+			}
+			if diff := cmp.Diff(tc.expArgs, args); diff != "" {
+				t.Errorf("ParseMkfsOptions(%q, %q): -want, +got \n%s", tc.fsType, tc.options, diff)
+			}
+		})
+	}
+}
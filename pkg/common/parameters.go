@@ -17,9 +17,11 @@ limitations under the License.
 package common
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/klog/v2"
 )
@@ -40,6 +42,13 @@ const (
 	ParameterKeyStoragePools                  = "storage-pools"
 	ParameterKeyUseAllowedDiskTopology        = "use-allowed-disk-topology"
 
+	// Parameters for the filesystem check run when the volume is staged
+	ParameterKeyFsckPolicy = "fsck-policy"
+
+	// Parameter for the periodic trim of the filesystem of the volume by the
+	// node, as a duration like 24h
+	ParameterKeyFstrimInterval = "fstrim-interval"
+
 	// Parameters for Data Cache
 	ParameterKeyDataCacheSize               = "data-cache-size"
 	ParameterKeyDataCacheMode               = "data-cache-mode"
@@ -54,6 +63,16 @@ const (
 	DiskImageType                = "images"
 	replicationTypeNone          = "none"
 
+	// Values of the fsck policy
+	FsckPolicyNever               = "never"
+	FsckPolicyOnDirty             = "on-dirty"
+	FsckPolicyAlwaysReadOnlyCheck = "always-readonly-check"
+	FsckPolicyRepair              = "repair"
+
+	// MinFstrimInterval is the shortest interval between two periodic trims
+	// of the filesystem of a volume.
+	MinFstrimInterval = time.Hour
+
 	// Parameters for AvailabilityClass
 	ParameterNoAvailabilityClass       = "none"
 	ParameterRegionalHardFailoverClass = "regional-hard-failover"
@@ -138,6 +157,40 @@ type DiskParameters struct {
 	// Values {}
 	// Default: false
 	UseAllowedDiskTopology bool
+	// Values: {map[string]string} of filesystem type to mkfs options
+	// Default: ""
+	MkfsOptions map[string]string
+	// Values: never, on-dirty, always-readonly-check, repair
+	// Default: "", the policy of the node
+	FsckPolicy string
+	// Values: {string} comma separated list of queue/<name>=<value>
+	// Default: ""
+	QueueSettings string
+	// Values: {duration} of at least 1h
+	// Default: "", the filesystem is not trimmed periodically
+	FstrimInterval string
+}
+
+// ValidateFsckPolicy returns an error if policy is not a valid fsck policy.
+func ValidateFsckPolicy(policy string) error {
+	switch policy {
+	case FsckPolicyNever, FsckPolicyOnDirty, FsckPolicyAlwaysReadOnlyCheck, FsckPolicyRepair:
+		return nil
+	}
+	return fmt.Errorf("invalid fsck policy %q, must be one of %s, %s, %s or %s", policy, FsckPolicyNever, FsckPolicyOnDirty, FsckPolicyAlwaysReadOnlyCheck, FsckPolicyRepair)
+}
+
+// ParseFstrimInterval parses the interval between two periodic trims of the
+// filesystem of a volume.
+func ParseFstrimInterval(interval string) (time.Duration, error) {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fstrim interval %q: %w", interval, err)
+	}
+	if d < MinFstrimInterval {
+		return 0, fmt.Errorf("invalid fstrim interval %q, must be at least %v", interval, MinFstrimInterval)
+	}
+	return d, nil
 }
 
 func (dp *DiskParameters) IsRegional() bool {
@@ -339,6 +392,30 @@ func (pp *ParameterProcessor) ExtractAndDefaultParameters(parameters map[string]
 			}
 
 			p.UseAllowedDiskTopology = paramUseAllowedDiskTopology
+		case ParameterKeyMkfsOptionsExt4, ParameterKeyMkfsOptionsXfs, ParameterKeyMkfsOptionsBtrfs:
+			fsType := strings.TrimPrefix(strings.ToLower(k), mkfsOptionsKeyPrefix)
+			if _, err := ParseMkfsOptions(fsType, v); err != nil {
+				return p, d, fmt.Errorf("parameters contain invalid %s parameter: %w", k, err)
+			}
+			if p.MkfsOptions == nil {
+				p.MkfsOptions = make(map[string]string)
+			}
+			p.MkfsOptions[fsType] = v
+		case ParameterKeyFsckPolicy:
+			if err := ValidateFsckPolicy(v); err != nil {
+				return p, d, fmt.Errorf("parameters contain invalid %s parameter: %w", ParameterKeyFsckPolicy, err)
+			}
+			p.FsckPolicy = v
+		case ParameterKeyQueueSettings:
+			if _, err := ParseQueueSettingsParameter(v); err != nil {
+				return p, d, fmt.Errorf("parameters contain invalid %s parameter: %w", ParameterKeyQueueSettings, err)
+			}
+			p.QueueSettings = v
+		case ParameterKeyFstrimInterval:
+			if _, err := ParseFstrimInterval(v); err != nil {
+				return p, d, fmt.Errorf("parameters contain invalid %s parameter: %w", ParameterKeyFstrimInterval, err)
+			}
+			p.FstrimInterval = v
 		default:
 			return p, d, fmt.Errorf("parameters contains invalid option %q", k)
 		}
@@ -419,6 +496,20 @@ func extractResourceTagsParameter(tagsString string, resourceTags map[string]str
 	return nil
 }
 
+// IsCreatedByDriver returns true if description holds the JSON encoded tags
+// the driver puts in the description of the disks, snapshots and images it
+// creates, and the tags name driverName as the creator.
+func IsCreatedByDriver(description, driverName string) bool {
+	if description == "" {
+		return false
+	}
+	tags := map[string]string{}
+	if err := json.Unmarshal([]byte(description), &tags); err != nil {
+		return false
+	}
+	return tags[tagKeyCreatedBy] == driverName
+}
+
 func ExtractModifyVolumeParameters(parameters map[string]string) (ModifyVolumeParameters, error) {
 
 	modifyVolumeParams := ModifyVolumeParameters{}
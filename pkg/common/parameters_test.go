@@ -513,6 +513,69 @@ func TestExtractAndDefaultParameters(t *testing.T) {
 				UseAllowedDiskTopology: true,
 			},
 		},
+		{
+			name:       "mkfs options",
+			parameters: map[string]string{ParameterKeyMkfsOptionsExt4: "-i 8192 -O ^metadata_csum", ParameterKeyMkfsOptionsXfs: "-m reflink=1,crc=1"},
+			labels:     map[string]string{},
+			expectParams: DiskParameters{
+				DiskType:             "pd-standard",
+				ReplicationType:      "none",
+				DiskEncryptionKMSKey: "",
+				Tags:                 map[string]string{},
+				Labels:               map[string]string{},
+				ResourceTags:         map[string]string{},
+				MkfsOptions: map[string]string{
+					"ext4": "-i 8192 -O ^metadata_csum",
+					"xfs":  "-m reflink=1,crc=1",
+				},
+			},
+		},
+		{
+			name:       "fsck policy",
+			parameters: map[string]string{ParameterKeyFsckPolicy: FsckPolicyRepair},
+			labels:     map[string]string{},
+			expectParams: DiskParameters{
+				DiskType:             "pd-standard",
+				ReplicationType:      "none",
+				DiskEncryptionKMSKey: "",
+				Tags:                 map[string]string{},
+				Labels:               map[string]string{},
+				ResourceTags:         map[string]string{},
+				FsckPolicy:           FsckPolicyRepair,
+			},
+		},
+		{
+			name:       "invalid fsck policy",
+			parameters: map[string]string{ParameterKeyFsckPolicy: "sometimes"},
+			labels:     map[string]string{},
+			expectErr:  true,
+		},
+		{
+			name:       "fstrim interval",
+			parameters: map[string]string{ParameterKeyFstrimInterval: "168h"},
+			labels:     map[string]string{},
+			expectParams: DiskParameters{
+				DiskType:             "pd-standard",
+				ReplicationType:      "none",
+				DiskEncryptionKMSKey: "",
+				Tags:                 map[string]string{},
+				Labels:               map[string]string{},
+				ResourceTags:         map[string]string{},
+				FstrimInterval:       "168h",
+			},
+		},
+		{
+			name:       "fstrim interval too short",
+			parameters: map[string]string{ParameterKeyFstrimInterval: "30m"},
+			labels:     map[string]string{},
+			expectErr:  true,
+		},
+		{
+			name:       "mkfs options with a disallowed flag",
+			parameters: map[string]string{ParameterKeyMkfsOptionsExt4: "-i 8192 -F"},
+			labels:     map[string]string{},
+			expectErr:  true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -635,3 +698,39 @@ func TestExtractModifyVolumeParameters(t *testing.T) {
 		t.Errorf("Got ExtractModifyVolumeParameters(%+v) = %+v; want: %v", parameters, result, expected)
 	}
 }
+
+func TestIsCreatedByDriver(t *testing.T) {
+	tests := []struct {
+		desc        string
+		description string
+		want        bool
+	}{
+		{
+			desc:        "created by the driver",
+			description: `{"kubernetes.io/created-for/pv/name":"pv","storage.gke.io/created-by":"test-driver"}`,
+			want:        true,
+		},
+		{
+			desc:        "created by another driver",
+			description: `{"storage.gke.io/created-by":"other-driver"}`,
+		},
+		{
+			desc:        "no creator tag",
+			description: `{"kubernetes.io/created-for/pv/name":"pv"}`,
+		},
+		{
+			desc:        "free form description",
+			description: "Disk created by hand",
+		},
+		{
+			desc: "empty description",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := IsCreatedByDriver(tc.description, "test-driver"); got != tc.want {
+				t.Errorf("IsCreatedByDriver(%q) = %v; want %v", tc.description, got, tc.want)
+			}
+		})
+	}
+}
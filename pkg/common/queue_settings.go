@@ -0,0 +1,98 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Parameter for StorageClass with the block queue settings of the
+	// device of the volume, as a comma separated list of
+	// queue/<name>=<value>. Unlike mount flags, it also applies to block
+	// volumes.
+	ParameterKeyQueueSettings = "queue-settings"
+
+	// QueueSettingPrefix is the prefix of the mount flags and of the entries
+	// of the queue-settings parameter setting a block queue attribute, as in
+	// the path of the attribute under /sys/block/<device>.
+	QueueSettingPrefix = "queue/"
+)
+
+// queueSettingValidators are the block queue attributes that may be set.
+var queueSettingValidators = map[string]valueValidator{
+	"nr_requests":    positiveUintValue,
+	"scheduler":      oneOf("none", "mq-deadline", "kyber", "bfq"),
+	"max_sectors_kb": positiveUintValue,
+	"rq_affinity":    oneOf("0", "1", "2"),
+	"nomerges":       oneOf("0", "1", "2"),
+}
+
+// IsQueueSetting returns true if flag sets a block queue attribute.
+func IsQueueSetting(flag string) bool {
+	return strings.HasPrefix(flag, QueueSettingPrefix)
+}
+
+// ParseQueueSettingsParameter returns the block queue attributes set by the
+// value of the queue-settings parameter, by name.
+func ParseQueueSettingsParameter(parameter string) (map[string]string, error) {
+	flags := strings.FieldsFunc(parameter, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	for _, flag := range flags {
+		if !IsQueueSetting(flag) {
+			return nil, fmt.Errorf("block queue setting %q must be of the form %s<name>=<value>", flag, QueueSettingPrefix)
+		}
+	}
+	return ParseQueueSettings(flags)
+}
+
+// ParseQueueSettings returns the block queue attributes set by the
+// queue/<name>=<value> entries of flags, by name. Other flags are ignored,
+// and the last entry of an attribute takes precedence.
+func ParseQueueSettings(flags []string) (map[string]string, error) {
+	settings := map[string]string{}
+	for _, flag := range flags {
+		if !IsQueueSetting(flag) {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(flag, QueueSettingPrefix), "=")
+		if !ok {
+			return nil, fmt.Errorf("block queue setting %q must be of the form %s<name>=<value>", flag, QueueSettingPrefix)
+		}
+		validate, ok := queueSettingValidators[name]
+		if !ok {
+			return nil, fmt.Errorf("block queue attribute %q is not allowed", name)
+		}
+		if err := validate(value); err != nil {
+			return nil, fmt.Errorf("invalid value %q of block queue attribute %q: %w", value, name, err)
+		}
+		settings[name] = value
+	}
+	return settings, nil
+}
+
+func positiveUintValue(value string) error {
+	if err := uintValue(value); err != nil {
+		return err
+	}
+	if strings.TrimLeft(value, "0") == "" {
+		return fmt.Errorf("must be positive")
+	}
+	return nil
+}
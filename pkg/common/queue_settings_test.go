@@ -0,0 +1,87 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseQueueSettings(t *testing.T) {
+	testCases := []struct {
+		name        string
+		flags       []string
+		expSettings map[string]string
+		expectErr   bool
+	}{
+		{
+			name:        "no settings",
+			flags:       []string{"noatime", "read_ahead_kb=4096"},
+			expSettings: map[string]string{},
+		},
+		{
+			name:        "settings",
+			flags:       []string{"noatime", "queue/scheduler=mq-deadline", "queue/nr_requests=64", "queue/rq_affinity=2", "queue/nr_requests=128"},
+			expSettings: map[string]string{"scheduler": "mq-deadline", "nr_requests": "128", "rq_affinity": "2"},
+		},
+		{
+			name:      "attribute not allowed",
+			flags:     []string{"queue/write_cache=write through"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid value",
+			flags:     []string{"queue/max_sectors_kb=0"},
+			expectErr: true,
+		},
+		{
+			name:      "missing value",
+			flags:     []string{"queue/nomerges"},
+			expectErr: true,
+		},
+		{
+			name:      "path traversal",
+			flags:     []string{"queue/../../power/state=mem"},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, err := ParseQueueSettings(tc.flags)
+			if gotErr := err != nil; gotErr != tc.expectErr {
+				t.Fatalf("ParseQueueSettings(%v) = %v; expectedErr: %v", tc.flags, err, tc.expectErr)
+			}
+			if diff := cmp.Diff(tc.expSettings, settings); diff != "" {
+				t.Errorf("ParseQueueSettings(%v): -want, +got \n%s", tc.flags, diff)
+			}
+		})
+	}
+}
+
+func TestParseQueueSettingsParameter(t *testing.T) {
+	settings, err := ParseQueueSettingsParameter("queue/scheduler=none, queue/nomerges=1")
+	if err != nil {
+		t.Fatalf("ParseQueueSettingsParameter() failed: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"scheduler": "none", "nomerges": "1"}, settings); diff != "" {
+		t.Errorf("ParseQueueSettingsParameter(): -want, +got \n%s", diff)
+	}
+	if _, err := ParseQueueSettingsParameter("queue/scheduler=none,noatime"); err == nil {
+		t.Errorf("ParseQueueSettingsParameter() with a mount option got no error")
+	}
+}
@@ -57,12 +57,18 @@ const (
 	snapshotProjectKey    = 1
 
 	// Node ID Expected Format
-	// "projects/{projectName}/zones/{zoneName}/disks/{diskName}"
-	nodeIDFmt           = "projects/%s/zones/%s/instances/%s"
-	nodeIDProjectValue  = 1
-	nodeIDZoneValue     = 3
-	nodeIDNameValue     = 5
-	nodeIDTotalElements = 6
+	// "projects/{projectName}/zones/{zoneName}/instances/{instanceName}"
+	// or, qualified with the numeric ID of the instance,
+	// "projects/{projectName}/zones/{zoneName}/instances/{instanceName}/ids/{instanceID}"
+	nodeIDFmt                         = "projects/%s/zones/%s/instances/%s"
+	nodeIDWithInstanceIDFmt           = nodeIDFmt + "/ids/%s"
+	nodeIDProjectValue                = 1
+	nodeIDZoneValue                   = 3
+	nodeIDNameValue                   = 5
+	nodeIDInstanceIDKey               = 6
+	nodeIDInstanceIDValue             = 7
+	nodeIDTotalElements               = 6
+	nodeIDWithInstanceIDTotalElements = 8
 
 	regionalDeviceNameSuffix = "_regional"
 
@@ -182,14 +188,49 @@ func SnapshotIDToProjectKey(id string) (string, string, string, error) {
 	}
 }
 
-func NodeIDToZoneAndName(id string) (string, string, error) {
+// splitNodeID splits a node ID, with or without an instance ID, into its
+// components.
+func splitNodeID(id string) ([]string, error) {
 	splitId := strings.Split(id, "/")
-	if len(splitId) != nodeIDTotalElements {
-		return "", "", fmt.Errorf("failed to get id components. expected projects/{project}/zones/{zone}/instances/{name}. Got: %s", id)
+	switch {
+	case len(splitId) == nodeIDTotalElements:
+		return splitId, nil
+	case len(splitId) == nodeIDWithInstanceIDTotalElements && splitId[nodeIDInstanceIDKey] == "ids":
+		return splitId, nil
+	}
+	return nil, fmt.Errorf("failed to get id components. expected projects/{project}/zones/{zone}/instances/{name}[/ids/{id}]. Got: %s", id)
+}
+
+func NodeIDToZoneAndName(id string) (string, string, error) {
+	splitId, err := splitNodeID(id)
+	if err != nil {
+		return "", "", err
 	}
 	return splitId[nodeIDZoneValue], splitId[nodeIDNameValue], nil
 }
 
+// NodeIDToProject returns the project of the instance of a node ID.
+func NodeIDToProject(id string) (string, error) {
+	splitId, err := splitNodeID(id)
+	if err != nil {
+		return "", err
+	}
+	return splitId[nodeIDProjectValue], nil
+}
+
+// NodeIDToInstanceID returns the numeric ID of the instance of a node ID, or
+// "" if the node ID does not include it.
+func NodeIDToInstanceID(id string) (string, error) {
+	splitId, err := splitNodeID(id)
+	if err != nil {
+		return "", err
+	}
+	if len(splitId) != nodeIDWithInstanceIDTotalElements {
+		return "", nil
+	}
+	return splitId[nodeIDInstanceIDValue], nil
+}
+
 func GetRegionFromZones(zones []string) (string, error) {
 	const tpcPrefix = "u"
 	regions := sets.String{}
@@ -229,6 +270,13 @@ func CreateNodeID(project, zone, name string) string {
 	return fmt.Sprintf(nodeIDFmt, project, zone, name)
 }
 
+// CreateNodeIDWithInstanceID returns a node ID qualified with the numeric ID
+// of the instance, which changes when an instance is recreated with the same
+// name.
+func CreateNodeIDWithInstanceID(project, zone, name, instanceID string) string {
+	return fmt.Sprintf(nodeIDWithInstanceIDFmt, project, zone, name, instanceID)
+}
+
 func CreateZonalVolumeID(project, zone, name string) string {
 	return fmt.Sprintf(volIDZonalFmt, project, zone, name)
 }
@@ -238,11 +238,22 @@ func TestNodeIDToZoneAndName(t *testing.T) {
 			expZone: testZone,
 			expName: testName,
 		},
+		{
+			name:    "with instance ID",
+			nodeID:  CreateNodeIDWithInstanceID(testProject, testZone, testName, "1234"),
+			expZone: testZone,
+			expName: testName,
+		},
 		{
 			name:   "malformed",
 			nodeID: "wrong",
 			expErr: true,
 		},
+		{
+			name:   "malformed instance ID",
+			nodeID: CreateNodeID(testProject, testZone, testName) + "/uids/1234",
+			expErr: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Logf("test case: %s", tc.name)
@@ -264,6 +275,22 @@ func TestNodeIDToZoneAndName(t *testing.T) {
 	}
 }
 
+func TestNodeIDToInstanceID(t *testing.T) {
+	testCases := map[string]string{
+		CreateNodeID("test-project", "test-zone", "test-name"):                       "",
+		CreateNodeIDWithInstanceID("test-project", "test-zone", "test-name", "1234"): "1234",
+	}
+	for nodeID, want := range testCases {
+		got, err := NodeIDToInstanceID(nodeID)
+		if err != nil || got != want {
+			t.Errorf("NodeIDToInstanceID(%q) = %q, %v, want %q", nodeID, got, err, want)
+		}
+	}
+	if _, err := NodeIDToInstanceID("wrong"); err == nil {
+		t.Errorf("NodeIDToInstanceID() of a malformed node ID did not fail")
+	}
+}
+
 func TestGetRegionFromZones(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -17,6 +17,7 @@ limitations under the License.
 package common
 
 import (
+	"errors"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -26,6 +27,28 @@ const (
 	VolumeOperationAlreadyExistsFmt = "An operation with the given Volume ID %s already exists"
 )
 
+// errVolumeLockLost is the cause of the cancellation of an operation whose
+// volume lock was lost.
+var errVolumeLockLost = errors.New("volume lock was lost")
+
+// VolumeLocker serializes the operations on each volume.
+type VolumeLocker interface {
+	// TryAcquire tries to acquire the lock for operating on volumeID and
+	// returns true if successful.
+	TryAcquire(volumeID string) bool
+	// Release releases the lock of volumeID.
+	Release(volumeID string)
+}
+
+// LosableVolumeLocker is a VolumeLocker whose locks may be lost while held,
+// e.g. locks stored outside of the process that could not be renewed.
+type LosableVolumeLocker interface {
+	VolumeLocker
+	// Lost returns a channel closed if the lock of volumeID, held by the
+	// caller, is lost. It returns nil if the lock is not held.
+	Lost(volumeID string) <-chan struct{}
+}
+
 // VolumeLocks implements a map with atomic operations. It stores a set of all volume IDs
 // with an ongoing operation.
 type VolumeLocks struct {
@@ -0,0 +1,305 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// volumeLockPollInterval is how often the first waiter for a volume
+	// retries a lock held outside of this process, e.g. by another replica.
+	volumeLockPollInterval = 500 * time.Millisecond
+
+	volumeLockAcquired  = "acquired"
+	volumeLockContended = "contended"
+	volumeLockTimeout   = "timeout"
+)
+
+var (
+	volumeLockWaitDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "csidriver",
+			Name:           "volume_lock_wait_duration_seconds",
+			Help:           "Time operations waited for the lock of their volume, by operation and result",
+			Buckets:        []float64{0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"operation", "result"})
+
+	volumeLockHoldDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "csidriver",
+			Name:           "volume_lock_hold_duration_seconds",
+			Help:           "Time operations held the lock of their volume",
+			Buckets:        []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"operation"})
+)
+
+// VolumeLockMetrics returns the metrics of the volume locks, to be
+// registered by the metrics manager.
+func VolumeLockMetrics() []metrics.Registerable {
+	return []metrics.Registerable{
+		volumeLockWaitDurationMetric,
+		volumeLockHoldDurationMetric,
+	}
+}
+
+// VolumeLockWaitConfig configures waiting for contended volume locks.
+type VolumeLockWaitConfig struct {
+	// MaxWait bounds how long an operation waits for the lock of its volume.
+	// Waiting is also bounded by half of the time left before the deadline of
+	// the operation, leaving it the other half to run. If 0, operations on a
+	// locked volume fail right away.
+	MaxWait time.Duration
+	// LongHeldThreshold is the hold time past which a lock is logged as held
+	// too long. If 0, long held locks are not logged.
+	LongHeldThreshold time.Duration
+}
+
+// VolumeLockHolder describes the holder of a volume lock.
+type VolumeLockHolder struct {
+	// Holder is the host name of the process holding the lock.
+	Holder string
+	// Operation is the operation holding the lock, e.g. NodeStageVolume.
+	Operation   string
+	AcquireTime time.Time
+}
+
+// volumeLockWaiter is an operation queued for the lock of a volume.
+type volumeLockWaiter struct {
+	// turn is closed when the waiter is first in the queue.
+	turn chan struct{}
+}
+
+// QueuedVolumeLocks queues the operations on a locked volume in FIFO order,
+// instead of failing them right away, so that an operation does not wait for
+// the backoff of the sidecars or of the kubelet once the lock is released.
+type QueuedVolumeLocks struct {
+	locks  VolumeLocker
+	holder string
+	config VolumeLockWaitConfig
+	now    func() time.Time
+
+	mux sync.Mutex
+	// queues are the waiters for each volume. The first waiter holds the
+	// lock, or is the next to acquire it.
+	queues  map[string][]*volumeLockWaiter
+	holders map[string]VolumeLockHolder
+}
+
+// NewQueuedVolumeLocks returns queued volume locks acquiring locks. The
+// locks are recorded as held by the host name of the process.
+func NewQueuedVolumeLocks(locks VolumeLocker, config VolumeLockWaitConfig) *QueuedVolumeLocks {
+	holder, err := os.Hostname()
+	if err != nil {
+		holder = "unknown"
+	}
+	return &QueuedVolumeLocks{
+		locks:   locks,
+		holder:  holder,
+		config:  config,
+		now:     time.Now,
+		queues:  map[string][]*volumeLockWaiter{},
+		holders: map[string]VolumeLockHolder{},
+	}
+}
+
+// waitDeadline returns until when an operation started at start waits for a
+// lock.
+func (q *QueuedVolumeLocks) waitDeadline(ctx context.Context, start time.Time) time.Time {
+	deadline := start.Add(q.config.MaxWait)
+	if ctxDeadline, ok := ctx.Deadline(); ok {
+		if half := start.Add(ctxDeadline.Sub(start) / 2); half.Before(deadline) {
+			deadline = half
+		}
+	}
+	return deadline
+}
+
+// Acquire acquires the lock for operation on volumeID and returns true if
+// successful. If the lock is held, it waits for it in FIFO order, up to the
+// configured maximum wait.
+func (q *QueuedVolumeLocks) Acquire(ctx context.Context, volumeID, operation string) bool {
+	start := q.now()
+	deadline := q.waitDeadline(ctx, start)
+	waiting := deadline.After(start)
+
+	w := &volumeLockWaiter{turn: make(chan struct{})}
+	q.mux.Lock()
+	queue := q.queues[volumeID]
+	contended := len(queue) > 0
+	if len(queue) > 0 && !waiting {
+		q.logContendedLocked(volumeID, operation)
+		q.mux.Unlock()
+		volumeLockWaitDurationMetric.WithLabelValues(operation, volumeLockContended).Observe(0)
+		return false
+	}
+	if len(queue) == 0 {
+		close(w.turn)
+	} else {
+		q.logContendedLocked(volumeID, operation)
+	}
+	q.queues[volumeID] = append(queue, w)
+	q.mux.Unlock()
+
+	// Without waiting, the turn of the waiter is already closed.
+	var timeout <-chan time.Time
+	var done <-chan struct{}
+	if waiting {
+		timer := time.NewTimer(deadline.Sub(start))
+		defer timer.Stop()
+		timeout = timer.C
+		done = ctx.Done()
+	}
+	turn := (<-chan struct{})(w.turn)
+	var poll <-chan time.Time
+	for {
+		select {
+		case <-turn:
+			if q.locks.TryAcquire(volumeID) {
+				q.mux.Lock()
+				q.holders[volumeID] = VolumeLockHolder{Holder: q.holder, Operation: operation, AcquireTime: q.now()}
+				q.mux.Unlock()
+				if contended {
+					klog.V(4).Infof("%s acquired the lock of volume %s after %v", operation, volumeID, q.now().Sub(start))
+				}
+				volumeLockWaitDurationMetric.WithLabelValues(operation, volumeLockAcquired).Observe(q.now().Sub(start).Seconds())
+				return true
+			}
+			if !waiting {
+				q.dequeue(volumeID, w)
+				volumeLockWaitDurationMetric.WithLabelValues(operation, volumeLockContended).Observe(0)
+				return false
+			}
+			// The lock is held outside of this process, retry it later.
+			contended = true
+			turn = nil
+			poll = time.After(volumeLockPollInterval)
+			continue
+		case <-poll:
+			// The turn of the waiter is closed, retry right away.
+			poll = nil
+			turn = w.turn
+			continue
+		case <-timeout:
+		case <-done:
+		}
+		q.dequeue(volumeID, w)
+		klog.V(4).Infof("%s timed out after %v waiting for the lock of volume %s", operation, q.now().Sub(start), volumeID)
+		volumeLockWaitDurationMetric.WithLabelValues(operation, volumeLockTimeout).Observe(q.now().Sub(start).Seconds())
+		return false
+	}
+}
+
+// Release releases the lock of volumeID and hands it over to the next waiter.
+func (q *QueuedVolumeLocks) Release(volumeID string) {
+	q.locks.Release(volumeID)
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if h, ok := q.holders[volumeID]; ok {
+		held := q.now().Sub(h.AcquireTime)
+		volumeLockHoldDurationMetric.WithLabelValues(h.Operation).Observe(held.Seconds())
+		if q.config.LongHeldThreshold > 0 && held > q.config.LongHeldThreshold {
+			klog.Warningf("%s held the lock of volume %s for %v", h.Operation, volumeID, held)
+		}
+		delete(q.holders, volumeID)
+	}
+	if queue := q.queues[volumeID]; len(queue) > 0 {
+		q.removeLocked(volumeID, queue[0])
+	}
+}
+
+// WithLock returns a copy of ctx that is cancelled if the lock of volumeID,
+// held by the caller, is lost, so that the operation holding it stops before
+// another replica starts operating on the volume. The returned cancel
+// function must be called once the operation is done.
+func (q *QueuedVolumeLocks) WithLock(ctx context.Context, volumeID string) (context.Context, context.CancelFunc) {
+	locks, ok := q.locks.(LosableVolumeLocker)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	lost := locks.Lost(volumeID)
+	if lost == nil {
+		return context.WithCancel(ctx)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-lost:
+			klog.Errorf("Cancelling the operation on volume %s, whose lock was lost", volumeID)
+			cancel(errVolumeLockLost)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// Holder returns the holder of the lock of volumeID in this process, if any.
+func (q *QueuedVolumeLocks) Holder(volumeID string) (VolumeLockHolder, bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	h, ok := q.holders[volumeID]
+	return h, ok
+}
+
+// dequeue removes w from the queue of volumeID.
+func (q *QueuedVolumeLocks) dequeue(volumeID string, w *volumeLockWaiter) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.removeLocked(volumeID, w)
+}
+
+// removeLocked removes w from the queue of volumeID, giving the turn to the
+// next waiter if w was first.
+func (q *QueuedVolumeLocks) removeLocked(volumeID string, w *volumeLockWaiter) {
+	queue := q.queues[volumeID]
+	i := slices.Index(queue, w)
+	if i < 0 {
+		return
+	}
+	queue = slices.Delete(queue, i, i+1)
+	if len(queue) == 0 {
+		delete(q.queues, volumeID)
+		return
+	}
+	q.queues[volumeID] = queue
+	if i == 0 {
+		close(queue[0].turn)
+	}
+}
+
+// logContendedLocked logs the holder of a contended lock if it held it for
+// too long.
+func (q *QueuedVolumeLocks) logContendedLocked(volumeID, operation string) {
+	h, ok := q.holders[volumeID]
+	if !ok || q.config.LongHeldThreshold <= 0 {
+		return
+	}
+	if held := q.now().Sub(h.AcquireTime); held > q.config.LongHeldThreshold {
+		klog.Warningf("%s is blocked by %s of %s, holding the lock of volume %s for %v since %v", operation, h.Operation, h.Holder, volumeID, held, h.AcquireTime)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const testVolumeID = "projects/p/zones/z/disks/d"
+
+// waitForQueue waits until n operations are queued for the volume.
+func waitForQueue(t *testing.T, q *QueuedVolumeLocks, n int) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		q.mux.Lock()
+		queued := len(q.queues[testVolumeID])
+		q.mux.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d operations queued, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueuedVolumeLocksWithoutWait(t *testing.T) {
+	q := NewQueuedVolumeLocks(NewVolumeLocks(), VolumeLockWaitConfig{})
+	ctx := context.Background()
+	if !q.Acquire(ctx, testVolumeID, "NodeStageVolume") {
+		t.Fatalf("Acquire() failed on a free volume")
+	}
+	if h, ok := q.Holder(testVolumeID); !ok || h.Operation != "NodeStageVolume" || h.AcquireTime.IsZero() || h.Holder == "" {
+		t.Errorf("Holder() = %+v, %v, want NodeStageVolume", h, ok)
+	}
+	if q.Acquire(ctx, testVolumeID, "NodePublishVolume") {
+		t.Errorf("Acquire() succeeded on a locked volume")
+	}
+	q.Release(testVolumeID)
+	if _, ok := q.Holder(testVolumeID); ok {
+		t.Errorf("Holder() found a holder after Release()")
+	}
+	if !q.Acquire(ctx, testVolumeID, "NodePublishVolume") {
+		t.Errorf("Acquire() failed on a released volume")
+	}
+}
+
+func TestQueuedVolumeLocksFIFO(t *testing.T) {
+	q := NewQueuedVolumeLocks(NewVolumeLocks(), VolumeLockWaitConfig{MaxWait: time.Minute})
+	ctx := context.Background()
+	if !q.Acquire(ctx, testVolumeID, "first") {
+		t.Fatalf("Acquire() failed on a free volume")
+	}
+
+	acquired := make(chan string, 2)
+	for i, operation := range []string{"second", "third"} {
+		go func() {
+			if q.Acquire(ctx, testVolumeID, operation) {
+				acquired <- operation
+			}
+		}()
+		waitForQueue(t, q, i+2)
+	}
+
+	for _, want := range []string{"second", "third"} {
+		select {
+		case got := <-acquired:
+			t.Fatalf("%s acquired the lock before it was released", got)
+		case <-time.After(10 * time.Millisecond):
+		}
+		q.Release(testVolumeID)
+		if got := <-acquired; got != want {
+			t.Fatalf("%s acquired the lock, want %s", got, want)
+		}
+		if h, _ := q.Holder(testVolumeID); h.Operation != want {
+			t.Errorf("Holder() = %+v, want %s", h, want)
+		}
+	}
+	q.Release(testVolumeID)
+	waitForQueue(t, q, 0)
+}
+
+func TestQueuedVolumeLocksWaitBounds(t *testing.T) {
+	testCases := []struct {
+		name    string
+		maxWait time.Duration
+		timeout time.Duration
+	}{
+		{
+			name:    "max wait",
+			maxWait: 20 * time.Millisecond,
+			timeout: time.Minute,
+		},
+		{
+			name:    "half of the request deadline",
+			maxWait: time.Minute,
+			timeout: 40 * time.Millisecond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewQueuedVolumeLocks(NewVolumeLocks(), VolumeLockWaitConfig{MaxWait: tc.maxWait})
+			if !q.Acquire(context.Background(), testVolumeID, "first") {
+				t.Fatalf("Acquire() failed on a free volume")
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			start := time.Now()
+			if q.Acquire(ctx, testVolumeID, "second") {
+				t.Fatalf("Acquire() succeeded on a locked volume")
+			}
+			if waited := time.Since(start); waited < 15*time.Millisecond || waited > 10*time.Second {
+				t.Errorf("Acquire() waited %v, want about 20ms", waited)
+			}
+			// The timed out operation left the queue.
+			waitForQueue(t, q, 1)
+		})
+	}
+}
+
+func TestQueuedVolumeLocksHeldElsewhere(t *testing.T) {
+	locks := NewVolumeLocks()
+	q := NewQueuedVolumeLocks(locks, VolumeLockWaitConfig{MaxWait: time.Minute})
+	// The lock is held outside of the queue, e.g. by another replica.
+	locks.TryAcquire(testVolumeID)
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- q.Acquire(context.Background(), testVolumeID, "NodeStageVolume")
+	}()
+	waitForQueue(t, q, 1)
+	locks.Release(testVolumeID)
+	select {
+	case ok := <-acquired:
+		if !ok {
+			t.Errorf("Acquire() failed after the lock was released")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Acquire() did not acquire the released lock")
+	}
+}
+
+func TestQueuedVolumeLocksWithLockInProcess(t *testing.T) {
+	locks := NewQueuedVolumeLocks(NewVolumeLocks(), VolumeLockWaitConfig{})
+	volumeID := "projects/p/zones/z/disks/d"
+	if !locks.Acquire(context.Background(), volumeID, "test") {
+		t.Fatalf("Acquire() failed on a free volume")
+	}
+	ctx, cancel := locks.WithLock(context.Background(), volumeID)
+	if ctx.Err() != nil {
+		t.Errorf("WithLock() returned a done context: %v", ctx.Err())
+	}
+	cancel()
+	if ctx.Err() == nil {
+		t.Errorf("cancel() did not cancel the context")
+	}
+	locks.Release(volumeID)
+}
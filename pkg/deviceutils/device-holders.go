@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceutils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSummaryHolders is the number of holders of each kind listed in the
+// summary of DeviceHolders.
+const maxSummaryHolders = 5
+
+// DeviceHolders describes what still holds a device, and its partitions.
+type DeviceHolders struct {
+	// FilesystemInUse is true if the kernel still has the filesystem of the
+	// device mounted.
+	FilesystemInUse bool `json:"filesystemInUse"`
+	// MountPoints are the mounts of the device remaining in the mount
+	// namespaces of the node.
+	MountPoints []DeviceMount `json:"mountPoints,omitempty"`
+	// Holders are the block devices built on top of the device, e.g.
+	// device-mapper or LVM devices, from /sys/block/<device>/holders.
+	Holders []string `json:"holders,omitempty"`
+	// Processes are the processes with an open handle on the device.
+	Processes []ProcessHolder `json:"processes,omitempty"`
+	// Truncated is true if the scan of the processes stopped before all of
+	// them were scanned, so that some mounts or processes may be missing.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DeviceMount is a mount of a device in a mount namespace.
+type DeviceMount struct {
+	// Namespace is the mount namespace, as in /proc/<pid>/ns/mnt.
+	Namespace string `json:"namespace"`
+	// Path is the mount point in the namespace.
+	Path string `json:"path"`
+}
+
+// ProcessHolder is a process with an open handle on a device.
+type ProcessHolder struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+}
+
+// HasHolders returns true if anything holds the device. Only a mounted
+// filesystem keeps a volume from being unstaged, the other holders are
+// reported for diagnostics.
+func (h *DeviceHolders) HasHolders() bool {
+	return h != nil && (h.FilesystemInUse || len(h.MountPoints) > 0 || len(h.Holders) > 0 || len(h.Processes) > 0)
+}
+
+// String returns a short summary of the holders of the device.
+func (h *DeviceHolders) String() string {
+	if !h.HasHolders() {
+		return "no holders"
+	}
+	var parts []string
+	if h.FilesystemInUse {
+		parts = append(parts, "filesystem mounted")
+	}
+	if len(h.MountPoints) > 0 {
+		mounts := make([]string, len(h.MountPoints))
+		for i, m := range h.MountPoints {
+			mounts[i] = fmt.Sprintf("%s in %s", m.Path, m.Namespace)
+		}
+		parts = append(parts, "mounts: "+summarize(mounts))
+	}
+	if len(h.Holders) > 0 {
+		parts = append(parts, "holders: "+summarize(h.Holders))
+	}
+	if len(h.Processes) > 0 {
+		processes := make([]string, len(h.Processes))
+		for i, p := range h.Processes {
+			processes[i] = fmt.Sprintf("%d (%s)", p.PID, p.Command)
+		}
+		parts = append(parts, "processes: "+summarize(processes))
+	}
+	if h.Truncated {
+		parts = append(parts, "process scan truncated")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// summarize joins the first maxSummaryHolders items, followed by the number of
+// items left out.
+func summarize(items []string) string {
+	if len(items) <= maxSummaryHolders {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxSummaryHolders], ", "), len(items)-maxSummaryHolders)
+}
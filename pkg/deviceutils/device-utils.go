@@ -15,6 +15,7 @@ limitations under the License.
 package deviceutils
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -89,12 +90,15 @@ type DeviceUtils interface {
 	// Resize returns whether or not a device needs resizing.
 	Resize(resizer resizefs.Resizefs, devicePath string, deviceMountPath string) (bool, error)
 
-	// IsDeviceFilesystemInUse returns if a device path with the specified fstype
+	// IsDeviceFilesystemInUse returns what still holds a device path: its
+	// mounted filesystem, its remaining mounts, the block devices built on top
+	// of it and the processes with an open handle on it. The scan of the
+	// processes is bounded, see DeviceHolders.Truncated.
 	// TODO: Mounter is passed in in order to call GetDiskFormat()
 	// This is currently only implemented in mounter_linux, not mounter_windows.
 	// Refactor this interface and function call up the stack to the caller once it is
 	// implemented in mounter_windows.
-	IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (bool, error)
+	IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (*DeviceHolders, error)
 }
 
 type deviceUtils struct {
@@ -209,6 +213,10 @@ func ensureUdevToolsExist() error {
 	return nil
 }
 
+// ErrDiskNotFound is returned, wrapped, by VerifyDevicePath if no device has
+// the serial number of the disk, e.g. because it is not attached.
+var ErrDiskNotFound = errors.New("no device has the serial number of the disk")
+
 // VerifyDevicePath returns the first devicePath that maps to a real disk in the
 // candidate devicePaths or an empty string if none is found.
 // If the device is not found, it will attempt to fix any issues
@@ -365,7 +373,7 @@ func udevadmTriggerForDiskIfExists(deviceName string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("udevadm --trigger requested to fix disk %s but no such disk was found in device path %v", deviceName, devFsPathToSerial)
+	return fmt.Errorf("udevadm --trigger requested to fix disk %s but no such disk was found in device path %v: %w", deviceName, devFsPathToSerial, ErrDiskNotFound)
 }
 
 // Calls "udevadm trigger --action=change" on the specified drive. drivePath
@@ -17,29 +17,160 @@ limitations under the License.
 package deviceutils
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"k8s.io/mount-utils"
 )
 
-func (_ *deviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (bool, error) {
+// holderScanLimits bound the cost of scanning /proc for the holders of a
+// device.
+type holderScanLimits struct {
+	// maxProcesses is the number of processes scanned.
+	maxProcesses int
+	// maxFDs is the number of open files scanned per process.
+	maxFDs int
+	// timeout is the time after which the scan stops.
+	timeout time.Duration
+}
+
+var defaultHolderScanLimits = holderScanLimits{
+	maxProcesses: 4096,
+	maxFDs:       1024,
+	timeout:      2 * time.Second,
+}
+
+func (_ *deviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (*DeviceHolders, error) {
 	fstype, err := mounter.GetDiskFormat(devicePath)
 	if err != nil {
-		return false, fmt.Errorf("failed to get disk format for %s (aka %s): %v", devicePath, devFsPath, err)
+		return nil, fmt.Errorf("failed to get disk format for %s (aka %s): %v", devicePath, devFsPath, err)
 	}
 
 	devFsName := filepath.Base(devFsPath)
 	sysFsTypePath := fmt.Sprintf("/sys/fs/%s/%s", fstype, devFsName)
 	stat, err := os.Stat(sysFsTypePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	// A path which doesn't exist indicates the filesystem is NOT in use.
+	filesystemInUse := err == nil && stat.IsDir()
+
+	holders := scanDeviceHolders("/proc", "/sys", devFsName, defaultHolderScanLimits)
+	holders.FilesystemInUse = filesystemInUse
+	return holders, nil
+}
+
+// scanDeviceHolders returns the holders of the block device devName and of its
+// partitions: the block devices in their holders directories under sysRoot,
+// and the mounts and open handles of the processes under procRoot. The mount
+// table of each mount namespace is read once. Processes which cannot be read,
+// e.g. because they exited, are skipped.
+func scanDeviceHolders(procRoot, sysRoot, devName string, limits holderScanLimits) *DeviceHolders {
+	holders := &DeviceHolders{}
+
+	// Block device directories by name, for the device and its partitions.
+	devDirs := map[string]string{devName: filepath.Join(sysRoot, "block", devName)}
+	if entries, err := os.ReadDir(devDirs[devName]); err == nil {
+		for _, entry := range entries {
+			dir := filepath.Join(devDirs[devName], entry.Name())
+			if _, err := os.Stat(filepath.Join(dir, "partition")); err == nil {
+				devDirs[entry.Name()] = dir
+			}
+		}
+	}
+	// Device numbers, as in /proc/<pid>/mountinfo, and device paths, as
+	// opened by processes.
+	devNumbers := map[string]bool{}
+	devPaths := map[string]bool{}
+	for name, dir := range devDirs {
+		if dev, err := os.ReadFile(filepath.Join(dir, "dev")); err == nil {
+			devNumbers[strings.TrimSpace(string(dev))] = true
+		}
+		devPaths["/dev/"+name] = true
+		if entries, err := os.ReadDir(filepath.Join(dir, "holders")); err == nil {
+			for _, entry := range entries {
+				holders.Holders = append(holders.Holders, entry.Name())
+			}
+		}
+	}
+	slices.Sort(holders.Holders)
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return holders
+	}
+	deadline := time.Now().Add(limits.timeout)
+	namespaces := map[string]bool{}
+	scanned := 0
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if scanned >= limits.maxProcesses || time.Now().After(deadline) {
+			holders.Truncated = true
+			break
+		}
+		scanned++
+		pidDir := filepath.Join(procRoot, entry.Name())
+
+		if ns, err := os.Readlink(filepath.Join(pidDir, "ns", "mnt")); err == nil && !namespaces[ns] {
+			namespaces[ns] = true
+			for _, path := range deviceMountPoints(filepath.Join(pidDir, "mountinfo"), devNumbers) {
+				holders.MountPoints = append(holders.MountPoints, DeviceMount{Namespace: ns, Path: path})
+			}
+		}
+
+		if holdsDevice(filepath.Join(pidDir, "fd"), devPaths, limits.maxFDs) {
+			comm, _ := os.ReadFile(filepath.Join(pidDir, "comm"))
+			holders.Processes = append(holders.Processes, ProcessHolder{PID: pid, Command: strings.TrimSpace(string(comm))})
+		}
+	}
+	return holders
+}
+
+// deviceMountPoints returns the mount points of the mountinfo file whose
+// device number is one of devNumbers.
+func deviceMountPoints(mountinfo string, devNumbers map[string]bool) []string {
+	f, err := os.Open(mountinfo)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Path doesn't exist, indicating the device is NOT in use
-			return false, nil
+		return nil
+	}
+	defer f.Close()
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 4 && devNumbers[fields[2]] {
+			paths = append(paths, fields[4])
 		}
-		return false, err
 	}
+	return paths
+}
 
-	return stat.IsDir(), nil
+// holdsDevice returns true if one of the first maxFDs open files of the fd
+// directory of a process is one of devPaths.
+func holdsDevice(fdDir string, devPaths map[string]bool, maxFDs int) bool {
+	d, err := os.Open(fdDir)
+	if err != nil {
+		return false
+	}
+	defer d.Close()
+	names, err := d.Readdirnames(maxFDs)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		if target, err := os.Readlink(filepath.Join(fdDir, name)); err == nil && devPaths[target] {
+			return true
+		}
+	}
+	return false
 }
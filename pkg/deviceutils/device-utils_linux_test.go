@@ -0,0 +1,156 @@
+//go:build linux
+
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceutils
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeProcess is a process of a fake /proc.
+type fakeProcess struct {
+	pid       string
+	comm      string
+	namespace string
+	mountinfo string
+	fds       []string
+}
+
+func writeFakeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func symlinkFakeFile(t *testing.T, target, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatalf("Failed to link %s to %s: %v", path, target, err)
+	}
+}
+
+func TestScanDeviceHolders(t *testing.T) {
+	sysRoot := t.TempDir()
+	writeFakeFile(t, filepath.Join(sysRoot, "block/sdb/dev"), "8:16\n")
+	writeFakeFile(t, filepath.Join(sysRoot, "block/sdb/sdb1/dev"), "8:17\n")
+	writeFakeFile(t, filepath.Join(sysRoot, "block/sdb/sdb1/partition"), "1\n")
+	writeFakeFile(t, filepath.Join(sysRoot, "block/sdb/sdb1/holders/dm-0"), "")
+	writeFakeFile(t, filepath.Join(sysRoot, "block/sdb/queue/read_ahead_kb"), "128\n")
+
+	hostMounts := "22 1 8:1 / / rw - ext4 /dev/sda1 rw\n" +
+		"35 22 8:16 / /var/lib/kubelet/plugins/kubernetes.io/csi/pd.csi.storage.gke.io/abc/globalmount rw - ext4 /dev/sdb rw\n"
+	processes := []fakeProcess{
+		{pid: "1", comm: "systemd", namespace: "mnt:[4026531841]", mountinfo: hostMounts, fds: []string{"/dev/null"}},
+		// Mount namespaces are read once.
+		{pid: "12", comm: "kubelet", namespace: "mnt:[4026531841]", mountinfo: hostMounts},
+		{pid: "130", comm: "app", namespace: "mnt:[4026532000]", mountinfo: "40 39 8:17 / /data rw - xfs /dev/sdb1 rw\n"},
+		{pid: "1400", comm: "dd", namespace: "mnt:[4026531841]", fds: []string{"/dev/null", "/dev/sdb"}},
+	}
+	procRoot := t.TempDir()
+	writeFakeFile(t, filepath.Join(procRoot, "meminfo"), "")
+	for _, p := range processes {
+		dir := filepath.Join(procRoot, p.pid)
+		writeFakeFile(t, filepath.Join(dir, "comm"), p.comm+"\n")
+		writeFakeFile(t, filepath.Join(dir, "mountinfo"), p.mountinfo)
+		symlinkFakeFile(t, p.namespace, filepath.Join(dir, "ns/mnt"))
+		for i, fd := range p.fds {
+			symlinkFakeFile(t, fd, filepath.Join(dir, "fd", strconv.Itoa(i)))
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		devName    string
+		limits     holderScanLimits
+		expHolders *DeviceHolders
+		expSummary string
+	}{
+		{
+			name:    "all holders",
+			devName: "sdb",
+			limits:  defaultHolderScanLimits,
+			expHolders: &DeviceHolders{
+				MountPoints: []DeviceMount{
+					{Namespace: "mnt:[4026531841]", Path: "/var/lib/kubelet/plugins/kubernetes.io/csi/pd.csi.storage.gke.io/abc/globalmount"},
+					{Namespace: "mnt:[4026532000]", Path: "/data"},
+				},
+				Holders:   []string{"dm-0"},
+				Processes: []ProcessHolder{{PID: 1400, Command: "dd"}},
+			},
+			expSummary: "mounts: /var/lib/kubelet/plugins/kubernetes.io/csi/pd.csi.storage.gke.io/abc/globalmount in mnt:[4026531841], /data in mnt:[4026532000]; holders: dm-0; processes: 1400 (dd)",
+		},
+		{
+			name:    "process limit",
+			devName: "sdb",
+			limits:  holderScanLimits{maxProcesses: 2, maxFDs: 16, timeout: time.Minute},
+			expHolders: &DeviceHolders{
+				MountPoints: []DeviceMount{
+					{Namespace: "mnt:[4026531841]", Path: "/var/lib/kubelet/plugins/kubernetes.io/csi/pd.csi.storage.gke.io/abc/globalmount"},
+				},
+				Holders:   []string{"dm-0"},
+				Truncated: true,
+			},
+			expSummary: "mounts: /var/lib/kubelet/plugins/kubernetes.io/csi/pd.csi.storage.gke.io/abc/globalmount in mnt:[4026531841]; holders: dm-0; process scan truncated",
+		},
+		{
+			name:       "unused device",
+			devName:    "sdc",
+			limits:     defaultHolderScanLimits,
+			expHolders: &DeviceHolders{},
+			expSummary: "no holders",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			holders := scanDeviceHolders(procRoot, sysRoot, tc.devName, tc.limits)
+			if diff := cmp.Diff(tc.expHolders, holders); diff != "" {
+				t.Errorf("scanDeviceHolders() returned unexpected holders (-want +got):\n%s", diff)
+			}
+			if got := holders.String(); got != tc.expSummary {
+				t.Errorf("scanDeviceHolders() returned holders %q, want %q", got, tc.expSummary)
+			}
+		})
+	}
+}
+
+func TestDeviceHoldersSummary(t *testing.T) {
+	holders := &DeviceHolders{
+		FilesystemInUse: true,
+		Holders:         []string{"dm-0", "dm-1", "dm-2", "dm-3", "dm-4", "dm-5", "dm-6"},
+	}
+	want := "filesystem mounted; holders: dm-0, dm-1, dm-2, dm-3, dm-4 and 2 more"
+	if got := holders.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !holders.HasHolders() {
+		t.Errorf("HasHolders() = false, want true")
+	}
+}
@@ -23,8 +23,8 @@ func (_ *deviceUtils) DisableDevice(devicePath string) error {
 	return nil
 }
 
-func (_ *deviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (bool, error) {
+func (_ *deviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (*DeviceHolders, error) {
 	// We don't support checking if a device filesystem is captured elsewhere by the system
-	// Return false, to skip this check. Assume the filesystem is not in use.
-	return false, nil
+	// Return no holders, to skip this check. Assume the filesystem is not in use.
+	return &DeviceHolders{}, nil
 }
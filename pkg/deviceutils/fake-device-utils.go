@@ -54,8 +54,8 @@ func (du *fakeDeviceUtils) Resize(resizer resizefs.Resizefs, devicePath string,
 	return resizer.Resize(devicePath, deviceMountPath)
 }
 
-func (_ *fakeDeviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (bool, error) {
+func (_ *fakeDeviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (*DeviceHolders, error) {
 	// We don't support checking if a device filesystem is captured elsewhere by the system
-	// Return false, to skip this check.
-	return false, nil
+	// Return no holders, to skip this check.
+	return &DeviceHolders{}, nil
 }
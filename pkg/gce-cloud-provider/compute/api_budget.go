@@ -0,0 +1,334 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Call types of compute API calls, used as the call_type metric label.
+	apiCallRead   = "read"
+	apiCallMutate = "mutate"
+)
+
+// APIPriority is the priority of the compute API calls made with a context.
+type APIPriority int
+
+const (
+	// APIPriorityDefault is the priority of calls serving a request that
+	// blocks workloads, e.g. attaching or detaching a disk.
+	APIPriorityDefault APIPriority = iota
+	// APIPriorityLow is the priority of calls that can be delayed, e.g.
+	// listing volumes. Low priority calls leave part of each budget to
+	// default priority calls.
+	APIPriorityLow
+)
+
+func (p APIPriority) String() string {
+	if p == APIPriorityLow {
+		return "low"
+	}
+	return "default"
+}
+
+type apiPriorityKey struct{}
+
+// WithAPIPriority returns a context whose compute API calls are made with the
+// given priority.
+func WithAPIPriority(ctx context.Context, priority APIPriority) context.Context {
+	return context.WithValue(ctx, apiPriorityKey{}, priority)
+}
+
+func apiPriorityFromContext(ctx context.Context) APIPriority {
+	if priority, ok := ctx.Value(apiPriorityKey{}).(APIPriority); ok {
+		return priority
+	}
+	return APIPriorityDefault
+}
+
+// APIBudget is a token bucket budget of compute API calls.
+type APIBudget struct {
+	// QPS is the rate the bucket is refilled at. A budget with a QPS of 0 is
+	// unlimited.
+	QPS float64
+	// Burst is the size of the bucket.
+	Burst int
+}
+
+// APIBudgetConfig configures the client-side budget of compute API calls.
+// Calls are classified by method class, the compute resource collection they
+// act on (e.g. "disks", "instances" or "operations"), and call type, read or
+// mutate. Each project, method class and call type has its own bucket.
+type APIBudgetConfig struct {
+	// Read is the budget of each method class for read calls.
+	Read APIBudget
+	// Mutate is the budget of each method class for mutating calls.
+	Mutate APIBudget
+	// ClassBudgets overrides the budget of a method class and call type,
+	// keyed by "<method class>.<read|mutate>".
+	ClassBudgets map[string]APIBudget
+	// LowPriorityReserve is the fraction of each bucket kept for default
+	// priority calls: low priority calls only take a token while the bucket
+	// holds more than that fraction of its burst.
+	LowPriorityReserve float64
+}
+
+func (c APIBudgetConfig) enabled() bool {
+	return c.Read.QPS > 0 || c.Mutate.QPS > 0 || len(c.ClassBudgets) > 0
+}
+
+func (c APIBudgetConfig) budgetFor(class, callType string) APIBudget {
+	if budget, ok := c.ClassBudgets[class+"."+callType]; ok {
+		return budget
+	}
+	if callType == apiCallRead {
+		return c.Read
+	}
+	return c.Mutate
+}
+
+// ParseAPIClassBudgets parses a comma separated list of method class budgets
+// like "<method class>.<read|mutate>=<qps>[:<burst>]". The burst defaults to
+// the QPS, rounded up.
+func ParseAPIClassBudgets(s string) (map[string]APIBudget, error) {
+	budgets := map[string]APIBudget{}
+	if s == "" {
+		return budgets, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("budget %q is not of the form <method class>.<read|mutate>=<qps>[:<burst>]", entry)
+		}
+		class, callType, ok := strings.Cut(key, ".")
+		if !ok || class == "" || (callType != apiCallRead && callType != apiCallMutate) {
+			return nil, fmt.Errorf("budget key %q is not of the form <method class>.<read|mutate>", key)
+		}
+		qpsStr, burstStr, hasBurst := strings.Cut(value, ":")
+		qps, err := strconv.ParseFloat(qpsStr, 64)
+		if err != nil || qps < 0 {
+			return nil, fmt.Errorf("invalid QPS %q for budget %q", qpsStr, key)
+		}
+		burst := int(math.Ceil(qps))
+		if hasBurst {
+			burst, err = strconv.Atoi(burstStr)
+			if err != nil || burst < 1 {
+				return nil, fmt.Errorf("invalid burst %q for budget %q", burstStr, key)
+			}
+		}
+		budgets[key] = APIBudget{QPS: qps, Burst: max(burst, 1)}
+	}
+	return budgets, nil
+}
+
+// apiBudget holds the token buckets of the compute API calls.
+type apiBudget struct {
+	config APIBudgetConfig
+
+	mux     sync.Mutex
+	buckets map[string]*rate.Limiter
+
+	now func() time.Time
+}
+
+// newAPIBudget returns the budget for config, or nil if config does not
+// limit any call.
+func newAPIBudget(config APIBudgetConfig) *apiBudget {
+	if !config.enabled() {
+		return nil
+	}
+	klog.Infof("Limiting compute API calls with budget %+v", config)
+	return &apiBudget{
+		config:  config,
+		buckets: map[string]*rate.Limiter{},
+		now:     time.Now,
+	}
+}
+
+// bucket returns the token bucket of a project, method class and call type,
+// or nil if those calls are not limited.
+func (b *apiBudget) bucket(project, class, callType string) *rate.Limiter {
+	budget := b.config.budgetFor(class, callType)
+	if budget.QPS <= 0 {
+		return nil
+	}
+	key := strings.Join([]string{project, class, callType}, "/")
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = rate.NewLimiter(rate.Limit(budget.QPS), max(budget.Burst, 1))
+		b.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// wait blocks until the call can be made within its budget. The call is
+// rejected with a 429 error, without waiting, if its budget cannot be met
+// before the ctx deadline.
+func (b *apiBudget) wait(ctx context.Context, project, class, callType string) error {
+	bucket := b.bucket(project, class, callType)
+	if bucket == nil {
+		return nil
+	}
+	priority := apiPriorityFromContext(ctx)
+	start := b.now()
+	var err error
+	if priority == APIPriorityLow {
+		err = b.waitLowPriority(ctx, bucket)
+	} else {
+		err = b.waitDefaultPriority(ctx, bucket)
+	}
+	if errors.Is(err, errAPIBudgetDeadline) {
+		apiBudgetRejectionsMetric.WithLabelValues(class, callType, priority.String()).Inc()
+		return &googleapi.Error{
+			Code:    http.StatusTooManyRequests,
+			Message: fmt.Sprintf("client-side %s budget of %s calls in project %s cannot be met before the deadline", callType, class, project),
+		}
+	}
+	if err != nil {
+		return err
+	}
+	apiBudgetWaitDurationMetric.WithLabelValues(class, callType, priority.String()).Observe(b.now().Sub(start).Seconds())
+	return nil
+}
+
+// errAPIBudgetDeadline is returned internally when a call budget cannot be
+// met before the ctx deadline.
+var errAPIBudgetDeadline = errors.New("API budget cannot be met before the deadline")
+
+// exceedsDeadline returns true if a call delayed by delay would miss the ctx
+// deadline.
+func (b *apiBudget) exceedsDeadline(ctx context.Context, now time.Time, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && now.Add(delay).After(deadline)
+}
+
+func (b *apiBudget) waitDefaultPriority(ctx context.Context, bucket *rate.Limiter) error {
+	now := b.now()
+	r := bucket.ReserveN(now, 1)
+	delay := r.DelayFrom(now)
+	if b.exceedsDeadline(ctx, now, delay) {
+		r.CancelAt(now)
+		return errAPIBudgetDeadline
+	}
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// waitLowPriority waits until the bucket holds more tokens than the low
+// priority reserve, then takes one. As default priority calls can take
+// tokens at any time, this gives way to them whenever the budget is tight.
+func (b *apiBudget) waitLowPriority(ctx context.Context, bucket *rate.Limiter) error {
+	reserve := math.Min(b.config.LowPriorityReserve*float64(bucket.Burst()), float64(bucket.Burst()-1))
+	for {
+		now := b.now()
+		tokens := bucket.TokensAt(now)
+		if tokens >= reserve+1 {
+			if bucket.AllowN(now, 1) {
+				return nil
+			}
+			continue
+		}
+		delay := time.Duration((reserve + 1 - tokens) / float64(bucket.Limit()) * float64(time.Second))
+		if b.exceedsDeadline(ctx, now, delay) {
+			return errAPIBudgetDeadline
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// budgetedTransport makes the compute API calls sent through it wait for
+// their budget.
+type budgetedTransport struct {
+	budget *apiBudget
+	base   http.RoundTripper
+}
+
+func (t *budgetedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	project, class, callType := classifyComputeRequest(req)
+	if err := t.budget.wait(req.Context(), project, class, callType); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// classifyComputeRequest returns the project, method class and call type of
+// a compute API request. The method class is the resource collection of the
+// request path, e.g. "disks" for
+// /compute/v1/projects/p/zones/z/disks/d/setLabels.
+func classifyComputeRequest(req *http.Request) (project, class, callType string) {
+	callType = apiCallMutate
+	if req.Method == http.MethodGet || strings.HasSuffix(req.URL.Path, "/wait") {
+		// Waiting for an operation does not change it.
+		callType = apiCallRead
+	}
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	i := 0
+	for i < len(segments) && segments[i] != "projects" {
+		i++
+	}
+	if i+1 >= len(segments) {
+		return "", "other", callType
+	}
+	project = segments[i+1]
+	rest := segments[i+2:]
+	switch {
+	case len(rest) == 0:
+		class = "projects"
+	case (rest[0] == "zones" || rest[0] == "regions") && len(rest) >= 3:
+		class = rest[2]
+	case (rest[0] == "global" || rest[0] == "aggregated") && len(rest) >= 2:
+		class = rest[1]
+	default:
+		class = rest[0]
+	}
+	return project, class, callType
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	computev1 "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+func TestClassifyComputeRequest(t *testing.T) {
+	testCases := []struct {
+		method       string
+		path         string
+		wantProject  string
+		wantClass    string
+		wantCallType string
+	}{
+		{
+			method:       http.MethodGet,
+			path:         "/compute/v1/projects/p/zones/z/disks/d",
+			wantProject:  "p",
+			wantClass:    "disks",
+			wantCallType: apiCallRead,
+		},
+		{
+			method:       http.MethodPost,
+			path:         "/compute/beta/projects/p/zones/z/instances/i/attachDisk",
+			wantProject:  "p",
+			wantClass:    "instances",
+			wantCallType: apiCallMutate,
+		},
+		{
+			method:       http.MethodPost,
+			path:         "/compute/staging_v1/projects/p/regions/r/operations/op/wait",
+			wantProject:  "p",
+			wantClass:    "operations",
+			wantCallType: apiCallRead,
+		},
+		{
+			method:       http.MethodDelete,
+			path:         "/compute/v1/projects/p/global/snapshots/s",
+			wantProject:  "p",
+			wantClass:    "snapshots",
+			wantCallType: apiCallMutate,
+		},
+		{
+			method:       http.MethodGet,
+			path:         "/compute/v1/projects/p/aggregated/disks",
+			wantProject:  "p",
+			wantClass:    "disks",
+			wantCallType: apiCallRead,
+		},
+		{
+			method:       http.MethodGet,
+			path:         "/compute/v1/projects/p/zones",
+			wantProject:  "p",
+			wantClass:    "zones",
+			wantCallType: apiCallRead,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "https://compute.googleapis.com"+tc.path, nil)
+			project, class, callType := classifyComputeRequest(req)
+			if project != tc.wantProject || class != tc.wantClass || callType != tc.wantCallType {
+				t.Errorf("classifyComputeRequest() = %q, %q, %q, want %q, %q, %q", project, class, callType, tc.wantProject, tc.wantClass, tc.wantCallType)
+			}
+		})
+	}
+}
+
+func TestParseAPIClassBudgets(t *testing.T) {
+	testCases := []struct {
+		name    string
+		flag    string
+		want    map[string]APIBudget
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: map[string]APIBudget{},
+		},
+		{
+			name: "budgets with and without burst",
+			flag: "instances.mutate=5:10,operations.read=2.5",
+			want: map[string]APIBudget{
+				"instances.mutate": {QPS: 5, Burst: 10},
+				"operations.read":  {QPS: 2.5, Burst: 3},
+			},
+		},
+		{
+			name:    "bad call type",
+			flag:    "instances.write=5",
+			wantErr: true,
+		},
+		{
+			name:    "bad burst",
+			flag:    "instances.mutate=5:0",
+			wantErr: true,
+		},
+		{
+			name:    "missing QPS",
+			flag:    "instances.mutate",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseAPIClassBudgets(tc.flag)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("ParseAPIClassBudgets(%q) got error %v, want error %v", tc.flag, err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ParseAPIClassBudgets(%q) = %v, want %v", tc.flag, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAPIBudgetRejectsCallsBeyondDeadline(t *testing.T) {
+	budget := newAPIBudget(APIBudgetConfig{Mutate: APIBudget{QPS: 0.001, Burst: 1}})
+	labels := map[string]string{"method_class": "instances", "call_type": apiCallMutate, "priority": "default"}
+	before := gatherMetric(t, "csidriver_compute_api_budget_rejections", labels).GetCounter().GetValue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := budget.wait(ctx, "p", "instances", apiCallMutate); err != nil {
+		t.Fatalf("wait() failed within the burst: %v", err)
+	}
+	err := budget.wait(ctx, "p", "instances", apiCallMutate)
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("wait() beyond the burst got error %v, want a 429 error", err)
+	}
+	if code := common.CodeForError(err); code != codes.ResourceExhausted {
+		t.Errorf("CodeForError() = %v, want %v", code, codes.ResourceExhausted)
+	}
+	if rejections := gatherMetric(t, "csidriver_compute_api_budget_rejections", labels).GetCounter().GetValue() - before; rejections != 1 {
+		t.Errorf("compute_api_budget_rejections = %v, want 1", rejections)
+	}
+
+	// Other projects, method classes and read calls have their own budget.
+	if err := budget.wait(ctx, "other", "instances", apiCallMutate); err != nil {
+		t.Errorf("wait() in another project failed: %v", err)
+	}
+	if err := budget.wait(ctx, "p", "disks", apiCallMutate); err != nil {
+		t.Errorf("wait() for another method class failed: %v", err)
+	}
+	if err := budget.wait(ctx, "p", "instances", apiCallRead); err != nil {
+		t.Errorf("wait() for an unlimited read failed: %v", err)
+	}
+}
+
+func TestAPIBudgetWaitsWithinDeadline(t *testing.T) {
+	budget := newAPIBudget(APIBudgetConfig{Read: APIBudget{QPS: 50, Burst: 1}})
+	labels := map[string]string{"method_class": "disks", "call_type": apiCallRead, "priority": "default"}
+	before := gatherMetric(t, "csidriver_compute_api_budget_wait_duration_seconds", labels).GetHistogram().GetSampleCount()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := budget.wait(ctx, "p", "disks", apiCallRead); err != nil {
+			t.Fatalf("wait() failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("3 calls with a burst of 1 at 50 QPS took %v, want about 40ms", elapsed)
+	}
+	if samples := gatherMetric(t, "csidriver_compute_api_budget_wait_duration_seconds", labels).GetHistogram().GetSampleCount() - before; samples != 3 {
+		t.Errorf("compute_api_budget_wait_duration_seconds has %d new samples, want 3", samples)
+	}
+}
+
+func TestAPIBudgetLowPriorityLeavesReserve(t *testing.T) {
+	budget := newAPIBudget(APIBudgetConfig{
+		Read:               APIBudget{QPS: 0.001, Burst: 4},
+		LowPriorityReserve: 0.5,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	lowCtx := WithAPIPriority(ctx, APIPriorityLow)
+
+	// Low priority calls stop once half of the bucket is left.
+	for i := 0; i < 2; i++ {
+		if err := budget.wait(lowCtx, "p", "disks", apiCallRead); err != nil {
+			t.Fatalf("low priority wait() %d failed: %v", i, err)
+		}
+	}
+	if err := budget.wait(lowCtx, "p", "disks", apiCallRead); err == nil {
+		t.Fatalf("low priority wait() took a token from the reserve")
+	}
+	// The reserve is left to default priority calls.
+	for i := 0; i < 2; i++ {
+		if err := budget.wait(ctx, "p", "disks", apiCallRead); err != nil {
+			t.Errorf("default priority wait() %d failed: %v", i, err)
+		}
+	}
+}
+
+func TestBudgetedTransport(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"name": "disk"}`))
+	}))
+	defer srv.Close()
+
+	budget := newAPIBudget(APIBudgetConfig{Read: APIBudget{QPS: 0.001, Burst: 1}})
+	client := srv.Client()
+	client.Transport = &budgetedTransport{budget: budget, base: client.Transport}
+	svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("Failed to create compute service: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := svc.Disks.Get("p", "z", "disk").Context(ctx).Do(); err != nil {
+		t.Fatalf("Disks.Get() failed: %v", err)
+	}
+	_, err = svc.Disks.Get("p", "z", "disk").Context(ctx).Do()
+	if code := common.CodeForError(err); code != codes.ResourceExhausted {
+		t.Errorf("Disks.Get() beyond the budget got error %v, want ResourceExhausted", err)
+	}
+	if requests != 1 {
+		t.Errorf("server got %d requests, want 1", requests)
+	}
+}
+
+func TestGetInstanceOrErrorWithinDeadline(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"name": "instance"}`))
+	}))
+	defer srv.Close()
+
+	budget := newAPIBudget(APIBudgetConfig{Read: APIBudget{QPS: 0.001, Burst: 1}})
+	client := srv.Client()
+	client.Transport = &budgetedTransport{budget: budget, base: client.Transport}
+	svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("Failed to create compute service: %v", err)
+	}
+	cloud := &CloudProvider{service: svc, project: "p"}
+
+	// The budget of the call is bounded by the deadline of its context.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := cloud.GetInstanceOrError(ctx, "p", "z", "instance"); err != nil {
+		t.Fatalf("GetInstanceOrError() failed: %v", err)
+	}
+	start := time.Now()
+	_, err = cloud.GetInstanceOrError(ctx, "p", "z", "instance")
+	if code := common.CodeForError(err); code != codes.ResourceExhausted {
+		t.Errorf("GetInstanceOrError() beyond the budget got error %v, want ResourceExhausted", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetInstanceOrError() beyond the budget took %v, want it rejected within the deadline", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("server got %d requests, want 1", requests)
+	}
+}
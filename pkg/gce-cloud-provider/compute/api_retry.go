@@ -0,0 +1,364 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+// apiErrorClass tells how a failed compute API call is handled.
+type apiErrorClass string
+
+const (
+	// apiErrorPermanent errors are returned to the caller as is.
+	apiErrorPermanent apiErrorClass = "permanent"
+	// apiErrorTransient errors are server or network failures. They are
+	// retried, and count towards opening the circuit breaker of the zone.
+	apiErrorTransient apiErrorClass = "transient"
+	// apiErrorThrottled errors are rate limit errors. They are retried, but
+	// say nothing about the health of the zone.
+	apiErrorThrottled apiErrorClass = "throttled"
+)
+
+// googleAPIErrorClasses classifies googleapi errors by HTTP code and, if set,
+// error reason. The first matching entry wins, errors matching no entry are
+// permanent.
+var googleAPIErrorClasses = []struct {
+	code   int
+	reason string
+	class  apiErrorClass
+}{
+	{http.StatusTooManyRequests, "", apiErrorThrottled},
+	{http.StatusForbidden, "rateLimitExceeded", apiErrorThrottled},
+	{http.StatusForbidden, "userRateLimitExceeded", apiErrorThrottled},
+	{http.StatusInternalServerError, "", apiErrorTransient},
+	{http.StatusBadGateway, "", apiErrorTransient},
+	{http.StatusServiceUnavailable, "", apiErrorTransient},
+	{http.StatusGatewayTimeout, "", apiErrorTransient},
+}
+
+// classifyGoogleAPIError returns the class of a googleapi error.
+func classifyGoogleAPIError(apiErr *googleapi.Error) apiErrorClass {
+	for _, entry := range googleAPIErrorClasses {
+		if entry.code != apiErr.Code {
+			continue
+		}
+		if entry.reason == "" {
+			return entry.class
+		}
+		for _, item := range apiErr.Errors {
+			if item.Reason == entry.reason {
+				return entry.class
+			}
+		}
+	}
+	return apiErrorPermanent
+}
+
+// classifyTransportError returns the class of an error returned by the
+// transport before any response is received.
+func classifyTransportError(err error) apiErrorClass {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return apiErrorPermanent
+	}
+	var netErr net.Error
+	if errors.Is(err, io.ErrUnexpectedEOF) || (errors.As(err, &netErr) && netErr.Timeout()) ||
+		strings.Contains(err.Error(), "connection reset by peer") || strings.Contains(err.Error(), "connection refused") {
+		return apiErrorTransient
+	}
+	return apiErrorPermanent
+}
+
+// RetryConfig configures the retries of compute API calls and the per-zone
+// circuit breakers.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of a call. Retries are
+	// disabled if it is 1 or less.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled, with
+	// jitter, for each following retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BreakerThreshold is the number of consecutive transient failures of
+	// the calls to a zone that opens its circuit breaker. Circuit breakers are
+	// disabled if it is 0.
+	BreakerThreshold int
+	// BreakerCooldown is how long a circuit breaker stays open, failing calls
+	// fast, before a single call is let through to probe the zone.
+	BreakerCooldown time.Duration
+}
+
+// breakerState is the state of a circuit breaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker fails the calls to a zone fast after repeated transient
+// failures.
+type circuitBreaker struct {
+	location  string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mux      sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// probing is true while the single call allowed in the half-open state
+	// is in flight.
+	probing bool
+}
+
+// allow returns an Unavailable error if the breaker is open.
+func (b *circuitBreaker) allow() error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			break
+		}
+		klog.Infof("Compute API circuit breaker for %s is half-open, probing", b.location)
+		b.state = breakerHalfOpen
+		b.probing = true
+		return nil
+	case breakerHalfOpen:
+		if b.probing {
+			break
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+	circuitBreakerRejectionsMetric.WithLabelValues(b.location).Inc()
+	return common.NewTemporaryError(codes.Unavailable, fmt.Errorf("compute API circuit breaker for %s is open after %d consecutive transient failures", b.location, b.failures))
+}
+
+// record updates the breaker with the outcome of a call let through.
+func (b *circuitBreaker) record(class apiErrorClass) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.probing = false
+	switch class {
+	case apiErrorTransient:
+		b.failures++
+		if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+			klog.Warningf("Opening compute API circuit breaker for %s after %d consecutive transient failures", b.location, b.failures)
+			b.state = breakerOpen
+			b.openedAt = b.now()
+			circuitBreakerOpenMetric.WithLabelValues(b.location).Set(1)
+		}
+	case apiErrorThrottled:
+		// Throttling says nothing about the health of the zone.
+	default:
+		if b.state != breakerClosed {
+			klog.Infof("Closing compute API circuit breaker for %s", b.location)
+			circuitBreakerOpenMetric.WithLabelValues(b.location).Set(0)
+		}
+		b.state = breakerClosed
+		b.failures = 0
+	}
+}
+
+// apiRetrier holds the retry policy and the circuit breakers of the compute
+// API calls.
+type apiRetrier struct {
+	config RetryConfig
+	now    func() time.Time
+	// jitter returns a random factor in [0, 1).
+	jitter func() float64
+
+	mux      sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// newAPIRetrier returns the retrier for config, or nil if config neither
+// retries calls nor opens circuit breakers.
+func newAPIRetrier(config RetryConfig) *apiRetrier {
+	if config.MaxAttempts <= 1 && config.BreakerThreshold <= 0 {
+		return nil
+	}
+	return &apiRetrier{
+		config:   config,
+		now:      time.Now,
+		jitter:   rand.Float64,
+		breakers: map[string]*circuitBreaker{},
+	}
+}
+
+// breaker returns the circuit breaker of a location, or nil if circuit
+// breakers are disabled.
+func (r *apiRetrier) breaker(location string) *circuitBreaker {
+	if r.config.BreakerThreshold <= 0 {
+		return nil
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	b, ok := r.breakers[location]
+	if !ok {
+		b = &circuitBreaker{
+			location:  location,
+			threshold: r.config.BreakerThreshold,
+			cooldown:  r.config.BreakerCooldown,
+			now:       r.now,
+		}
+		r.breakers[location] = b
+	}
+	return b
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+func (r *apiRetrier) backoff(retry int) time.Duration {
+	delay := r.config.InitialBackoff
+	for i := 1; i < retry && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, r.config.MaxBackoff)
+	// Full jitter on the upper half of the delay.
+	return delay/2 + time.Duration(r.jitter()*float64(delay/2))
+}
+
+// retryTransport retries compute API calls that are safe to retry and fails
+// calls to zones whose circuit breaker is open.
+type retryTransport struct {
+	retrier *apiRetrier
+	base    http.RoundTripper
+}
+
+// isRetriableRequest returns true if sending the request again has no other
+// effect than sending it once: reads, waits on operations, and mutations
+// with a request ID, which GCE uses to deduplicate them.
+func isRetriableRequest(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead || strings.HasSuffix(req.URL.Path, "/wait") {
+		return true
+	}
+	return req.URL.Query().Get("requestId") != ""
+}
+
+// requestLocation returns the zone or region of a compute API request, or
+// "global".
+func requestLocation(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "zones" || segments[i] == "regions" {
+			return segments[i+1]
+		}
+	}
+	return "global"
+}
+
+// classifyResponse returns the class of a failed response, or "" if the call
+// succeeded. The response body is read to get the error reason, and replaced
+// so that the caller can still read it.
+func classifyResponse(res *http.Response) apiErrorClass {
+	if res.StatusCode < 300 {
+		return ""
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return apiErrorTransient
+	}
+	checked := *res
+	checked.Body = io.NopCloser(bytes.NewReader(body))
+	var apiErr *googleapi.Error
+	if !errors.As(googleapi.CheckResponse(&checked), &apiErr) {
+		return apiErrorPermanent
+	}
+	return classifyGoogleAPIError(apiErr)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	breaker := t.retrier.breaker(requestLocation(req))
+	retriable := isRetriableRequest(req) && (req.Body == nil || req.GetBody != nil)
+	_, class, _ := classifyComputeRequest(req)
+
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		if breaker != nil {
+			if err := breaker.allow(); err != nil {
+				if attemptReq.Body != nil {
+					attemptReq.Body.Close()
+				}
+				return nil, err
+			}
+		}
+
+		res, err := t.base.RoundTrip(attemptReq)
+		var errClass apiErrorClass
+		if err != nil {
+			errClass = classifyTransportError(err)
+		} else {
+			errClass = classifyResponse(res)
+		}
+		if breaker != nil {
+			breaker.record(errClass)
+		}
+		if errClass == "" || errClass == apiErrorPermanent || !retriable || attempt >= t.retrier.config.MaxAttempts {
+			return res, err
+		}
+
+		delay := t.retrier.backoff(attempt)
+		if deadline, ok := req.Context().Deadline(); ok && t.retrier.now().Add(delay).After(deadline) {
+			return res, err
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+		klog.V(4).Infof("Retrying %s %s in %v after %s failure (attempt %d)", req.Method, req.URL.Path, delay, errClass, attempt)
+		apiRetriesMetric.WithLabelValues(class, string(errClass)).Inc()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+
+		attemptReq = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	computev1 "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+func TestClassifyGoogleAPIError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  *googleapi.Error
+		want apiErrorClass
+	}{
+		{
+			name: "service unavailable",
+			err:  &googleapi.Error{Code: http.StatusServiceUnavailable},
+			want: apiErrorTransient,
+		},
+		{
+			name: "too many requests",
+			err:  &googleapi.Error{Code: http.StatusTooManyRequests},
+			want: apiErrorThrottled,
+		},
+		{
+			name: "rate limit exceeded",
+			err:  &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}},
+			want: apiErrorThrottled,
+		},
+		{
+			name: "permission denied",
+			err:  &googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "forbidden"}}},
+			want: apiErrorPermanent,
+		},
+		{
+			name: "not found",
+			err:  &googleapi.Error{Code: http.StatusNotFound},
+			want: apiErrorPermanent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyGoogleAPIError(tc.err); got != tc.want {
+				t.Errorf("classifyGoogleAPIError(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+// flakyComputeServer fails the first failures requests with status, then
+// returns an empty disk or operation.
+type flakyComputeServer struct {
+	mux      sync.Mutex
+	failures int
+	status   int
+	requests int
+}
+
+func (s *flakyComputeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.requests++
+	if s.requests <= s.failures {
+		w.WriteHeader(s.status)
+		fmt.Fprintf(w, `{"error": {"code": %d, "message": "backend error", "errors": [{"reason": "backendError"}]}}`, s.status)
+		return
+	}
+	w.Write([]byte(`{"name": "name"}`))
+}
+
+func newRetryTestService(t *testing.T, handler http.Handler, config RetryConfig) (*computev1.Service, *apiRetrier) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	retrier := newAPIRetrier(config)
+	retrier.jitter = func() float64 { return 0 }
+	client := srv.Client()
+	client.Transport = (&computeCallPolicy{retrier: retrier}).wrap(client.Transport)
+	svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("Failed to create compute service: %v", err)
+	}
+	return svc, retrier
+}
+
+func TestRetryTransport(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	testCases := []struct {
+		name         string
+		status       int
+		failures     int
+		call         func(svc *computev1.Service) error
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:     "get is retried",
+			status:   http.StatusServiceUnavailable,
+			failures: 2,
+			call: func(svc *computev1.Service) error {
+				_, err := svc.Disks.Get("p", "z", "d").Do()
+				return err
+			},
+			wantRequests: 3,
+		},
+		{
+			name:     "get fails after max attempts",
+			status:   http.StatusServiceUnavailable,
+			failures: 3,
+			call: func(svc *computev1.Service) error {
+				_, err := svc.Disks.Get("p", "z", "d").Do()
+				return err
+			},
+			wantErr:      true,
+			wantRequests: 3,
+		},
+		{
+			name:     "not found is not retried",
+			status:   http.StatusNotFound,
+			failures: 1,
+			call: func(svc *computev1.Service) error {
+				_, err := svc.Disks.Get("p", "z", "d").Do()
+				return err
+			},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:     "insert without request ID is not retried",
+			status:   http.StatusServiceUnavailable,
+			failures: 1,
+			call: func(svc *computev1.Service) error {
+				_, err := svc.Disks.Insert("p", "z", &computev1.Disk{Name: "d"}).Do()
+				return err
+			},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:     "insert with request ID is retried",
+			status:   http.StatusServiceUnavailable,
+			failures: 1,
+			call: func(svc *computev1.Service) error {
+				_, err := svc.Disks.Insert("p", "z", &computev1.Disk{Name: "d"}).RequestId("request").Do()
+				return err
+			},
+			wantRequests: 2,
+		},
+		{
+			name:     "operation wait is retried",
+			status:   http.StatusBadGateway,
+			failures: 1,
+			call: func(svc *computev1.Service) error {
+				_, err := svc.ZoneOperations.Wait("p", "z", "op").Do()
+				return err
+			},
+			wantRequests: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &flakyComputeServer{failures: tc.failures, status: tc.status}
+			svc, _ := newRetryTestService(t, server, config)
+			err := tc.call(svc)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("call got error %v, want error %v", err, tc.wantErr)
+			}
+			if server.requests != tc.wantRequests {
+				t.Errorf("server got %d requests, want %d", server.requests, tc.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	server := &flakyComputeServer{failures: 2, status: http.StatusServiceUnavailable}
+	svc, retrier := newRetryTestService(t, server, RetryConfig{MaxAttempts: 1, BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	now := time.Now()
+	retrier.now = func() time.Time { return now }
+	labels := map[string]string{"location": "z"}
+	before := gatherMetric(t, "csidriver_compute_api_circuit_breaker_rejections", labels).GetCounter().GetValue()
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Disks.Get("p", "z", "d").Do(); err == nil {
+			t.Fatalf("Disks.Get() %d succeeded, want the server error", i)
+		}
+	}
+	if open := gatherMetric(t, "csidriver_compute_api_circuit_breaker_open", labels).GetGauge().GetValue(); open != 1 {
+		t.Errorf("compute_api_circuit_breaker_open = %v, want 1", open)
+	}
+
+	// The breaker fails calls to the zone fast, calls to other zones go on.
+	_, err := svc.Disks.Get("p", "z", "d").Do()
+	if code := common.CodeForError(err); code != codes.Unavailable {
+		t.Errorf("Disks.Get() with an open breaker got %v with code %v, want %v", err, code, codes.Unavailable)
+	}
+	var tempErr *common.TemporaryError
+	if !errors.As(err, &tempErr) {
+		t.Errorf("Disks.Get() with an open breaker got %T, want a TemporaryError", err)
+	}
+	if rejections := gatherMetric(t, "csidriver_compute_api_circuit_breaker_rejections", labels).GetCounter().GetValue() - before; rejections != 1 {
+		t.Errorf("compute_api_circuit_breaker_rejections = %v, want 1", rejections)
+	}
+	if _, err := svc.Disks.Get("p", "other-zone", "d").Do(); err != nil {
+		t.Errorf("Disks.Get() in another zone failed: %v", err)
+	}
+	if server.requests != 3 {
+		t.Errorf("server got %d requests, want 3", server.requests)
+	}
+
+	// After the cooldown, a successful probe closes the breaker.
+	now = now.Add(time.Minute)
+	if _, err := svc.Disks.Get("p", "z", "d").Do(); err != nil {
+		t.Errorf("Disks.Get() probing the zone failed: %v", err)
+	}
+	if open := gatherMetric(t, "csidriver_compute_api_circuit_breaker_open", labels).GetGauge().GetValue(); open != 0 {
+		t.Errorf("compute_api_circuit_breaker_open = %v after a successful probe, want 0", open)
+	}
+	if _, err := svc.Disks.Get("p", "z", "d").Do(); err != nil {
+		t.Errorf("Disks.Get() with a closed breaker failed: %v", err)
+	}
+}
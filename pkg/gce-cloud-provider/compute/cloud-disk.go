@@ -155,6 +155,15 @@ func (d *CloudDisk) setSizeGb(size int64) {
 	}
 }
 
+func (d *CloudDisk) setLabels(labels map[string]string) {
+	switch {
+	case d.disk != nil:
+		d.disk.Labels = labels
+	case d.betaDisk != nil:
+		d.betaDisk.Labels = labels
+	}
+}
+
 func (d *CloudDisk) GetZone() string {
 	switch {
 	case d.disk != nil:
@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultUniverseDomain is the universe domain of the public Google Cloud.
+	DefaultUniverseDomain = "googleapis.com"
+
+	// Service names, prefixed to the universe domain to build the default
+	// endpoint of each API.
+	resourceManagerServiceName = "cloudresourcemanager"
+	iamCredentialsServiceName  = "iamcredentials"
+)
+
+// apiEndpoints are the endpoints of the Google Cloud APIs used by the driver,
+// resolved from the cloud config and command line flags.
+type apiEndpoints struct {
+	// universeDomain is the universe the APIs are served from.
+	universeDomain string
+	// compute is the compute endpoint, without the API version path. If nil,
+	// the client library default for universeDomain is used.
+	compute *url.URL
+	// resourceManagerHost is the host of the resource manager API. Location
+	// specific requests are sent to "{location}-{resourceManagerHost}".
+	resourceManagerHost string
+	// iamCredentials is the endpoint of the IAM Credentials API, used to mint
+	// tokens for an impersonated service account.
+	iamCredentials string
+}
+
+// newAPIEndpoints resolves the API endpoints. An endpoint set in the cloud
+// config takes precedence over the universe domain default, and the
+// --compute-endpoint flag takes precedence over the cloud config.
+func newAPIEndpoints(configFile *ConfigFile, computeEndpoint *url.URL) (*apiEndpoints, error) {
+	var global ConfigGlobal
+	if configFile != nil {
+		global = configFile.Global
+	}
+
+	e := &apiEndpoints{
+		universeDomain:      DefaultUniverseDomain,
+		compute:             computeEndpoint,
+		resourceManagerHost: fmt.Sprintf("%s.%s", resourceManagerServiceName, DefaultUniverseDomain),
+		iamCredentials:      fmt.Sprintf("https://%s.%s", iamCredentialsServiceName, DefaultUniverseDomain),
+	}
+	if global.UniverseDomain != "" {
+		e.universeDomain = global.UniverseDomain
+		e.resourceManagerHost = fmt.Sprintf("%s.%s", resourceManagerServiceName, global.UniverseDomain)
+		e.iamCredentials = fmt.Sprintf("https://%s.%s", iamCredentialsServiceName, global.UniverseDomain)
+	}
+
+	if e.compute == nil && global.ComputeEndpoint != "" {
+		u, err := parseEndpoint(global.ComputeEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid compute-endpoint: %w", err)
+		}
+		e.compute = u
+	}
+	if global.ResourceManagerEndpoint != "" {
+		u, err := parseEndpoint(global.ResourceManagerEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource-manager-endpoint: %w", err)
+		}
+		e.resourceManagerHost = u.Host
+	}
+	if global.IAMCredentialsEndpoint != "" {
+		u, err := parseEndpoint(global.IAMCredentialsEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid iam-credentials-endpoint: %w", err)
+		}
+		e.iamCredentials = strings.TrimSuffix(u.String(), "/")
+	}
+	return e, nil
+}
+
+// parseEndpoint parses an endpoint given either as a URL or a bare host, in
+// which case https is assumed.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in endpoint %q", endpoint)
+	}
+	return u, nil
+}
+
+// resourceManagerEndpoint returns the resource manager endpoint for the
+// location, or the global endpoint if location is empty.
+func (e *apiEndpoints) resourceManagerEndpoint(location string) string {
+	if location != "" {
+		return fmt.Sprintf("https://%s-%s", location, e.resourceManagerHost)
+	}
+	return fmt.Sprintf("https://%s", e.resourceManagerHost)
+}
+
+// ComputeAPIVersionPaths returns the API version path segments a compute
+// resource self link can have, e.g. "v1" in
+// https://compute.googleapis.com/compute/v1/projects/...
+func ComputeAPIVersionPaths() []string {
+	// The driver does not use the alpha API, but resources created with it
+	// have alpha self links.
+	versions := append([]GCEAPIVersion{"alpha"}, GCEAPIVersions...)
+	paths := make([]string, 0, 2*len(versions))
+	for _, env := range []Environment{EnvironmentProduction, EnvironmentStaging} {
+		for _, v := range versions {
+			// constructComputeEndpointPath returns "compute/{version}/".
+			paths = append(paths, strings.Split(constructComputeEndpointPath(env, v), "/")[1])
+		}
+	}
+	return paths
+}
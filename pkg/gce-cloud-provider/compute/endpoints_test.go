@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"testing"
+)
+
+func TestNewAPIEndpoints(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		config                   *ConfigFile
+		computeEndpointFlag      string
+		wantUniverseDomain       string
+		wantCompute              string
+		wantResourceManager      string
+		wantZonalResourceManager string
+		wantIAMCredentials       string
+		wantErr                  bool
+	}{
+		{
+			name:                     "no config",
+			wantUniverseDomain:       "googleapis.com",
+			wantResourceManager:      "https://cloudresourcemanager.googleapis.com",
+			wantZonalResourceManager: "https://us-central1-a-cloudresourcemanager.googleapis.com",
+			wantIAMCredentials:       "https://iamcredentials.googleapis.com",
+		},
+		{
+			name:                     "universe domain",
+			config:                   &ConfigFile{Global: ConfigGlobal{UniverseDomain: "example.com"}},
+			wantUniverseDomain:       "example.com",
+			wantResourceManager:      "https://cloudresourcemanager.example.com",
+			wantZonalResourceManager: "https://us-central1-a-cloudresourcemanager.example.com",
+			wantIAMCredentials:       "https://iamcredentials.example.com",
+		},
+		{
+			name: "endpoint overrides",
+			config: &ConfigFile{Global: ConfigGlobal{
+				UniverseDomain:          "example.com",
+				ComputeEndpoint:         "https://compute-psc.p.example.com",
+				ResourceManagerEndpoint: "cloudresourcemanager-psc.p.example.com",
+				IAMCredentialsEndpoint:  "https://iamcredentials-psc.p.example.com/",
+			}},
+			wantUniverseDomain:       "example.com",
+			wantCompute:              "https://compute-psc.p.example.com",
+			wantResourceManager:      "https://cloudresourcemanager-psc.p.example.com",
+			wantZonalResourceManager: "https://us-central1-a-cloudresourcemanager-psc.p.example.com",
+			wantIAMCredentials:       "https://iamcredentials-psc.p.example.com",
+		},
+		{
+			name:                     "compute endpoint flag takes precedence",
+			config:                   &ConfigFile{Global: ConfigGlobal{ComputeEndpoint: "https://compute-psc.p.googleapis.com"}},
+			computeEndpointFlag:      "https://compute.googleapis.com",
+			wantUniverseDomain:       "googleapis.com",
+			wantCompute:              "https://compute.googleapis.com",
+			wantResourceManager:      "https://cloudresourcemanager.googleapis.com",
+			wantZonalResourceManager: "https://us-central1-a-cloudresourcemanager.googleapis.com",
+			wantIAMCredentials:       "https://iamcredentials.googleapis.com",
+		},
+		{
+			name:    "invalid endpoint",
+			config:  &ConfigFile{Global: ConfigGlobal{ResourceManagerEndpoint: "https://"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newAPIEndpoints(tc.config, convertStringToURL(tc.computeEndpointFlag))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("newAPIEndpoints() got error %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got.universeDomain != tc.wantUniverseDomain {
+				t.Errorf("universeDomain = %q, want %q", got.universeDomain, tc.wantUniverseDomain)
+			}
+			gotCompute := ""
+			if got.compute != nil {
+				gotCompute = got.compute.String()
+			}
+			if gotCompute != tc.wantCompute {
+				t.Errorf("compute = %q, want %q", gotCompute, tc.wantCompute)
+			}
+			if e := got.resourceManagerEndpoint(""); e != tc.wantResourceManager {
+				t.Errorf("resourceManagerEndpoint(\"\") = %q, want %q", e, tc.wantResourceManager)
+			}
+			if e := got.resourceManagerEndpoint("us-central1-a"); e != tc.wantZonalResourceManager {
+				t.Errorf("resourceManagerEndpoint(\"us-central1-a\") = %q, want %q", e, tc.wantZonalResourceManager)
+			}
+			if got.iamCredentials != tc.wantIAMCredentials {
+				t.Errorf("iamCredentials = %q, want %q", got.iamCredentials, tc.wantIAMCredentials)
+			}
+		})
+	}
+}
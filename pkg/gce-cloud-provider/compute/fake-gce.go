@@ -30,6 +30,7 @@ import (
 	"google.golang.org/grpc/status"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -60,6 +61,11 @@ type FakeCloudProvider struct {
 	instances  map[string]*computev1.Instance
 	snapshots  map[string]*computev1.Snapshot
 	images     map[string]*computev1.Image
+	// tags holds the resource manager tags bound to each resource, keyed by
+	// TagBindingRequest.key().
+	tags map[string]map[string]string
+
+	tenantLimits map[string]tenancy.Limits
 
 	// marker to set disk status during InsertDisk operation.
 	mockDiskStatus string
@@ -69,13 +75,15 @@ var _ GCECompute = &FakeCloudProvider{}
 
 func CreateFakeCloudProvider(project, zone string, cloudDisks []*CloudDisk) (*FakeCloudProvider, error) {
 	fcp := &FakeCloudProvider{
-		project:    project,
-		zone:       zone,
-		disks:      map[string]*CloudDisk{},
-		instances:  map[string]*computev1.Instance{},
-		snapshots:  map[string]*computev1.Snapshot{},
-		images:     map[string]*computev1.Image{},
-		pageTokens: map[string]sets.String{},
+		project:      project,
+		zone:         zone,
+		disks:        map[string]*CloudDisk{},
+		instances:    map[string]*computev1.Instance{},
+		snapshots:    map[string]*computev1.Snapshot{},
+		images:       map[string]*computev1.Image{},
+		tags:         map[string]map[string]string{},
+		pageTokens:   map[string]sets.String{},
+		tenantLimits: map[string]tenancy.Limits{},
 		// A newly created disk is marked READY by default.
 		mockDiskStatus: "READY",
 	}
@@ -97,6 +105,17 @@ func (cloud *FakeCloudProvider) GetDefaultProject() string {
 	return cloud.project
 }
 
+func (cloud *FakeCloudProvider) GetTenantLimits(projectNumber string) (tenancy.Limits, bool) {
+	limits, ok := cloud.tenantLimits[projectNumber]
+	return limits, ok
+}
+
+// SetTenantLimits configures the limits returned by GetTenantLimits for the
+// given tenant project number.
+func (cloud *FakeCloudProvider) SetTenantLimits(projectNumber string, limits tenancy.Limits) {
+	cloud.tenantLimits[projectNumber] = limits
+}
+
 func (cloud *FakeCloudProvider) GetDefaultZone() string {
 	return cloud.zone
 }
@@ -208,10 +227,17 @@ func (cloud *FakeCloudProvider) InsertDisk(ctx context.Context, project string,
 		}
 	}
 
+	description := "Disk created by GCE-PD CSI Driver"
+	if len(params.Tags) > 0 {
+		var err error
+		if description, err = encodeTags(params.Tags); err != nil {
+			return err
+		}
+	}
 	computeDisk := &computebeta.Disk{
 		Name:                      volKey.Name,
 		SizeGb:                    common.BytesToGbRoundUp(capBytes),
-		Description:               "Disk created by GCE-PD CSI Driver",
+		Description:               description,
 		Type:                      cloud.GetDiskTypeURI(project, volKey, params.DiskType),
 		SourceDiskId:              volumeContentSourceVolumeID,
 		Status:                    cloud.mockDiskStatus,
@@ -255,6 +281,7 @@ func (cloud *FakeCloudProvider) InsertDisk(ctx context.Context, project string,
 	}
 
 	cloud.disks[volKey.String()] = CloudDiskFromBeta(computeDisk)
+	operationStarted(ctx)
 	return nil
 }
 
@@ -292,7 +319,7 @@ func (cloud *FakeCloudProvider) DeleteDisk(ctx context.Context, project string,
 	return nil
 }
 
-func (cloud *FakeCloudProvider) AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName string, forceAttach bool) error {
+func (cloud *FakeCloudProvider) AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName, instanceFingerprint string, forceAttach bool) error {
 	source := cloud.GetDiskSourceURI(project, volKey)
 
 	attachedDiskV1 := &computev1.AttachedDisk{
@@ -311,7 +338,7 @@ func (cloud *FakeCloudProvider) AttachDisk(ctx context.Context, project string,
 	return nil
 }
 
-func (cloud *FakeCloudProvider) DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName string) error {
+func (cloud *FakeCloudProvider) DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName, instanceFingerprint string) error {
 	instance, ok := cloud.instances[instanceName]
 	if !ok {
 		return fmt.Errorf("Failed to get instance %v", instanceName)
@@ -404,8 +431,13 @@ func (cloud *FakeCloudProvider) CreateSnapshot(ctx context.Context, project stri
 		return snapshot, nil
 	}
 
+	description, err := encodeTags(snapshotParams.Tags)
+	if err != nil {
+		return nil, err
+	}
 	snapshotToCreate := &computev1.Snapshot{
 		Name:              snapshotName,
+		Description:       description,
 		DiskSizeGb:        int64(DiskSizeGb),
 		CreationTimestamp: Timestamp,
 		Status:            "UPLOADING",
@@ -434,6 +466,8 @@ func (cloud *FakeCloudProvider) ResizeDisk(ctx context.Context, project string,
 
 	requestSizGb := common.BytesToGbRoundUp(requestBytes)
 
+	// Like GCE, the disk keeps its size while the resize is running.
+	operationStarted(ctx)
 	disk.setSizeGb(requestSizGb)
 
 	return requestSizGb, nil
@@ -485,8 +519,13 @@ func (cloud *FakeCloudProvider) CreateImage(ctx context.Context, project string,
 		return image, nil
 	}
 
+	description, err := encodeTags(snapshotParams.Tags)
+	if err != nil {
+		return nil, err
+	}
 	imageToCreate := &computev1.Image{
 		CreationTimestamp: Timestamp,
+		Description:       description,
 		DiskSizeGb:        int64(DiskSizeGb),
 		Family:            snapshotParams.ImageFamily,
 		Name:              imageName,
@@ -515,6 +554,52 @@ func (cloud *FakeCloudProvider) DeleteImage(ctx context.Context, project, imageN
 	return nil
 }
 
+// Label and Tag Methods
+func (cloud *FakeCloudProvider) SetDiskLabels(ctx context.Context, project string, volKey *meta.Key, labels map[string]string, labelFingerprint string) error {
+	disk, ok := cloud.disks[volKey.String()]
+	if !ok {
+		return notFoundError()
+	}
+	disk.setLabels(labels)
+	return nil
+}
+
+func (cloud *FakeCloudProvider) SetSnapshotLabels(ctx context.Context, project, snapshotName string, labels map[string]string, labelFingerprint string) error {
+	snapshot, ok := cloud.snapshots[snapshotName]
+	if !ok {
+		return notFoundError()
+	}
+	snapshot.Labels = labels
+	return nil
+}
+
+func (cloud *FakeCloudProvider) SetImageLabels(ctx context.Context, project, imageName string, labels map[string]string, labelFingerprint string) error {
+	image, ok := cloud.images[imageName]
+	if !ok {
+		return notFoundError()
+	}
+	image.Labels = labels
+	return nil
+}
+
+func (cloud *FakeCloudProvider) GetEffectiveTags(ctx context.Context, req TagBindingRequest) (map[string]string, error) {
+	tags := map[string]string{}
+	for k, v := range cloud.tags[req.key()] {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func (cloud *FakeCloudProvider) BindTags(ctx context.Context, req TagBindingRequest) error {
+	if cloud.tags[req.key()] == nil {
+		cloud.tags[req.key()] = map[string]string{}
+	}
+	for k, v := range req.Tags {
+		cloud.tags[req.key()][k] = k + "/" + v
+	}
+	return nil
+}
+
 func (cloud *FakeCloudProvider) ValidateExistingSnapshot(resp *computev1.Snapshot, volKey *meta.Key) error {
 	if resp == nil {
 		return fmt.Errorf("disk does not exist")
@@ -597,24 +682,24 @@ func (cloud *FakeBlockingCloudProvider) CreateImage(ctx context.Context, project
 	return cloud.FakeCloudProvider.CreateImage(ctx, project, volKey, imageName, snapshotParams)
 }
 
-func (cloud *FakeBlockingCloudProvider) DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName string) error {
+func (cloud *FakeBlockingCloudProvider) DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName, instanceFingerprint string) error {
 	execute := make(chan Signal)
 	cloud.ReadyToExecute <- execute
 	val := <-execute
 	if val.ReportError {
 		return fmt.Errorf("force mock error for DetachDisk device %s", deviceName)
 	}
-	return cloud.FakeCloudProvider.DetachDisk(ctx, project, deviceName, instanceZone, instanceName)
+	return cloud.FakeCloudProvider.DetachDisk(ctx, project, deviceName, instanceZone, instanceName, instanceFingerprint)
 }
 
-func (cloud *FakeBlockingCloudProvider) AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName string, forceAttach bool) error {
+func (cloud *FakeBlockingCloudProvider) AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName, instanceFingerprint string, forceAttach bool) error {
 	execute := make(chan Signal)
 	cloud.ReadyToExecute <- execute
 	val := <-execute
 	if val.ReportError {
 		return fmt.Errorf("force mock error for AttachDisk: volkey %s", volKey)
 	}
-	return cloud.FakeCloudProvider.AttachDisk(ctx, project, volKey, readWrite, diskType, instanceZone, instanceName, forceAttach)
+	return cloud.FakeCloudProvider.AttachDisk(ctx, project, volKey, readWrite, diskType, instanceZone, instanceName, instanceFingerprint, forceAttach)
 }
 
 func notFoundError() *googleapi.Error {
@@ -21,7 +21,10 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	rscmgr "cloud.google.com/go/resourcemanager/apiv3"
@@ -41,6 +44,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/utils/strings/slices"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
 )
 
 const (
@@ -51,6 +55,9 @@ const (
 	cryptoKeyVerDelimiter          = "/cryptoKeyVersions"
 	// Example message: "[pd-standard] features are not compatible for creating instance"
 	pdDiskTypeUnsupportedPattern = `\[([a-z-]+)\] features are not compatible for creating instance`
+	// maxConcurrentProjectLists bounds the number of projects listed
+	// concurrently when fanning out List calls over the tenant projects.
+	maxConcurrentProjectLists = 10
 )
 
 var pdDiskTypeUnsupportedRegex = regexp.MustCompile(pdDiskTypeUnsupportedPattern)
@@ -103,8 +110,8 @@ type GCECompute interface {
 	InsertDisk(ctx context.Context, project string, volKey *meta.Key, params common.DiskParameters, capBytes int64, capacityRange *csi.CapacityRange, replicaZones []string, snapshotID string, volumeContentSourceVolumeID string, multiWriter bool, accessMode string) error
 	DeleteDisk(ctx context.Context, project string, volumeKey *meta.Key) error
 	UpdateDisk(ctx context.Context, project string, volKey *meta.Key, existingDisk *CloudDisk, params common.ModifyVolumeParameters) error
-	AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName string, forceAttach bool) error
-	DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName string) error
+	AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName, instanceFingerprint string, forceAttach bool) error
+	DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName, instanceFingerprint string) error
 	SetDiskAccessMode(ctx context.Context, project string, volKey *meta.Key, accessMode string) error
 	ListCompatibleDiskTypeZones(ctx context.Context, project string, zones []string, diskType string) ([]string, error)
 	GetDiskSourceURI(project string, volKey *meta.Key) string
@@ -128,6 +135,14 @@ type GCECompute interface {
 	GetImage(ctx context.Context, project, imageName string) (*computev1.Image, error)
 	CreateImage(ctx context.Context, project string, volKey *meta.Key, imageName string, snapshotParams common.SnapshotParameters) (*computev1.Image, error)
 	DeleteImage(ctx context.Context, project, imageName string) error
+	// Label and Tag Methods
+	SetDiskLabels(ctx context.Context, project string, volKey *meta.Key, labels map[string]string, labelFingerprint string) error
+	SetSnapshotLabels(ctx context.Context, project, snapshotName string, labels map[string]string, labelFingerprint string) error
+	SetImageLabels(ctx context.Context, project, imageName string, labels map[string]string, labelFingerprint string) error
+	GetEffectiveTags(ctx context.Context, req TagBindingRequest) (map[string]string, error)
+	BindTags(ctx context.Context, req TagBindingRequest) error
+	// Tenant Methods
+	GetTenantLimits(projectNumber string) (tenancy.Limits, bool)
 }
 
 // GetDefaultProject returns the project that was used to instantiate this GCE client.
@@ -140,6 +155,33 @@ func (cloud *CloudProvider) GetDefaultZone() string {
 	return cloud.zone
 }
 
+// GetTenantLimits returns the persistent disk guardrails configured for the
+// tenant with the given project number. The boolean is false if the project
+// is not a known tenant or the tenant has no limits configured.
+func (cloud *CloudProvider) GetTenantLimits(projectNumber string) (tenancy.Limits, bool) {
+	cloud.tenantLimitsMutex.RLock()
+	defer cloud.tenantLimitsMutex.RUnlock()
+	limits, ok := cloud.tenantLimits[projectNumber]
+	return limits, ok
+}
+
+func (cloud *CloudProvider) setTenantLimits(tenantMeta *tenancy.Metadata) {
+	cloud.tenantLimitsMutex.Lock()
+	defer cloud.tenantLimitsMutex.Unlock()
+	if tenantMeta.Limits.IsEmpty() {
+		delete(cloud.tenantLimits, tenantMeta.ProjectNumber)
+		return
+	}
+	klog.V(4).Infof("Setting limits for tenant %s (Project: %s): %+v", tenantMeta.TenantName, tenantMeta.ProjectNumber, tenantMeta.Limits)
+	cloud.tenantLimits[tenantMeta.ProjectNumber] = tenantMeta.Limits
+}
+
+func (cloud *CloudProvider) deleteTenantLimits(tenantMeta *tenancy.Metadata) {
+	cloud.tenantLimitsMutex.Lock()
+	defer cloud.tenantLimitsMutex.Unlock()
+	delete(cloud.tenantLimits, tenantMeta.ProjectNumber)
+}
+
 // ListDisks lists disks based on maxEntries and pageToken only in the project
 // and region that the driver is running in.
 func (cloud *CloudProvider) ListDisks(ctx context.Context, fields []googleapi.Field) ([]*computev1.Disk, string, error) {
@@ -160,46 +202,29 @@ func (cloud *CloudProvider) listDisksInternal(ctx context.Context, fields []goog
 	if err != nil {
 		return nil, "", err
 	}
-	disks := []*computev1.Disk{}
 
-	klog.Infof("Getting regional disks for project: %s", cloud.project)
-	rDisks, err := listRegionalDisksForProject(cloud.service, cloud.project, region, fields, filter)
-	if err != nil {
-		return nil, "", err
-	}
-	disks = append(disks, rDisks...)
-	// listing out regional disks in the region for each tenant project
-	for p, s := range cloud.tenantServiceMap {
-		klog.Infof("Getting regional disks for tenant project: %s", p)
-		rDisks, err := listRegionalDisksForProject(s, p, region, fields, filter)
+	disks, err := listAcrossProjects(ctx, cloud.projectServices(), func(ctx context.Context, ps projectService) ([]*computev1.Disk, error) {
+		klog.Infof("Getting regional disks for project: %s", ps.project)
+		rDisks, err := listRegionalDisksForProject(ctx, ps.service, ps.project, region, fields, filter)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
-		disks = append(disks, rDisks...)
-	}
-
-	klog.Infof("Getting zonal disks for project: %s", cloud.project)
-	zDisks, err := listZonalDisksForProject(cloud.service, cloud.project, zones, fields, filter)
-	if err != nil {
-		return nil, "", err
-	}
-	disks = append(disks, zDisks...)
-	// listing out zonal disks in all zones of the region for each tenant project
-	for p, s := range cloud.tenantServiceMap {
-		klog.Infof("Getting zonal disks for tenant project: %s", p)
-		zDisks, err := listZonalDisksForProject(s, p, zones, fields, filter)
+		klog.Infof("Getting zonal disks for project: %s", ps.project)
+		zDisks, err := listZonalDisksForProject(ctx, ps.service, ps.project, zones, fields, filter)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
-		disks = append(disks, zDisks...)
+		return append(rDisks, zDisks...), nil
+	})
+	if err != nil {
+		return nil, "", err
 	}
-
 	return disks, "", nil
 }
 
-func listRegionalDisksForProject(service *computev1.Service, project string, region string, fields []googleapi.Field, filter string) ([]*computev1.Disk, error) {
+func listRegionalDisksForProject(ctx context.Context, service *computev1.Service, project string, region string, fields []googleapi.Field, filter string) ([]*computev1.Disk, error) {
 	items := []*computev1.Disk{}
-	rlCall := service.RegionDisks.List(project, region)
+	rlCall := service.RegionDisks.List(project, region).Context(ctx)
 	rlCall.Fields(fields...)
 	rlCall.Filter(filter)
 	nextPageToken := "pageToken"
@@ -215,10 +240,10 @@ func listRegionalDisksForProject(service *computev1.Service, project string, reg
 	return items, nil
 }
 
-func listZonalDisksForProject(service *computev1.Service, project string, zones []string, fields []googleapi.Field, filter string) ([]*computev1.Disk, error) {
+func listZonalDisksForProject(ctx context.Context, service *computev1.Service, project string, zones []string, fields []googleapi.Field, filter string) ([]*computev1.Disk, error) {
 	items := []*computev1.Disk{}
 	for _, zone := range zones {
-		lCall := service.Disks.List(project, zone)
+		lCall := service.Disks.List(project, zone).Context(ctx)
 		lCall.Fields(fields...)
 		lCall.Filter(filter)
 		nextPageToken := "pageToken"
@@ -247,29 +272,20 @@ func (cloud *CloudProvider) ListInstances(ctx context.Context, fields []googleap
 	if err != nil {
 		return nil, "", err
 	}
-	items := []*computev1.Instance{}
-	instances, err := cloud.listInstancesForProject(cloud.service, cloud.project, zones, fields)
+	items, err := listAcrossProjects(ctx, cloud.projectServices(), func(ctx context.Context, ps projectService) ([]*computev1.Instance, error) {
+		return cloud.listInstancesForProject(ctx, ps.service, ps.project, zones, fields)
+	})
 	if err != nil {
 		return nil, "", err
 	}
-	items = append(items, instances...)
-
-	for p, s := range cloud.tenantServiceMap {
-		instances, err := cloud.listInstancesForProject(s, p, zones, fields)
-		if err != nil {
-			return nil, "", err
-		}
-		items = append(items, instances...)
-	}
-
 	return items, "", nil
 }
 
-func (cloud *CloudProvider) listInstancesForProject(service *computev1.Service, project string, zones []string, fields []googleapi.Field) ([]*computev1.Instance, error) {
+func (cloud *CloudProvider) listInstancesForProject(ctx context.Context, service *computev1.Service, project string, zones []string, fields []googleapi.Field) ([]*computev1.Instance, error) {
 	items := []*computev1.Instance{}
 
 	for _, zone := range zones {
-		lCall := service.Instances.List(project, zone)
+		lCall := service.Instances.List(project, zone).Context(ctx)
 		for _, filter := range cloud.listInstancesConfig.Filters {
 			lCall = lCall.Filter(filter)
 		}
@@ -288,6 +304,95 @@ func (cloud *CloudProvider) listInstancesForProject(service *computev1.Service,
 	return items, nil
 }
 
+// projectService is a project along with the Compute Service used to access it.
+type projectService struct {
+	project string
+	service *computev1.Service
+}
+
+// projectServices returns the default project followed by every tenant
+// project sorted by project number. The order is stable so results merged
+// across projects can be paginated consistently.
+func (cloud *CloudProvider) projectServices() []projectService {
+	tenantServiceMutex.Lock()
+	defer tenantServiceMutex.Unlock()
+	services := []projectService{{project: cloud.project, service: cloud.service}}
+	tenants := make([]string, 0, len(cloud.tenantServiceMap))
+	for p := range cloud.tenantServiceMap {
+		if p != cloud.project {
+			tenants = append(tenants, p)
+		}
+	}
+	sort.Strings(tenants)
+	for _, p := range tenants {
+		services = append(services, projectService{project: p, service: cloud.tenantServiceMap[p]})
+	}
+	return services
+}
+
+// serviceForProject returns the Compute Service for a tenant project, or the
+// default Compute Service if project is not a tenant project.
+func (cloud *CloudProvider) serviceForProject(project string) *computev1.Service {
+	tenantServiceMutex.Lock()
+	defer tenantServiceMutex.Unlock()
+	if service, ok := cloud.tenantServiceMap[project]; ok {
+		return service
+	}
+	return cloud.service
+}
+
+// listAcrossProjects calls listFn for each project, running at most
+// maxConcurrentProjectLists calls at a time, and merges the results in the
+// order of projects. The first error cancels the remaining calls.
+func listAcrossProjects[T any](ctx context.Context, projects []projectService, listFn func(context.Context, projectService) ([]T, error)) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]T, len(projects))
+	errs := make([]error, len(projects))
+	sem := make(chan struct{}, maxConcurrentProjectLists)
+	var wg sync.WaitGroup
+	for i, ps := range projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				errs[i] = ctx.Err()
+				return
+			}
+			results[i], errs[i] = listFn(ctx, ps)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("failed to list resources in project %s: %w", ps.project, errs[i])
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Report the first error that was not caused by cancelling the remaining
+	// calls.
+	var firstErr error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if firstErr == nil || (errors.Is(firstErr, context.Canceled) && !errors.Is(err, context.Canceled)) {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	items := []T{}
+	for _, r := range results {
+		items = append(items, r...)
+	}
+	return items, nil
+}
+
 // RepairUnderspecifiedVolumeKey will query the cloud provider and check each zone for the disk specified
 // by the volume key and return a volume key with a correct zone
 func (cloud *CloudProvider) RepairUnderspecifiedVolumeKey(ctx context.Context, project string, volumeKey *meta.Key) (string, *meta.Key, error) {
@@ -349,7 +454,7 @@ func (cloud *CloudProvider) ListZones(ctx context.Context, region string) ([]str
 		return cloud.zonesCache[region], nil
 	}
 	zones := []string{}
-	zoneList, err := cloud.service.Zones.List(cloud.project).Filter(fmt.Sprintf("region eq .*%s$", region)).Do()
+	zoneList, err := cloud.service.Zones.List(cloud.project).Filter(fmt.Sprintf("region eq .*%s$", region)).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list zones in region %s: %w", region, err)
 	}
@@ -363,16 +468,23 @@ func (cloud *CloudProvider) ListZones(ctx context.Context, region string) ([]str
 
 func (cloud *CloudProvider) ListSnapshots(ctx context.Context, filter string) ([]*computev1.Snapshot, string, error) {
 	klog.V(5).Infof("Listing snapshots with filter: %s", filter)
-	items := []*computev1.Snapshot{}
-	lCall := cloud.service.Snapshots.List(cloud.project).Filter(filter)
-	nextPageToken := "pageToken"
-	for nextPageToken != "" {
-		snapshotList, err := lCall.Do()
-		if err != nil {
-			return nil, "", err
+	items, err := listAcrossProjects(ctx, cloud.projectServices(), func(ctx context.Context, ps projectService) ([]*computev1.Snapshot, error) {
+		items := []*computev1.Snapshot{}
+		lCall := ps.service.Snapshots.List(ps.project).Context(ctx).Filter(filter)
+		nextPageToken := "pageToken"
+		for nextPageToken != "" {
+			snapshotList, err := lCall.Do()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, snapshotList.Items...)
+			nextPageToken = snapshotList.NextPageToken
+			lCall.PageToken(nextPageToken)
 		}
-		items = append(items, snapshotList.Items...)
-		nextPageToken = snapshotList.NextPageToken
+		return items, nil
+	})
+	if err != nil {
+		return nil, "", err
 	}
 	return items, "", nil
 }
@@ -526,7 +638,26 @@ func (cloud *CloudProvider) InsertDisk(ctx context.Context, project string, volK
 		return err
 	}
 
-	return cloud.insertConstructedDisk(ctx, diskToCreate, isZonal, project, volKey, params, capacityRange, multiWriter, accessMode)
+	if err := cloud.insertConstructedDisk(ctx, diskToCreate, isZonal, project, volKey, params, capacityRange, multiWriter, accessMode); err != nil {
+		return err
+	}
+	if cloud.TagReconciler != nil {
+		// The tags are bound when the disk is created. Queue the disk to
+		// verify its bindings.
+		location := volKey.Zone
+		if !isZonal {
+			location = volKey.Region
+		}
+		cloud.TagReconciler.Enqueue(TagBindingRequest{
+			Project:      project,
+			ResourceType: DisksType,
+			Location:     location,
+			IsZonal:      isZonal,
+			Name:         volKey.Name,
+			Tags:         params.ResourceTags,
+		})
+	}
+	return nil
 }
 
 func (cloud *CloudProvider) constructDiskToCreate(
@@ -600,7 +731,7 @@ func (cloud *CloudProvider) constructDiskToCreate(
 	}
 	diskToCreate.EnableConfidentialCompute = params.EnableConfidentialCompute
 
-	resourceTags, err := getResourceManagerTags(ctx, cloud.tokenSource, params.ResourceTags)
+	resourceTags, err := getResourceManagerTags(ctx, cloud.tokenSource, cloud.endpoints, params.ResourceTags)
 	if err != nil {
 		return nil, err
 	}
@@ -643,19 +774,40 @@ func (cloud *CloudProvider) processDiskAlreadyExistErr(ctx context.Context, err
 	return err
 }
 
+type operationStartedKey struct{}
+
+// WithOperationStarted returns a context whose InsertDisk and ResizeDisk
+// calls call started once GCE accepted the operation, before waiting for it
+// to complete. started is not called if no operation is started.
+func WithOperationStarted(ctx context.Context, started func()) context.Context {
+	return context.WithValue(ctx, operationStartedKey{}, started)
+}
+
+func operationStarted(ctx context.Context) {
+	if started, ok := ctx.Value(operationStartedKey{}).(func()); ok {
+		started()
+	}
+}
+
 func (cloud *CloudProvider) insertConstructedDisk(ctx context.Context, disk *computebeta.Disk, isZonal bool, project string, volKey *meta.Key, params common.DiskParameters, capacityRange *csi.CapacityRange, multiWriter bool, accessMode string) error {
 	var (
 		insertOp *computebeta.Operation
 		opName   string
 		err      error
 	)
+	journalKey := diskJournalKey(project, volKey)
+	journalTarget := fmt.Sprintf("%s/%d", disk.Type, disk.SizeGb)
+	if done, err := cloud.resumeJournaledOperation(ctx, journalKey, journalKindInsert, journalTarget); err != nil || done {
+		return err
+	}
+	rid := cloud.requestIDs.next("insert", project, volKey.String(), strconv.FormatInt(disk.SizeGb, 10), disk.Type)
 	if isZonal {
-		insertOp, err = cloud.betaService.Disks.Insert(project, volKey.Zone, disk).Context(ctx).Do()
+		insertOp, err = cloud.betaService.Disks.Insert(project, volKey.Zone, disk).RequestId(rid.id).Context(ctx).Do()
 		if insertOp != nil {
 			opName = insertOp.Name
 		}
 	} else {
-		insertOp, err = cloud.betaService.RegionDisks.Insert(project, volKey.Region, disk).Context(ctx).Do()
+		insertOp, err = cloud.betaService.RegionDisks.Insert(project, volKey.Region, disk).RequestId(rid.id).Context(ctx).Do()
 		if insertOp != nil {
 			opName = insertOp.Name
 		}
@@ -666,12 +818,16 @@ func (cloud *CloudProvider) insertConstructedDisk(ctx context.Context, disk *com
 		return fmt.Errorf("unknown Insert disk error: %w", err)
 	}
 
-	klog.V(5).Infof("InsertDisk operation %s for disk %s", opName, disk.Name)
+	klog.V(5).Infof("InsertDisk operation %s (request %s) for disk %s", opName, rid.id, disk.Name)
+	cloud.journalOperation(ctx, journalKey, JournaledOperation{Name: opName, Kind: journalKindInsert, Target: journalTarget, Project: project, Zone: volKey.Zone, Region: volKey.Region})
+	operationStarted(ctx)
 	if isZonal {
 		err = cloud.waitForZonalOp(ctx, project, opName, volKey.Zone)
 	} else {
 		err = cloud.waitForRegionalOp(ctx, project, opName, volKey.Region)
 	}
+	cloud.requestIDs.done(ctx, rid, err)
+	cloud.finishJournaledOperation(ctx, journalKey, opName, err)
 
 	if filterErr := cloud.processDiskAlreadyExistErr(ctx, err, project, volKey, params, capacityRange, multiWriter, accessMode); filterErr != nil {
 		return common.NewTemporaryError(codes.Unavailable, fmt.Errorf("unknown error when polling the operation: %w", err))
@@ -833,6 +989,9 @@ func convertBetaDiskToV1Disk(betaDisk *computebeta.Disk) *computev1.Disk {
 		SelfLink:          betaDisk.SelfLink,
 		Params:            params,
 		AccessMode:        betaDisk.AccessMode,
+		Labels:            betaDisk.Labels,
+		LabelFingerprint:  betaDisk.LabelFingerprint,
+		Id:                betaDisk.Id,
 	}
 
 	if betaDisk.ProvisionedIops > 0 {
@@ -894,7 +1053,10 @@ func (cloud *CloudProvider) deleteRegionalDisk(ctx context.Context, project, reg
 	return nil
 }
 
-func (cloud *CloudProvider) AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName string, forceAttach bool) error {
+// AttachDisk attaches the disk of volKey to an instance. instanceFingerprint
+// is the fingerprint of the instance read by the caller, from which the
+// request ID of the call is derived. No request ID is sent if it is empty.
+func (cloud *CloudProvider) AttachDisk(ctx context.Context, project string, volKey *meta.Key, readWrite, diskType, instanceZone, instanceName, instanceFingerprint string, forceAttach bool) error {
 	klog.V(5).Infof("Attaching disk %v to %s", volKey, instanceName)
 	source := cloud.GetDiskSourceURI(project, volKey)
 
@@ -914,36 +1076,60 @@ func (cloud *CloudProvider) AttachDisk(ctx context.Context, project string, volK
 		ForceAttach: forceAttach,
 	}
 
-	service := cloud.service
-	if _, ok := cloud.tenantServiceMap[project]; ok {
-		service = cloud.tenantServiceMap[project]
+	journalKey := deviceJournalKey(project, instanceZone, instanceName, deviceName)
+	if done, err := cloud.resumeJournaledOperation(ctx, journalKey, journalKindAttach, ""); err != nil || done {
+		return err
 	}
-	op, err := service.Instances.AttachDisk(project, instanceZone, instanceName, attachedDiskV1).Context(ctx).ForceAttach(forceAttach).Do()
+
+	service := cloud.serviceForProject(project)
+	rid := instanceRequestID("attach", project, instanceZone, instanceName, deviceName, instanceFingerprint)
+	op, err := callWithInstanceRequestID(rid, func(rid string) (*computev1.Operation, error) {
+		call := service.Instances.AttachDisk(project, instanceZone, instanceName, attachedDiskV1).Context(ctx).ForceAttach(forceAttach)
+		if rid != "" {
+			call = call.RequestId(rid)
+		}
+		return call.Do()
+	})
 	if err != nil {
 		return fmt.Errorf("failed cloud service attach disk call: %w", err)
 	}
-	klog.V(5).Infof("AttachDisk operation %s for disk %s", op.Name, attachedDiskV1.DeviceName)
+	klog.V(5).Infof("AttachDisk operation %s (request %s) for disk %s", op.Name, rid, attachedDiskV1.DeviceName)
+	cloud.journalOperation(ctx, journalKey, JournaledOperation{Name: op.Name, Kind: journalKindAttach, Project: project, Zone: instanceZone})
 
 	err = cloud.waitForZonalOp(ctx, project, op.Name, instanceZone)
+	cloud.finishJournaledOperation(ctx, journalKey, op.Name, err)
 	if err != nil {
 		return fmt.Errorf("failed when waiting for zonal op: %w", err)
 	}
 	return nil
 }
 
-func (cloud *CloudProvider) DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName string) error {
+// DetachDisk detaches the disk of deviceName from an instance, with a
+// request ID derived from instanceFingerprint like AttachDisk.
+func (cloud *CloudProvider) DetachDisk(ctx context.Context, project, deviceName, instanceZone, instanceName, instanceFingerprint string) error {
 	klog.V(5).Infof("Detaching disk %v from %v", deviceName, instanceName)
-	service := cloud.service
-	if _, ok := cloud.tenantServiceMap[project]; ok {
-		service = cloud.tenantServiceMap[project]
+	journalKey := deviceJournalKey(project, instanceZone, instanceName, deviceName)
+	if done, err := cloud.resumeJournaledOperation(ctx, journalKey, journalKindDetach, ""); err != nil || done {
+		return err
 	}
-	op, err := service.Instances.DetachDisk(project, instanceZone, instanceName, deviceName).Context(ctx).Do()
+
+	service := cloud.serviceForProject(project)
+	rid := instanceRequestID("detach", project, instanceZone, instanceName, deviceName, instanceFingerprint)
+	op, err := callWithInstanceRequestID(rid, func(rid string) (*computev1.Operation, error) {
+		call := service.Instances.DetachDisk(project, instanceZone, instanceName, deviceName).Context(ctx)
+		if rid != "" {
+			call = call.RequestId(rid)
+		}
+		return call.Do()
+	})
 	if err != nil {
 		return err
 	}
-	klog.V(5).Infof("DetachDisk operation %s for disk %s", op.Name, deviceName)
+	klog.V(5).Infof("DetachDisk operation %s (request %s) for disk %s", op.Name, rid, deviceName)
+	cloud.journalOperation(ctx, journalKey, JournaledOperation{Name: op.Name, Kind: journalKindDetach, Project: project, Zone: instanceZone})
 
 	err = cloud.waitForZonalOp(ctx, project, op.Name, instanceZone)
+	cloud.finishJournaledOperation(ctx, journalKey, op.Name, err)
 	if err != nil {
 		return err
 	}
@@ -1065,39 +1251,83 @@ func (cloud *CloudProvider) getRegionalDiskTypeURI(project string, region, diskT
 
 func (cloud *CloudProvider) waitForZonalOp(ctx context.Context, project, opName string, zone string) error {
 	// The v1 API can query for v1, alpha, or beta operations.
-	return wait.ExponentialBackoff(WaitForOpBackoff, func() (bool, error) {
-		pollOp, err := cloud.service.ZoneOperations.Get(project, zone, opName).Context(ctx).Do()
-		if err != nil {
-			klog.Errorf("WaitForOp(op: %s, zone: %#v) failed to poll the operation", opName, zone)
-			return false, err
-		}
-		done, err := opIsDone(pollOp)
-		return done, err
-	})
+	service := cloud.serviceForProject(project)
+	return waitForOp(ctx, opName, "zonal",
+		func(ctx context.Context) (*computev1.Operation, error) {
+			return service.ZoneOperations.Wait(project, zone, opName).Context(ctx).Do()
+		},
+		func(ctx context.Context) (*computev1.Operation, error) {
+			return service.ZoneOperations.Get(project, zone, opName).Context(ctx).Do()
+		})
 }
 
 func (cloud *CloudProvider) waitForRegionalOp(ctx context.Context, project, opName string, region string) error {
 	// The v1 API can query for v1, alpha, or beta operations.
-	return wait.ExponentialBackoff(WaitForOpBackoff, func() (bool, error) {
-		pollOp, err := cloud.service.RegionOperations.Get(project, region, opName).Context(ctx).Do()
-		if err != nil {
-			klog.Errorf("WaitForOp(op: %s, region: %#v) failed to poll the operation", opName, region)
-			return false, err
-		}
-		done, err := opIsDone(pollOp)
-		return done, err
-	})
+	service := cloud.serviceForProject(project)
+	return waitForOp(ctx, opName, "regional",
+		func(ctx context.Context) (*computev1.Operation, error) {
+			return service.RegionOperations.Wait(project, region, opName).Context(ctx).Do()
+		},
+		func(ctx context.Context) (*computev1.Operation, error) {
+			return service.RegionOperations.Get(project, region, opName).Context(ctx).Do()
+		})
 }
 
 func (cloud *CloudProvider) waitForGlobalOp(ctx context.Context, project, opName string) error {
-	return wait.ExponentialBackoff(WaitForOpBackoff, func() (bool, error) {
-		pollOp, err := cloud.service.GlobalOperations.Get(project, opName).Context(ctx).Do()
+	service := cloud.serviceForProject(project)
+	return waitForOp(ctx, opName, "global",
+		func(ctx context.Context) (*computev1.Operation, error) {
+			return service.GlobalOperations.Wait(project, opName).Context(ctx).Do()
+		},
+		func(ctx context.Context) (*computev1.Operation, error) {
+			return service.GlobalOperations.Get(project, opName).Context(ctx).Do()
+		})
+}
+
+// waitForOp waits for an operation to complete. It long-polls with the
+// operations wait method, which returns when the operation is done or after
+// about two minutes. If wait fails, it falls back to polling with get using
+// WaitForOpBackoff. Both are bounded by the WaitForOpBackoff timeout and ctx.
+func waitForOp(ctx context.Context, opName, scope string, waitOp, getOp func(context.Context) (*computev1.Operation, error)) error {
+	start := time.Now()
+	opType := "unknown"
+	waitMethod := opWaitMethodWait
+	defer func() {
+		operationWaitDurationMetric.WithLabelValues(opType, scope, waitMethod).Observe(time.Since(start).Seconds())
+	}()
+
+	deadline := start.Add(time.Duration(WaitForOpBackoff.Steps) * WaitForOpBackoff.Duration)
+	for time.Now().Before(deadline) {
+		op, err := waitOp(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			klog.Warningf("WaitForOp(op: %s) failed to wait for the %s operation, falling back to polling: %v", opName, scope, err)
+			waitMethod = opWaitMethodPoll
+			break
+		}
+		if op.OperationType != "" {
+			opType = op.OperationType
+		}
+		if done, err := opIsDone(op); done {
+			return err
+		}
+	}
+	if waitMethod == opWaitMethodWait {
+		return fmt.Errorf("timed out waiting for %s operation %s", scope, opName)
+	}
+
+	return wait.ExponentialBackoffWithContext(ctx, WaitForOpBackoff, func(ctx context.Context) (bool, error) {
+		pollOp, err := getOp(ctx)
 		if err != nil {
-			klog.Errorf("waitForGlobalOp(op: %s) failed to poll the operation", opName)
+			klog.Errorf("WaitForOp(op: %s) failed to poll the %s operation", opName, scope)
 			return false, err
 		}
-		done, err := opIsDone(pollOp)
-		return done, err
+		if pollOp.OperationType != "" {
+			opType = pollOp.OperationType
+		}
+		return opIsDone(pollOp)
 	})
 }
 
@@ -1212,11 +1442,8 @@ func opIsDone(op *computev1.Operation) (bool, error) {
 
 func (cloud *CloudProvider) GetInstanceOrError(ctx context.Context, project, instanceZone, instanceName string) (*computev1.Instance, error) {
 	klog.V(5).Infof("Getting instance %v from zone %v", instanceName, instanceZone)
-	service := cloud.service
-	if _, ok := cloud.tenantServiceMap[project]; ok {
-		service = cloud.tenantServiceMap[project]
-	}
-	instance, err := service.Instances.Get(project, instanceZone, instanceName).Do()
+	service := cloud.serviceForProject(project)
+	instance, err := service.Instances.Get(project, instanceZone, instanceName).Context(ctx).Do()
 	if err != nil {
 		return nil, err
 	}
@@ -1278,16 +1505,29 @@ func (cloud *CloudProvider) CreateSnapshot(ctx context.Context, project string,
 		Labels:           snapshotParams.Labels,
 		SourceDisk:       cloud.GetDiskSourceURI(project, volKey),
 	}
-	_, err = cloud.service.Snapshots.Insert(project, snapshotToCreate).Context(ctx).Do()
+	rid := cloud.requestIDs.next("snapshot", project, snapshotName, snapshotToCreate.SourceDisk)
+	op, err := cloud.service.Snapshots.Insert(project, snapshotToCreate).RequestId(rid.id).Context(ctx).Do()
 
 	if err != nil {
 		return nil, err
 	}
+	// The snapshot is waited for rather than the operation, but a retried
+	// call can return the failed operation of a previous call.
+	if _, err := opIsDone(op); err != nil {
+		cloud.requestIDs.done(ctx, rid, err)
+		return nil, err
+	}
 
 	snapshot, err := cloud.waitForSnapshotCreation(ctx, project, snapshotName)
 
 	if err == nil {
-		err = cloud.attachTagsToResource(ctx, snapshotParams.ResourceTags, project, snapshot.Id, snapshotsType, "", false, resourceManagerHostSubPath)
+		err = cloud.bindTags(ctx, TagBindingRequest{
+			Project:      project,
+			ResourceType: SnapshotsType,
+			Name:         snapshot.Name,
+			ID:           snapshot.Id,
+			Tags:         snapshotParams.ResourceTags,
+		})
 	}
 
 	return snapshot, err
@@ -1326,7 +1566,13 @@ func (cloud *CloudProvider) CreateImage(ctx context.Context, project string, vol
 	newImage, err := cloud.waitForImageCreation(ctx, project, imageName)
 
 	if err == nil {
-		err = cloud.attachTagsToResource(ctx, snapshotParams.ResourceTags, project, newImage.Id, imagesType, "", false, resourceManagerHostSubPath)
+		err = cloud.bindTags(ctx, TagBindingRequest{
+			Project:      project,
+			ResourceType: ImagesType,
+			Name:         newImage.Name,
+			ID:           newImage.Id,
+			Tags:         snapshotParams.ResourceTags,
+		})
 	}
 
 	return newImage, err
@@ -1370,16 +1616,23 @@ func (cloud *CloudProvider) GetImage(ctx context.Context, project, imageName str
 
 func (cloud *CloudProvider) ListImages(ctx context.Context, filter string) ([]*computev1.Image, string, error) {
 	klog.V(5).Infof("Listing images with filter: %s", filter)
-	var items []*computev1.Image
-	lCall := cloud.service.Images.List(cloud.project).Context(ctx).Filter(filter)
-	nextPageToken := "pageToken"
-	for nextPageToken != "" {
-		imageList, err := lCall.Do()
-		if err != nil {
-			return nil, "", err
+	items, err := listAcrossProjects(ctx, cloud.projectServices(), func(ctx context.Context, ps projectService) ([]*computev1.Image, error) {
+		var items []*computev1.Image
+		lCall := ps.service.Images.List(ps.project).Context(ctx).Filter(filter)
+		nextPageToken := "pageToken"
+		for nextPageToken != "" {
+			imageList, err := lCall.Do()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, imageList.Items...)
+			nextPageToken = imageList.NextPageToken
+			lCall.PageToken(nextPageToken)
 		}
-		items = append(items, imageList.Items...)
-		nextPageToken = imageList.NextPageToken
+		return items, nil
+	})
+	if err != nil {
+		return nil, "", err
 	}
 	return items, "", nil
 }
@@ -1406,13 +1659,17 @@ func (cloud *CloudProvider) DeleteImage(ctx context.Context, project, imageName
 // k8s.io/apimachinery/quantity package for better size handling
 func (cloud *CloudProvider) ResizeDisk(ctx context.Context, project string, volKey *meta.Key, requestBytes int64) (int64, error) {
 	klog.V(5).Infof("Resizing disk %v to size %v", volKey, requestBytes)
+	requestGb := common.BytesToGbRoundUp(requestBytes)
+	// The size of the disk tells whether an in-flight resize is done.
+	if _, err := cloud.resumeJournaledOperation(ctx, diskJournalKey(project, volKey), journalKindResize, strconv.FormatInt(requestGb, 10)); err != nil {
+		return -1, err
+	}
 	cloudDisk, err := cloud.GetDisk(ctx, project, volKey)
 	if err != nil {
 		return -1, fmt.Errorf("failed to get disk: %w", err)
 	}
 
 	sizeGb := cloudDisk.GetSizeGb()
-	requestGb := common.BytesToGbRoundUp(requestBytes)
 
 	// If disk is already of size equal or greater than requested size, we
 	// simply return the found size
@@ -1441,6 +1698,8 @@ func (cloud *CloudProvider) resizeZonalDisk(ctx context.Context, project string,
 		return -1, err
 	}
 
+	// Both the update and the resize calls use the request ID of the resize.
+	rid := cloud.requestIDs.next("resize", project, volKey.String(), strconv.FormatInt(requestGb, 10))
 	var op *computev1.Operation
 	if common.IsUpdateIopsThroughputValuesAllowed(disk) {
 		// Only Hyperdisks can update iops/throughput
@@ -1461,28 +1720,33 @@ func (cloud *CloudProvider) resizeZonalDisk(ctx context.Context, project string,
 				updatedDisk.ProvisionedThroughput = minThroughputUpdate
 				paths = append(paths, "provisionedThroughput")
 			}
-			op, err = cloud.service.Disks.Update(project, volKey.Zone, volKey.Name, updatedDisk).Context(ctx).Paths(paths...).Do()
+			op, err = cloud.service.Disks.Update(project, volKey.Zone, volKey.Name, updatedDisk).RequestId(rid.id).Context(ctx).Paths(paths...).Do()
 			if err != nil {
 				return -1, fmt.Errorf("failed to resize zonal volume via update %v: %w", volKey.String(), err)
 			}
 		} else {
 			// No updates to iops or throughput for Hyperdisk, using Resize operation
-			op, err = cloud.service.Disks.Resize(project, volKey.Zone, volKey.Name, resizeReq).Context(ctx).Do()
+			op, err = cloud.service.Disks.Resize(project, volKey.Zone, volKey.Name, resizeReq).RequestId(rid.id).Context(ctx).Do()
 			if err != nil {
 				return -1, fmt.Errorf("failed to resize zonal volume %v: %w", volKey.String(), err)
 			}
 		}
 	} else {
 		// Iops and throughput are not applicable to PD disk updates
-		op, err = cloud.service.Disks.Resize(project, volKey.Zone, volKey.Name, resizeReq).Context(ctx).Do()
+		op, err = cloud.service.Disks.Resize(project, volKey.Zone, volKey.Name, resizeReq).RequestId(rid.id).Context(ctx).Do()
 		if err != nil {
 			return -1, fmt.Errorf("failed to resize zonal volume %v: %w", volKey.String(), err)
 		}
 	}
 
-	klog.V(5).Infof("ResizeDisk operation %s for disk %s", op.Name, volKey.Name)
+	klog.V(5).Infof("ResizeDisk operation %s (request %s) for disk %s", op.Name, rid.id, volKey.Name)
+	journalKey := diskJournalKey(project, volKey)
+	cloud.journalOperation(ctx, journalKey, JournaledOperation{Name: op.Name, Kind: journalKindResize, Target: strconv.FormatInt(requestGb, 10), Project: project, Zone: volKey.Zone})
+	operationStarted(ctx)
 
 	err = cloud.waitForZonalOp(ctx, project, op.Name, volKey.Zone)
+	cloud.requestIDs.done(ctx, rid, err)
+	cloud.finishJournaledOperation(ctx, journalKey, op.Name, err)
 	if err != nil {
 		return -1, fmt.Errorf("failed waiting for op for zonal resize for %s: %w", volKey.String(), err)
 	}
@@ -1500,6 +1764,7 @@ func (cloud *CloudProvider) resizeRegionalDisk(ctx context.Context, project stri
 	if err != nil {
 		return -1, err
 	}
+	rid := cloud.requestIDs.next("resize", project, volKey.String(), strconv.FormatInt(requestGb, 10))
 	var op *computev1.Operation
 	if common.IsUpdateIopsThroughputValuesAllowed(disks) {
 		// Only Hyperdisks can update iops/throughput
@@ -1520,27 +1785,32 @@ func (cloud *CloudProvider) resizeRegionalDisk(ctx context.Context, project stri
 				updatedDisk.ProvisionedThroughput = minThroughputUpdate
 				paths = append(paths, "provisionedThroughput")
 			}
-			op, err = cloud.service.RegionDisks.Update(project, volKey.Region, volKey.Name, updatedDisk).Context(ctx).Paths(paths...).Do()
+			op, err = cloud.service.RegionDisks.Update(project, volKey.Region, volKey.Name, updatedDisk).RequestId(rid.id).Context(ctx).Paths(paths...).Do()
 			if err != nil {
 				return -1, fmt.Errorf("failed to resize regional volume via update %v: %w", volKey.String(), err)
 			}
 		} else {
 			// No updates to iops or throughput for Hyperdisk, using Resize operation
-			op, err = cloud.service.RegionDisks.Resize(project, volKey.Region, volKey.Name, resizeReq).Context(ctx).Do()
+			op, err = cloud.service.RegionDisks.Resize(project, volKey.Region, volKey.Name, resizeReq).RequestId(rid.id).Context(ctx).Do()
 			if err != nil {
 				return -1, fmt.Errorf("failed to resize regional volume %v: %w", volKey.String(), err)
 			}
 		}
 	} else {
-		op, err = cloud.service.RegionDisks.Resize(project, volKey.Region, volKey.Name, resizeReq).Context(ctx).Do()
+		op, err = cloud.service.RegionDisks.Resize(project, volKey.Region, volKey.Name, resizeReq).RequestId(rid.id).Context(ctx).Do()
 		if err != nil {
 			return -1, fmt.Errorf("failed to resize regional volume %v: %w", volKey.String(), err)
 		}
 	}
 
-	klog.V(5).Infof("ResizeDisk operation %s for disk %s", op.Name, volKey.Name)
+	klog.V(5).Infof("ResizeDisk operation %s (request %s) for disk %s", op.Name, rid.id, volKey.Name)
+	journalKey := diskJournalKey(project, volKey)
+	cloud.journalOperation(ctx, journalKey, JournaledOperation{Name: op.Name, Kind: journalKindResize, Target: strconv.FormatInt(requestGb, 10), Project: project, Region: volKey.Region})
+	operationStarted(ctx)
 
 	err = cloud.waitForRegionalOp(ctx, project, op.Name, volKey.Region)
+	cloud.requestIDs.done(ctx, rid, err)
+	cloud.finishJournaledOperation(ctx, journalKey, op.Name, err)
 	if err != nil {
 		return -1, fmt.Errorf("failed waiting for op for regional resize for %s: %w", volKey.String(), err)
 	}
@@ -1577,12 +1847,12 @@ func (cloud *CloudProvider) waitForSnapshotCreation(ctx context.Context, project
 
 // getResourceManagerTags returns the map of tag keys and values. The tag keys are in the form `tagKeys/{tag_key_id}`
 // and the tag values are in the format `tagValues/456`.
-func getResourceManagerTags(ctx context.Context, tokenSource oauth2.TokenSource, tagsMap map[string]string) (map[string]string, error) {
+func getResourceManagerTags(ctx context.Context, tokenSource oauth2.TokenSource, endpoints *apiEndpoints, tagsMap map[string]string) (map[string]string, error) {
 	if len(tagsMap) <= 0 {
 		return nil, nil
 	}
 
-	tagValuesClient, err := createTagValuesClient(ctx, tokenSource, resourceManagerHostSubPath)
+	tagValuesClient, err := createTagValuesClient(ctx, tokenSource, endpoints)
 	if err != nil {
 		return nil, err
 	}
@@ -1655,6 +1925,185 @@ func getFilteredTagsMap(ctx context.Context, client *rscmgr.TagBindingsClient, p
 	return filteredTagsMap
 }
 
+// bindTags binds the requested tags to a resource. If the tag reconciler is
+// enabled, the resource is queued and the tags are bound asynchronously.
+func (cloud *CloudProvider) bindTags(ctx context.Context, req TagBindingRequest) error {
+	if cloud.TagReconciler != nil {
+		cloud.TagReconciler.Enqueue(req)
+		return nil
+	}
+	if req.ID == 0 {
+		id, err := cloud.getTagBindingResourceID(ctx, &req)
+		if err != nil {
+			return err
+		}
+		req.ID = id
+	}
+	return cloud.attachTagsToResource(ctx, req.Tags, req.Project, req.ID, req.ResourceType, req.Location, req.IsZonal)
+}
+
+// BindTags binds the requested tags to an existing resource, asynchronously
+// if the tag reconciler is enabled.
+func (cloud *CloudProvider) BindTags(ctx context.Context, req TagBindingRequest) error {
+	return cloud.bindTags(ctx, req)
+}
+
+// GetEffectiveTags returns the tags bound to a resource, as a map of
+// namespaced tag keys to namespaced tag values.
+func (cloud *CloudProvider) GetEffectiveTags(ctx context.Context, req TagBindingRequest) (map[string]string, error) {
+	if req.ID == 0 {
+		id, err := cloud.getTagBindingResourceID(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		req.ID = id
+	}
+	binder := &resourceManagerTagBinder{clients: cloud.tagBindingsClients}
+	return binder.effectiveTags(ctx, req.Location, tagBindingParent(req.Project, req.ResourceType, req.Location, req.IsZonal, req.ID))
+}
+
+// SetDiskLabels replaces the labels of a disk. labelFingerprint is the
+// fingerprint of the labels the new labels are based on.
+func (cloud *CloudProvider) SetDiskLabels(ctx context.Context, project string, volKey *meta.Key, labels map[string]string, labelFingerprint string) error {
+	klog.V(5).Infof("Setting labels of disk %v to %v", volKey, labels)
+	service := cloud.serviceForProject(project)
+	switch volKey.Type() {
+	case meta.Zonal:
+		op, err := service.Disks.SetLabels(project, volKey.Zone, volKey.Name, &computev1.ZoneSetLabelsRequest{
+			Labels:           labels,
+			LabelFingerprint: labelFingerprint,
+		}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		return cloud.waitForZonalOp(ctx, project, op.Name, volKey.Zone)
+	case meta.Regional:
+		op, err := service.RegionDisks.SetLabels(project, volKey.Region, volKey.Name, &computev1.RegionSetLabelsRequest{
+			Labels:           labels,
+			LabelFingerprint: labelFingerprint,
+		}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		return cloud.waitForRegionalOp(ctx, project, op.Name, volKey.Region)
+	default:
+		return fmt.Errorf("could not set labels, key was neither zonal nor regional, instead got: %v", volKey.String())
+	}
+}
+
+// SetSnapshotLabels replaces the labels of a snapshot.
+func (cloud *CloudProvider) SetSnapshotLabels(ctx context.Context, project, snapshotName string, labels map[string]string, labelFingerprint string) error {
+	klog.V(5).Infof("Setting labels of snapshot %s to %v", snapshotName, labels)
+	op, err := cloud.serviceForProject(project).Snapshots.SetLabels(project, snapshotName, &computev1.GlobalSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: labelFingerprint,
+	}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	return cloud.waitForGlobalOp(ctx, project, op.Name)
+}
+
+// SetImageLabels replaces the labels of an image.
+func (cloud *CloudProvider) SetImageLabels(ctx context.Context, project, imageName string, labels map[string]string, labelFingerprint string) error {
+	klog.V(5).Infof("Setting labels of image %s to %v", imageName, labels)
+	op, err := cloud.serviceForProject(project).Images.SetLabels(project, imageName, &computev1.GlobalSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: labelFingerprint,
+	}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	return cloud.waitForGlobalOp(ctx, project, op.Name)
+}
+
+// getTagBindingResourceID returns the numeric ID of the resource of a tag
+// binding request.
+func (cloud *CloudProvider) getTagBindingResourceID(ctx context.Context, req *TagBindingRequest) (uint64, error) {
+	service := cloud.serviceForProject(req.Project)
+	switch req.ResourceType {
+	case DisksType:
+		if req.IsZonal {
+			disk, err := service.Disks.Get(req.Project, req.Location, req.Name).Context(ctx).Do()
+			if err != nil {
+				return 0, err
+			}
+			return disk.Id, nil
+		}
+		disk, err := service.RegionDisks.Get(req.Project, req.Location, req.Name).Context(ctx).Do()
+		if err != nil {
+			return 0, err
+		}
+		return disk.Id, nil
+	case SnapshotsType:
+		snapshot, err := service.Snapshots.Get(req.Project, req.Name).Context(ctx).Do()
+		if err != nil {
+			return 0, err
+		}
+		return snapshot.Id, nil
+	case ImagesType:
+		image, err := service.Images.Get(req.Project, req.Name).Context(ctx).Do()
+		if err != nil {
+			return 0, err
+		}
+		return image.Id, nil
+	default:
+		return 0, fmt.Errorf("unsupported resource type %q", req.ResourceType)
+	}
+}
+
+var (
+	// Fields required to find the disks created by the driver for tag
+	// reconciliation.
+	tagReconcileDiskFields = []googleapi.Field{
+		"items/description",
+		"items/id",
+		"items/selfLink",
+		"nextPageToken",
+	}
+)
+
+// listCreatedTagBindingRequests returns requests without tags for the disks,
+// snapshots and images created by driverName, as marked in their
+// description.
+func (cloud *CloudProvider) listCreatedTagBindingRequests(ctx context.Context, driverName string) ([]TagBindingRequest, error) {
+	var reqs []TagBindingRequest
+	add := func(resourceType ResourceType, description, selfLink string, id uint64) {
+		if !common.IsCreatedByDriver(description, driverName) {
+			return
+		}
+		req, err := tagBindingRequestForSelfLink(resourceType, selfLink, id)
+		if err != nil {
+			klog.Warningf("Skipping tag reconciliation of %s: %v", selfLink, err)
+			return
+		}
+		reqs = append(reqs, req)
+	}
+
+	disks, _, err := cloud.ListDisks(ctx, tagReconcileDiskFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disks: %w", err)
+	}
+	for _, disk := range disks {
+		add(DisksType, disk.Description, disk.SelfLink, disk.Id)
+	}
+	snapshots, _, err := cloud.ListSnapshots(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	for _, snapshot := range snapshots {
+		add(SnapshotsType, snapshot.Description, snapshot.SelfLink, snapshot.Id)
+	}
+	images, _, err := cloud.ListImages(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	for _, image := range images {
+		add(ImagesType, image.Description, image.SelfLink, image.Id)
+	}
+	return reqs, nil
+}
+
 // attachTagsToResource attaches tags to a compute resource. As GCP has a rate limit of 600
 // requests per minute, the tags list is filtered so that only the tags which are not attached
 // to the resource are attached. The calls to create the tag binding resource for the tags are
@@ -1666,28 +2115,17 @@ func (cloud *CloudProvider) attachTagsToResource(
 	resourceID uint64,
 	resourceType ResourceType,
 	location string,
-	isZonal bool,
-	resourceManagerHostSubPath string) error {
+	isZonal bool) error {
 	if len(tagsMap) <= 0 {
 		return nil
 	}
 
-	tagBindingsClient, err := createTagBindingsClient(ctx, cloud.tokenSource, location, resourceManagerHostSubPath)
-	if err != nil || tagBindingsClient == nil {
+	tagBindingsClient, err := cloud.tagBindingsClients.get(location)
+	if err != nil {
 		return fmt.Errorf("failed to create tag binding client for adding tags to %d compute %s: %w", resourceID, resourceType, err)
 	}
-	defer tagBindingsClient.Close()
 
-	var fullResourceID string
-	if location != "" {
-		if isZonal {
-			fullResourceID = fmt.Sprintf(zonalOrRegionalComputeParentPathFmt, project, "zones", location, resourceType, resourceID)
-		} else {
-			fullResourceID = fmt.Sprintf(zonalOrRegionalComputeParentPathFmt, project, "regions", location, resourceType, resourceID)
-		}
-	} else {
-		fullResourceID = fmt.Sprintf(globalComputeParentPathFmt, project, resourceType, resourceID)
-	}
+	fullResourceID := tagBindingParent(project, resourceType, location, isZonal, resourceID)
 
 	filteredTagsMap := getFilteredTagsMap(ctx, tagBindingsClient, fullResourceID, tagsMap)
 
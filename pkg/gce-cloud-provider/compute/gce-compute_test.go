@@ -16,11 +16,20 @@ package gcecloudprovider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
 	computebeta "google.golang.org/api/compute/v0.beta"
 	computev1 "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
 )
 
@@ -329,3 +338,213 @@ func TestCodeForGCEOpError(t *testing.T) {
 		}
 	}
 }
+
+func TestListAcrossProjects(t *testing.T) {
+	projects := []projectService{}
+	for i := 0; i < 3*maxConcurrentProjectLists; i++ {
+		projects = append(projects, projectService{project: fmt.Sprintf("project-%d", i)})
+	}
+	testCases := []struct {
+		name      string
+		failOn    string
+		wantItems int
+		wantErr   bool
+	}{
+		{
+			name:      "results are merged in project order",
+			wantItems: len(projects),
+		},
+		{
+			name:    "error in one project fails the list",
+			failOn:  "project-7",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := listAcrossProjects(context.Background(), projects, func(ctx context.Context, ps projectService) ([]string, error) {
+				if ps.project == tc.failOn {
+					return nil, errors.New("injected error")
+				}
+				return []string{ps.project}, nil
+			})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("listAcrossProjects() got error %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(items) != tc.wantItems {
+				t.Fatalf("listAcrossProjects() got %d items, want %d", len(items), tc.wantItems)
+			}
+			for i, item := range items {
+				if item != projects[i].project {
+					t.Errorf("listAcrossProjects() item %d = %q, want %q", i, item, projects[i].project)
+				}
+			}
+		})
+	}
+}
+
+func TestListSnapshotsAcrossTenantProjects(t *testing.T) {
+	// Each project has two pages of snapshots.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var project string
+		if _, err := fmt.Sscanf(r.URL.Path, "/projects/%s", &project); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		project = project[:len(project)-len("/global/snapshots")]
+		list := &computev1.SnapshotList{}
+		if r.URL.Query().Get("pageToken") == "" {
+			list.Items = []*computev1.Snapshot{{Name: project + "-snapshot-1"}}
+			list.NextPageToken = "page-2"
+		} else {
+			list.Items = []*computev1.Snapshot{{Name: project + "-snapshot-2"}}
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer srv.Close()
+
+	newService := func() *computev1.Service {
+		svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+		if err != nil {
+			t.Fatalf("Failed to create compute service: %v", err)
+		}
+		return svc
+	}
+	cloud := &CloudProvider{
+		service: newService(),
+		project: "default-project",
+		tenantServiceMap: map[string]*computev1.Service{
+			"222": newService(),
+			"111": newService(),
+		},
+	}
+
+	snapshots, _, err := cloud.ListSnapshots(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListSnapshots() failed: %v", err)
+	}
+	got := []string{}
+	for _, s := range snapshots {
+		got = append(got, s.Name)
+	}
+	want := []string{
+		"default-project-snapshot-1", "default-project-snapshot-2",
+		"111-snapshot-1", "111-snapshot-2",
+		"222-snapshot-1", "222-snapshot-2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSnapshots() got %v, want %v", got, want)
+	}
+}
+
+func TestWaitForZonalOp(t *testing.T) {
+	oldBackoff := WaitForOpBackoff
+	WaitForOpBackoff = wait.Backoff{Duration: 10 * time.Millisecond, Steps: 100}
+	defer func() { WaitForOpBackoff = oldBackoff }()
+
+	testCases := []struct {
+		name           string
+		waitStatus     int
+		pollsUntilDone int
+		opError        bool
+		wantWaitMethod string
+		wantWaitCalls  int
+		wantGetCalls   int
+		wantErr        bool
+	}{
+		{
+			name:           "wait returns once the operation is done",
+			waitStatus:     http.StatusOK,
+			pollsUntilDone: 3,
+			wantWaitMethod: opWaitMethodWait,
+			wantWaitCalls:  3,
+		},
+		{
+			name:           "operation error is returned",
+			waitStatus:     http.StatusOK,
+			pollsUntilDone: 1,
+			opError:        true,
+			wantWaitMethod: opWaitMethodWait,
+			wantWaitCalls:  1,
+			wantErr:        true,
+		},
+		{
+			name:           "falls back to polling when wait fails",
+			waitStatus:     http.StatusNotImplemented,
+			pollsUntilDone: 2,
+			wantWaitMethod: opWaitMethodPoll,
+			wantWaitCalls:  1,
+			wantGetCalls:   2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var waitCalls, getCalls, polls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/projects/project/zones/zone/operations/op/wait" {
+					waitCalls++
+					if tc.waitStatus != http.StatusOK {
+						http.Error(w, "wait failed", tc.waitStatus)
+						return
+					}
+				} else if r.URL.Path == "/projects/project/zones/zone/operations/op" {
+					getCalls++
+				} else {
+					http.Error(w, "unexpected path "+r.URL.Path, http.StatusNotFound)
+					return
+				}
+				polls++
+				op := &computev1.Operation{Name: "op", OperationType: "attachDisk", Status: "RUNNING"}
+				if polls >= tc.pollsUntilDone {
+					op.Status = operationStatusDone
+					if tc.opError {
+						op.Error = &computev1.OperationError{Errors: []*computev1.OperationErrorErrors{{Code: "RESOURCE_NOT_READY", Message: "not ready"}}}
+					}
+				}
+				json.NewEncoder(w).Encode(op)
+			}))
+			defer srv.Close()
+
+			svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+			if err != nil {
+				t.Fatalf("Failed to create compute service: %v", err)
+			}
+			cloud := &CloudProvider{service: svc}
+
+			labels := map[string]string{"operation_type": "attachDisk", "scope": "zonal", "wait_method": tc.wantWaitMethod}
+			before := gatherMetric(t, "csidriver_operation_wait_duration_seconds", labels).GetHistogram().GetSampleCount()
+			err = cloud.waitForZonalOp(context.Background(), "project", "op", "zone")
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("waitForZonalOp() got error %v, want error %v", err, tc.wantErr)
+			}
+			if waitCalls != tc.wantWaitCalls || getCalls != tc.wantGetCalls {
+				t.Errorf("waitForZonalOp() made %d wait and %d get calls, want %d and %d", waitCalls, getCalls, tc.wantWaitCalls, tc.wantGetCalls)
+			}
+			after := gatherMetric(t, "csidriver_operation_wait_duration_seconds", labels).GetHistogram().GetSampleCount()
+			if after-before != 1 {
+				t.Errorf("operation_wait_duration_seconds recorded %d samples, want 1", after-before)
+			}
+		})
+	}
+}
+
+func TestWaitForZonalOpHonorsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&computev1.Operation{Name: "op", Status: "RUNNING"})
+	}))
+	defer srv.Close()
+	svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("Failed to create compute service: %v", err)
+	}
+	cloud := &CloudProvider{service: svc}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := cloud.waitForZonalOp(ctx, "project", "op", "zone"); err == nil {
+		t.Errorf("waitForZonalOp() succeeded, want context error")
+	}
+}
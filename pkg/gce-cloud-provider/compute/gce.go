@@ -25,13 +25,14 @@ import (
 	"sync"
 	"time"
 
-	"golang.org/x/oauth2/google"
 	"golang.org/x/time/rate"
 	"google.golang.org/api/option"
 	"gopkg.in/gcfg.v1"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
 
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/auth/oauth2adapt"
 	"cloud.google.com/go/compute/metadata"
 	rscmgr "cloud.google.com/go/resourcemanager/apiv3"
 	"golang.org/x/oauth2"
@@ -59,9 +60,6 @@ const (
 	EnvironmentStaging               Environment = "staging"
 	EnvironmentProduction            Environment = "production"
 
-	// resourceManagerHostSubPath is the endpoint for tag requests.
-	resourceManagerHostSubPath = "cloudresourcemanager.googleapis.com"
-
 	// zonalOrRegionalComputeParentPathFmt is the string format for the full path of compute resource.
 	// belonging to a zone or a region
 	zonalOrRegionalComputeParentPathFmt = "//compute.googleapis.com/projects/%s/%s/%s/%s/%d"
@@ -81,10 +79,12 @@ const (
 type ResourceType string
 
 var (
-	// snapshotsType is the resource type of compute snapshots.
-	snapshotsType ResourceType = "snapshots"
-	// imagesType is the resource type of compute images.
-	imagesType         ResourceType = "images"
+	// DisksType is the resource type of compute disks.
+	DisksType ResourceType = "disks"
+	// SnapshotsType is the resource type of compute snapshots.
+	SnapshotsType ResourceType = "snapshots"
+	// ImagesType is the resource type of compute images.
+	ImagesType         ResourceType = "images"
 	tenantServiceMutex sync.Mutex
 )
 
@@ -95,6 +95,7 @@ type CloudProvider struct {
 	service     *compute.Service
 	betaService *computebeta.Service
 	tokenSource oauth2.TokenSource
+	endpoints   *apiEndpoints
 	project     string
 	zone        string
 
@@ -102,13 +103,27 @@ type CloudProvider struct {
 
 	waitForAttachConfig WaitForAttachConfig
 
-	tagsRateLimiter *rate.Limiter
+	tagsRateLimiter    *rate.Limiter
+	tagBindingsClients *tagBindingsClientPool
+	// TagReconciler binds resource manager tags asynchronously. It is nil if
+	// tags are bound synchronously.
+	TagReconciler *TagReconciler
+	// OperationJournal records the in-flight operations, to resume them after
+	// a restart. Operations are not recorded if it is nil.
+	OperationJournal OperationJournal
 
 	listInstancesConfig ListInstancesConfig
 
 	TenantInformer tenancy.TenantsInformer
 	// tenantServiceMap maintains Compute Services for the default project as well as any tenant projects for any tenant-aware GCE operations
 	tenantServiceMap map[string]*compute.Service
+	// tenantLimits maintains the persistent disk guardrails of each tenant,
+	// keyed by tenant project number.
+	tenantLimits      map[string]tenancy.Limits
+	tenantLimitsMutex sync.RWMutex
+
+	// requestIDs derives the request IDs of mutating calls.
+	requestIDs requestIDGenerator
 
 	enableHdHA bool
 }
@@ -139,9 +154,32 @@ type ConfigGlobal struct {
 	TokenBody string `gcfg:"token-body"`
 	ProjectId string `gcfg:"project-id"`
 	Zone      string `gcfg:"zone"`
+	// CredentialsFile is the path to a Google credentials JSON file, e.g. an
+	// external_account configuration for workload identity federation. It is
+	// ignored if TokenURL is set.
+	CredentialsFile string `gcfg:"credentials-file"`
+	// ImpersonateServiceAccount is the email of a service account to
+	// impersonate with the credentials from CredentialsFile or the default
+	// credentials. It is ignored if TokenURL is set.
+	ImpersonateServiceAccount string `gcfg:"impersonate-service-account"`
+	// ImpersonateDelegates are the emails of the service accounts in the
+	// delegation chain to ImpersonateServiceAccount, if any.
+	ImpersonateDelegates []string `gcfg:"impersonate-delegate"`
+	// UniverseDomain is the universe the Google Cloud APIs are served from,
+	// e.g. for sovereign clouds. Defaults to googleapis.com.
+	UniverseDomain string `gcfg:"universe-domain"`
+	// ComputeEndpoint overrides the compute endpoint, e.g. with a Private
+	// Service Connect endpoint. The --compute-endpoint flag takes precedence.
+	ComputeEndpoint string `gcfg:"compute-endpoint"`
+	// ResourceManagerEndpoint overrides the resource manager endpoint used for
+	// tags. Location specific requests are sent to {location}-{host}.
+	ResourceManagerEndpoint string `gcfg:"resource-manager-endpoint"`
+	// IAMCredentialsEndpoint overrides the endpoint used to mint tokens for
+	// ImpersonateServiceAccount.
+	IAMCredentialsEndpoint string `gcfg:"iam-credentials-endpoint"`
 }
 
-func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath string, computeEndpoint *url.URL, computeEnvironment Environment, waitForAttachConfig WaitForAttachConfig, listInstancesConfig ListInstancesConfig, multiTenancyEnabled bool) (*CloudProvider, error) {
+func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath string, computeEndpoint *url.URL, computeEnvironment Environment, waitForAttachConfig WaitForAttachConfig, listInstancesConfig ListInstancesConfig, tagReconcilerConfig TagReconcilerConfig, apiBudgetConfig APIBudgetConfig, retryConfig RetryConfig, multiTenancyEnabled bool) (*CloudProvider, error) {
 	configFile, err := readConfig(configPath)
 	if err != nil {
 		return nil, err
@@ -151,18 +189,28 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 
 	klog.V(2).Infof("Using GCE provider config %+v", configFile)
 
-	tokenSource, err := generateTokenSource(ctx, configFile)
+	endpoints, err := newAPIEndpoints(configFile, computeEndpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	svc, err := createCloudService(ctx, vendorVersion, tokenSource, computeEndpoint, computeEnvironment)
+	tokenSource, err := generateTokenSource(ctx, configFile, endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &computeCallPolicy{
+		budget:  newAPIBudget(apiBudgetConfig),
+		retrier: newAPIRetrier(retryConfig),
+	}
+
+	svc, err := createCloudService(ctx, vendorVersion, tokenSource, endpoints, policy, computeEnvironment)
 	if err != nil {
 		return nil, err
 	}
 	klog.Infof("Compute endpoint for V1 version: %s", svc.BasePath)
 
-	betasvc, err := createBetaCloudService(ctx, vendorVersion, tokenSource, computeEndpoint, computeEnvironment)
+	betasvc, err := createBetaCloudService(ctx, vendorVersion, tokenSource, endpoints, policy, computeEnvironment)
 	if err != nil {
 		return nil, err
 	}
@@ -177,6 +225,7 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 		service:             svc,
 		betaService:         betasvc,
 		tokenSource:         tokenSource,
+		endpoints:           endpoints,
 		project:             project,
 		zone:                zone,
 		zonesCache:          make(map[string]([]string)),
@@ -186,6 +235,16 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 		// here to 8 requests per second.
 		tagsRateLimiter:  common.NewLimiter(gcpTagsRequestRateLimit, gcpTagsRequestTokenBucketSize, true),
 		tenantServiceMap: make(map[string]*compute.Service),
+		tenantLimits:     make(map[string]tenancy.Limits),
+		OperationJournal: NewMemoryOperationJournal(),
+	}
+	cp.tagBindingsClients = newTagBindingsClientPool(tokenSource, endpoints)
+
+	if tagReconcilerConfig.Enabled {
+		cp.TagReconciler, err = newTagReconciler(&resourceManagerTagBinder{clients: cp.tagBindingsClients}, cp.tagsRateLimiter, cp.getTagBindingResourceID, cp.listCreatedTagBindingRequests, tagReconcilerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed initializing tag reconciler: %w", err)
+		}
 	}
 
 	if multiTenancyEnabled {
@@ -197,6 +256,7 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 		cp.TenantInformer = ti
 		addTenantCallback := func(tenantMeta *tenancy.Metadata, projectZone string) (*computev1.Service, error) {
 			klog.Infof("Executing AddFunc callback for tenant: %s (Project: %s)", tenantMeta.TenantName, tenantMeta.ProjectNumber)
+			cp.setTenantLimits(tenantMeta)
 
 			region, err := common.GetRegionFromZones([]string{zone})
 			if err != nil {
@@ -210,7 +270,7 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 				return nil, fmt.Errorf("error during tenant token source generation: %w", err)
 			}
 
-			tenantComputeService, err := createCloudService(ctx, vendorVersion, tenantTokenSource, computeEndpoint, computeEnvironment)
+			tenantComputeService, err := createCloudService(ctx, vendorVersion, tenantTokenSource, endpoints, policy, computeEnvironment)
 			if err != nil {
 				klog.Errorf("Error while creating compute service with tenant identity for %s: %v", tenantMeta.TenantName, err)
 				return nil, fmt.Errorf("error while creating compute service with tenant identity: %w", err)
@@ -220,7 +280,9 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 		}
 
 		lifecycleHandler := tenancy.TenantLifecycleHandler{
-			AddFunc: addTenantCallback,
+			AddFunc:    addTenantCallback,
+			UpdateFunc: cp.setTenantLimits,
+			DeleteFunc: cp.deleteTenantLimits,
 		}
 
 		err = tenancy.RegisterTenantEventHandlers(
@@ -238,32 +300,57 @@ func CreateCloudProvider(ctx context.Context, vendorVersion string, configPath s
 	return cp, nil
 }
 
-func generateTokenSource(ctx context.Context, configFile *ConfigFile) (oauth2.TokenSource, error) {
+func generateTokenSource(ctx context.Context, configFile *ConfigFile, endpoints *apiEndpoints) (oauth2.TokenSource, error) {
 	if configFile != nil && configFile.Global.TokenURL != "" && configFile.Global.TokenURL != "nil" {
 		// configFile.Global.TokenURL is defined
 		// Use AltTokenSource
 
-		tokenSource := NewAltTokenSource(configFile.Global.TokenURL, configFile.Global.TokenBody)
+		tokenSource := newAltTokenSource(configFile.Global.TokenURL, configFile.Global.TokenBody)
 		klog.V(2).Infof("Using AltTokenSource %#v", tokenSource)
-		return tokenSource, nil
+		return newProactiveTokenSource(tokenSource, tokenSourceAlt), nil
 	}
 
-	// Use DefaultTokenSource
-
-	tokenSource, err := google.DefaultTokenSource(
-		ctx,
-		compute.CloudPlatformScope,
-		compute.ComputeScope)
-
-	// DefaultTokenSource relies on GOOGLE_APPLICATION_CREDENTIALS env var being set.
-	if gac, ok := os.LookupEnv("GOOGLE_APPLICATION_CREDENTIALS"); ok {
+	// The credentials cache tokens themselves, so they are configured to
+	// consider tokens stale before the proactiveTokenSource refreshes them.
+	opts := &credentials.DetectOptions{
+		Scopes:              []string{compute.CloudPlatformScope, compute.ComputeScope},
+		EarlyTokenRefresh:   maxTokenRefreshMargin,
+		DisableAsyncRefresh: true,
+	}
+	name := tokenSourceDefault
+	if configFile != nil && configFile.Global.CredentialsFile != "" {
+		// Use the credentials file, which may be an external_account
+		// configuration for workload identity federation.
+		data, err := os.ReadFile(configFile.Global.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file %s: %w", configFile.Global.CredentialsFile, err)
+		}
+		opts.CredentialsJSON = data
+		opts.UniverseDomain = endpoints.universeDomain
+		name = tokenSourceCredentialsFile
+	} else if gac, ok := os.LookupEnv("GOOGLE_APPLICATION_CREDENTIALS"); ok {
+		// Default credentials rely on GOOGLE_APPLICATION_CREDENTIALS env var being set.
 		klog.V(2).Infof("GOOGLE_APPLICATION_CREDENTIALS env var set %v", gac)
 	} else {
 		klog.Warningf("GOOGLE_APPLICATION_CREDENTIALS env var not set")
 	}
-	klog.V(2).Infof("Using DefaultTokenSource %#v", tokenSource)
+	creds, err := credentials.DetectDefault(opts)
+	if err != nil {
+		if name == tokenSourceCredentialsFile {
+			return nil, fmt.Errorf("failed to load credentials from %s: %w", configFile.Global.CredentialsFile, err)
+		}
+		return nil, err
+	}
+	tokenSource := oauth2adapt.TokenSourceFromTokenProvider(creds)
+	klog.V(2).Infof("Using %s credentials", name)
 
-	return tokenSource, err
+	if configFile != nil && configFile.Global.ImpersonateServiceAccount != "" {
+		klog.V(2).Infof("Impersonating service account %s (delegates: %v)", configFile.Global.ImpersonateServiceAccount, configFile.Global.ImpersonateDelegates)
+		tokenSource = NewImpersonatedTokenSource(ctx, tokenSource, endpoints.iamCredentials, configFile.Global.ImpersonateServiceAccount, configFile.Global.ImpersonateDelegates, compute.CloudPlatformScope, compute.ComputeScope)
+		name = tokenSourceImpersonated
+	}
+
+	return newProactiveTokenSource(tokenSource, name), nil
 }
 
 func readConfig(configPath string) (*ConfigFile, error) {
@@ -284,8 +371,8 @@ func readConfig(configPath string) (*ConfigFile, error) {
 	return cfg, nil
 }
 
-func createBetaCloudService(ctx context.Context, vendorVersion string, tokenSource oauth2.TokenSource, computeEndpoint *url.URL, computeEnvironment Environment) (*computebeta.Service, error) {
-	computeOpts, err := getComputeVersion(ctx, tokenSource, computeEndpoint, computeEnvironment, GCEAPIVersionBeta)
+func createBetaCloudService(ctx context.Context, vendorVersion string, tokenSource oauth2.TokenSource, endpoints *apiEndpoints, policy *computeCallPolicy, computeEnvironment Environment) (*computebeta.Service, error) {
+	computeOpts, err := getComputeVersion(ctx, tokenSource, endpoints.compute, endpoints.universeDomain, policy, computeEnvironment, GCEAPIVersionBeta)
 	if err != nil {
 		klog.Errorf("Failed to get compute endpoint: %s", err)
 	}
@@ -297,8 +384,8 @@ func createBetaCloudService(ctx context.Context, vendorVersion string, tokenSour
 	return service, nil
 }
 
-func createCloudService(ctx context.Context, vendorVersion string, tokenSource oauth2.TokenSource, computeEndpoint *url.URL, computeEnvironment Environment) (*compute.Service, error) {
-	computeOpts, err := getComputeVersion(ctx, tokenSource, computeEndpoint, computeEnvironment, GCEAPIVersionV1)
+func createCloudService(ctx context.Context, vendorVersion string, tokenSource oauth2.TokenSource, endpoints *apiEndpoints, policy *computeCallPolicy, computeEnvironment Environment) (*compute.Service, error) {
+	computeOpts, err := getComputeVersion(ctx, tokenSource, endpoints.compute, endpoints.universeDomain, policy, computeEnvironment, GCEAPIVersionV1)
 	if err != nil {
 		klog.Errorf("Failed to get compute endpoint: %s", err)
 	}
@@ -310,22 +397,48 @@ func createCloudService(ctx context.Context, vendorVersion string, tokenSource o
 	return service, nil
 }
 
-func getComputeVersion(ctx context.Context, tokenSource oauth2.TokenSource, computeEndpoint *url.URL, computeEnvironment Environment, computeVersion GCEAPIVersion) ([]option.ClientOption, error) {
+func getComputeVersion(ctx context.Context, tokenSource oauth2.TokenSource, computeEndpoint *url.URL, universeDomain string, policy *computeCallPolicy, computeEnvironment Environment, computeVersion GCEAPIVersion) ([]option.ClientOption, error) {
 	client, err := newOauthClient(ctx, tokenSource)
 	if err != nil {
 		return nil, err
 	}
+	client.Transport = policy.wrap(client.Transport)
 	computeOpts := []option.ClientOption{option.WithHTTPClient(client)}
 
+	if universeDomain != "" && universeDomain != DefaultUniverseDomain {
+		computeOpts = append(computeOpts, option.WithUniverseDomain(universeDomain))
+	}
 	if computeEndpoint != nil {
-		computeEnvironmentSuffix := constructComputeEndpointPath(computeEnvironment, computeVersion)
-		computeEndpoint.Path = computeEnvironmentSuffix
-		endpoint := computeEndpoint.String()
-		computeOpts = append(computeOpts, option.WithEndpoint(endpoint))
+		// Copy the endpoint, it is shared by the v1, beta and tenant services.
+		endpointURL := *computeEndpoint
+		endpointURL.Path = constructComputeEndpointPath(computeEnvironment, computeVersion)
+		computeOpts = append(computeOpts, option.WithEndpoint(endpointURL.String()))
 	}
 	return computeOpts, nil
 }
 
+// computeCallPolicy is the client-side policy applied to the compute API
+// calls: the API budget and the retries, either of which may be nil.
+type computeCallPolicy struct {
+	budget  *apiBudget
+	retrier *apiRetrier
+}
+
+// wrap returns base wrapped with the policy. Each retry of a call waits for
+// the API budget again.
+func (p *computeCallPolicy) wrap(base http.RoundTripper) http.RoundTripper {
+	if p == nil {
+		return base
+	}
+	if p.budget != nil {
+		base = &budgetedTransport{budget: p.budget, base: base}
+	}
+	if p.retrier != nil {
+		base = &retryTransport{retrier: p.retrier, base: base}
+	}
+	return base
+}
+
 func constructComputeEndpointPath(env Environment, version GCEAPIVersion) string {
 	prefix := ""
 	if env == EnvironmentStaging {
@@ -334,35 +447,28 @@ func constructComputeEndpointPath(env Environment, version GCEAPIVersion) string
 	return fmt.Sprintf("compute/%s%s/", prefix, version)
 }
 
-func createTagValuesClient(ctx context.Context, tokenSource oauth2.TokenSource, resourceManagerHostSubPath string) (*rscmgr.TagValuesClient, error) {
+func createTagValuesClient(ctx context.Context, tokenSource oauth2.TokenSource, endpoints *apiEndpoints) (*rscmgr.TagValuesClient, error) {
 	client, err := newOauthClient(ctx, tokenSource)
 	if err != nil {
 		return nil, err
 	}
 
-	endpoint := fmt.Sprintf("https://%s", resourceManagerHostSubPath)
 	opts := []option.ClientOption{
 		option.WithHTTPClient(client),
-		option.WithEndpoint(endpoint),
+		option.WithEndpoint(endpoints.resourceManagerEndpoint("")),
 	}
 	return rscmgr.NewTagValuesRESTClient(ctx, opts...)
 }
 
-func createTagBindingsClient(ctx context.Context, tokenSource oauth2.TokenSource, location string, resourceManagerHostSubPath string) (*rscmgr.TagBindingsClient, error) {
+func createTagBindingsClient(ctx context.Context, tokenSource oauth2.TokenSource, location string, endpoints *apiEndpoints) (*rscmgr.TagBindingsClient, error) {
 	client, err := newOauthClient(ctx, tokenSource)
 	if err != nil {
 		return nil, err
 	}
 
-	var endpoint string
-	if location != "" {
-		endpoint = fmt.Sprintf("https://%s-%s", location, resourceManagerHostSubPath)
-	} else {
-		endpoint = fmt.Sprintf("https://%s", resourceManagerHostSubPath)
-	}
 	opts := []option.ClientOption{
 		option.WithHTTPClient(client),
-		option.WithEndpoint(endpoint),
+		option.WithEndpoint(endpoints.resourceManagerEndpoint(location)),
 	}
 	return rscmgr.NewTagBindingsRESTClient(ctx, opts...)
 }
@@ -378,7 +484,11 @@ func newOauthClient(ctx context.Context, tokenSource oauth2.TokenSource) (*http.
 		return nil, err
 	}
 
-	return oauth2.NewClient(ctx, tokenSource), nil
+	// oauth2.NewClient would wrap tokenSource in another cache, which only
+	// refreshes tokens shortly before they expire.
+	return &http.Client{
+		Transport: &oauth2.Transport{Source: tokenSource},
+	}, nil
 }
 
 func getProjectAndZone(config *ConfigFile) (string, string, error) {
@@ -105,6 +105,7 @@ func TestGetComputeVersion(t *testing.T) {
 	testCases := []struct {
 		name               string
 		computeEndpoint    *url.URL
+		universeDomain     string
 		computeEnvironment Environment
 		computeVersion     GCEAPIVersion
 		expectedEndpoint   string
@@ -127,6 +128,23 @@ func TestGetComputeVersion(t *testing.T) {
 			expectedEndpoint:   "https://compute.googleapis.com/compute/staging_v1/",
 			expectError:        false,
 		},
+		{
+			name:               "check for universe domain",
+			universeDomain:     "example.com",
+			computeEnvironment: EnvironmentProduction,
+			computeVersion:     GCEAPIVersionV1,
+			expectedEndpoint:   "https://compute.example.com/compute/v1/",
+			expectError:        false,
+		},
+		{
+			name:               "check for endpoint with universe domain",
+			computeEndpoint:    convertStringToURL("https://compute-psc.p.example.com"),
+			universeDomain:     "example.com",
+			computeEnvironment: EnvironmentProduction,
+			computeVersion:     GCEAPIVersionBeta,
+			expectedEndpoint:   "https://compute-psc.p.example.com/compute/beta/",
+			expectError:        false,
+		},
 		{
 			name:               "check for random string as endpoint",
 			computeEndpoint:    convertStringToURL(""),
@@ -138,7 +156,7 @@ func TestGetComputeVersion(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		ctx := context.Background()
-		computeOpts, err := getComputeVersion(ctx, &mockTokenSource{}, tc.computeEndpoint, tc.computeEnvironment, tc.computeVersion)
+		computeOpts, err := getComputeVersion(ctx, &mockTokenSource{}, tc.computeEndpoint, tc.universeDomain, nil, tc.computeEnvironment, tc.computeVersion)
 		service, _ := compute.NewService(ctx, computeOpts...)
 		gotEndpoint := service.BasePath
 		if err != nil && !tc.expectError {
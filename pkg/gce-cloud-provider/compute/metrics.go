@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"k8s.io/component-base/metrics"
+)
+
+const (
+	// Operation wait methods used as the wait_method metric label.
+	opWaitMethodWait = "wait"
+	opWaitMethodPoll = "poll"
+)
+
+var operationWaitDurationMetric = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Subsystem:      "csidriver",
+		Name:           "operation_wait_duration_seconds",
+		Help:           "Time spent waiting for GCE operations to complete",
+		Buckets:        metrics.ExponentialBuckets(0.25, 2, 12),
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"operation_type", "scope", "wait_method"})
+
+var tagBindingsPendingMetric = metrics.NewGauge(
+	&metrics.GaugeOpts{
+		Subsystem:      "csidriver",
+		Name:           "tag_bindings_pending",
+		Help:           "Resources queued for resource manager tag reconciliation",
+		StabilityLevel: metrics.ALPHA,
+	})
+
+var tagBindingFailuresMetric = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "csidriver",
+		Name:           "tag_binding_failures",
+		Help:           "Failures to bind resource manager tags to compute resources",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"resource_type"})
+
+var apiBudgetWaitDurationMetric = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Subsystem:      "csidriver",
+		Name:           "compute_api_budget_wait_duration_seconds",
+		Help:           "Time compute API calls waited for the client-side API budget",
+		Buckets:        metrics.ExponentialBuckets(0.01, 2, 12),
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"method_class", "call_type", "priority"})
+
+var apiBudgetRejectionsMetric = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "csidriver",
+		Name:           "compute_api_budget_rejections",
+		Help:           "Compute API calls rejected because the client-side API budget could not be met before the caller deadline",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"method_class", "call_type", "priority"})
+
+var apiRetriesMetric = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "csidriver",
+		Name:           "compute_api_retries",
+		Help:           "Compute API calls retried after a transient or throttling error",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"method_class", "error_class"})
+
+var circuitBreakerOpenMetric = metrics.NewGaugeVec(
+	&metrics.GaugeOpts{
+		Subsystem:      "csidriver",
+		Name:           "compute_api_circuit_breaker_open",
+		Help:           "Whether the compute API circuit breaker of a zone or region is open",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"location"})
+
+var circuitBreakerRejectionsMetric = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "csidriver",
+		Name:           "compute_api_circuit_breaker_rejections",
+		Help:           "Compute API calls failed fast because the circuit breaker of their zone or region was open",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"location"})
+
+// CloudProviderMetrics returns the metrics of the cloud provider, for the
+// controller to register in the registry of its metrics manager.
+func CloudProviderMetrics() []metrics.Registerable {
+	return []metrics.Registerable{
+		tokenRefreshErrorsMetric,
+		operationWaitDurationMetric,
+		tagBindingsPendingMetric,
+		tagBindingFailuresMetric,
+		apiBudgetWaitDurationMetric,
+		apiBudgetRejectionsMetric,
+		apiRetriesMetric,
+		circuitBreakerOpenMetric,
+		circuitBreakerRejectionsMetric,
+	}
+}
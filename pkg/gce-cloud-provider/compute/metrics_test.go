@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"sync"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/component-base/metrics"
+)
+
+var (
+	testMetricsRegistry     = metrics.NewKubeRegistry()
+	registerTestMetricsOnce sync.Once
+)
+
+// gatherMetric returns the metric of the named family with the given labels,
+// or an empty metric if it has not been recorded.
+func gatherMetric(t *testing.T, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	registerTestMetricsOnce.Do(func() {
+		testMetricsRegistry.MustRegister(CloudProviderMetrics()...)
+	})
+	families, err := testMetricsRegistry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			matched := 0
+			for _, label := range m.GetLabel() {
+				if v, ok := labels[label.GetName()]; ok && v == label.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return m
+			}
+		}
+	}
+	return &dto.Metric{}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+const (
+	journalKindInsert = "insert"
+	journalKindAttach = "attach"
+	journalKindDetach = "detach"
+	journalKindResize = "resize"
+
+	// configMapJournalAttempts is the number of attempts of a ConfigMap
+	// journal update conflicting with another one.
+	configMapJournalAttempts = 5
+	// operationJournalConfigMapPrefix prefixes the names of the ConfigMaps
+	// of the journaled operations.
+	operationJournalConfigMapPrefix = "pd-csi-operation-"
+	// operationJournalKeyAnnotation records the journal key of a ConfigMap,
+	// whose name is a hash of it.
+	operationJournalKeyAnnotation = "pd.csi.storage.gke.io/operation-key"
+	// operationJournalDataKey is the data key of the operation in its
+	// ConfigMap.
+	operationJournalDataKey = "operation"
+)
+
+// JournaledOperation is an in-flight GCE operation recorded in an
+// OperationJournal.
+type JournaledOperation struct {
+	// Name is the name of the GCE operation.
+	Name string `json:"name"`
+	// Kind is the call that started the operation, e.g. "attach".
+	Kind string `json:"kind"`
+	// Target tells apart calls of the same kind on the same key, e.g. the
+	// requested size of a resize.
+	Target  string `json:"target,omitempty"`
+	Project string `json:"project"`
+	// Zone or Region is the location of the operation.
+	Zone      string    `json:"zone,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	StartTime time.Time `json:"startTime"`
+}
+
+// OperationJournal records the in-flight GCE operations of the controller.
+// A controller restarted while waiting for an operation looks it up, and
+// waits for it before starting another one, which would otherwise fail with
+// RESOURCE_IN_USE until the first one is done.
+//
+// Operations are keyed by the volume ID for the operations on a disk, and by
+// the disk device of an instance for attach and detach.
+type OperationJournal interface {
+	// Get returns the operation recorded for key, or nil.
+	Get(ctx context.Context, key string) (*JournaledOperation, error)
+	// Record records op for key, replacing the previous operation.
+	Record(ctx context.Context, key string, op JournaledOperation) error
+	// Remove removes the operation of key if it is the operation opName.
+	Remove(ctx context.Context, key, opName string) error
+}
+
+// memoryOperationJournal is an OperationJournal lost on restart. It still
+// resumes operations that a call stopped waiting for when its context
+// expired.
+type memoryOperationJournal struct {
+	mux sync.Mutex
+	ops map[string]JournaledOperation
+}
+
+// NewMemoryOperationJournal returns an OperationJournal kept in memory.
+func NewMemoryOperationJournal() OperationJournal {
+	return &memoryOperationJournal{ops: map[string]JournaledOperation{}}
+}
+
+func (j *memoryOperationJournal) Get(_ context.Context, key string) (*JournaledOperation, error) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	op, ok := j.ops[key]
+	if !ok {
+		return nil, nil
+	}
+	return &op, nil
+}
+
+func (j *memoryOperationJournal) Record(_ context.Context, key string, op JournaledOperation) error {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.ops[key] = op
+	return nil
+}
+
+func (j *memoryOperationJournal) Remove(_ context.Context, key, opName string) error {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if op, ok := j.ops[key]; ok && op.Name == opName {
+		delete(j.ops, key)
+	}
+	return nil
+}
+
+// ConfigMapClient is the subset of the ConfigMap client of a namespace used
+// by the ConfigMap journal.
+type ConfigMapClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.ConfigMap, error)
+	Create(ctx context.Context, configMap *v1.ConfigMap, opts metav1.CreateOptions) (*v1.ConfigMap, error)
+	Update(ctx context.Context, configMap *v1.ConfigMap, opts metav1.UpdateOptions) (*v1.ConfigMap, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// configMapOperationJournal is an OperationJournal stored in ConfigMaps, one
+// per journal key, so that it survives restarts and moves with the leader of
+// the controller. The ConfigMap of a key is deleted with its operation, so
+// that the journal only holds the in-flight operations.
+type configMapOperationJournal struct {
+	client ConfigMapClient
+}
+
+// NewConfigMapOperationJournal returns an OperationJournal stored in the
+// ConfigMaps of client.
+func NewConfigMapOperationJournal(client ConfigMapClient) OperationJournal {
+	return &configMapOperationJournal{client: client}
+}
+
+// operationJournalConfigMapName returns the name of the ConfigMap of a
+// journal key. Journal keys are not valid object names, so the name is a hash
+// of the key.
+func operationJournalConfigMapName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return operationJournalConfigMapPrefix + hex.EncodeToString(sum[:16])
+}
+
+// decodeJournaledOperation returns the operation stored in cm.
+func decodeJournaledOperation(cm *v1.ConfigMap) (*JournaledOperation, error) {
+	op := &JournaledOperation{}
+	if err := json.Unmarshal([]byte(cm.Data[operationJournalDataKey]), op); err != nil {
+		return nil, fmt.Errorf("failed to decode the operation in ConfigMap %s: %w", cm.Name, err)
+	}
+	return op, nil
+}
+
+func (j *configMapOperationJournal) Get(ctx context.Context, key string) (*JournaledOperation, error) {
+	cm, err := j.client.Get(ctx, operationJournalConfigMapName(key), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the journaled operation of %s: %w", key, err)
+	}
+	return decodeJournaledOperation(cm)
+}
+
+func (j *configMapOperationJournal) Record(ctx context.Context, key string, op JournaledOperation) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        operationJournalConfigMapName(key),
+			Annotations: map[string]string{operationJournalKeyAnnotation: key},
+		},
+		Data: map[string]string{operationJournalDataKey: string(data)},
+	}
+	for attempt := 0; attempt < configMapJournalAttempts; attempt++ {
+		_, err = j.client.Create(ctx, cm, metav1.CreateOptions{})
+		if !apierrors.IsAlreadyExists(err) {
+			break
+		}
+		// Replace the previous operation of the key.
+		var current *v1.ConfigMap
+		current, err = j.client.Get(ctx, cm.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			break
+		}
+		current.Data = cm.Data
+		_, err = j.client.Update(ctx, current, metav1.UpdateOptions{})
+		if !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record operation %s of %s: %w", op.Name, key, err)
+	}
+	return nil
+}
+
+func (j *configMapOperationJournal) Remove(ctx context.Context, key, opName string) error {
+	name := operationJournalConfigMapName(key)
+	cm, err := j.client.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the journaled operation of %s: %w", key, err)
+	}
+	if op, err := decodeJournaledOperation(cm); err == nil && op.Name != opName {
+		return nil
+	}
+	// The resource version keeps an operation recorded since.
+	err = j.client.Delete(ctx, name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &cm.ResourceVersion},
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove operation %s of %s: %w", opName, key, err)
+	}
+	return nil
+}
+
+// diskJournalKey returns the journal key of the operations on a disk.
+func diskJournalKey(project string, volKey *meta.Key) string {
+	volumeID, err := common.KeyToVolumeID(volKey, project)
+	if err != nil {
+		return fmt.Sprintf("projects/%s/disks/%s", project, volKey.Name)
+	}
+	return volumeID
+}
+
+// deviceJournalKey returns the journal key of the attach and detach of a
+// disk device of an instance.
+func deviceJournalKey(project, instanceZone, instanceName, deviceName string) string {
+	return fmt.Sprintf("projects/%s/zones/%s/instances/%s/devices/%s", project, instanceZone, instanceName, deviceName)
+}
+
+// resumeJournaledOperation waits for the operation recorded for key, if any,
+// before a call of kind on target starts another one. It returns true if the
+// recorded operation was started by the same call and succeeded, in which
+// case the call is done.
+//
+// The journal is best effort: errors reading it are logged and ignored.
+func (cloud *CloudProvider) resumeJournaledOperation(ctx context.Context, key, kind, target string) (bool, error) {
+	if cloud.OperationJournal == nil {
+		return false, nil
+	}
+	op, err := cloud.OperationJournal.Get(ctx, key)
+	if err != nil {
+		klog.Warningf("Failed to look up the in-flight operation of %s: %v", key, err)
+		return false, nil
+	}
+	if op == nil {
+		return false, nil
+	}
+	klog.Infof("Waiting for in-flight %s operation %s of %s, started at %v, before starting %s", op.Kind, op.Name, key, op.StartTime, kind)
+	var waitErr error
+	switch {
+	case op.Zone != "":
+		waitErr = cloud.waitForZonalOp(ctx, op.Project, op.Name, op.Zone)
+	case op.Region != "":
+		waitErr = cloud.waitForRegionalOp(ctx, op.Project, op.Name, op.Region)
+	default:
+		waitErr = cloud.waitForGlobalOp(ctx, op.Project, op.Name)
+	}
+	if waitErr != nil && !operationFailed(ctx, waitErr) && !IsGCENotFoundError(waitErr) {
+		// The operation may still be running, keep it for the next call.
+		return false, fmt.Errorf("failed waiting for in-flight %s operation %s of %s: %w", op.Kind, op.Name, key, waitErr)
+	}
+	cloud.removeJournaledOperation(ctx, key, op.Name)
+	if waitErr != nil {
+		klog.Infof("In-flight %s operation %s of %s did not succeed: %v", op.Kind, op.Name, key, waitErr)
+		return false, nil
+	}
+	return op.Kind == kind && op.Target == target, nil
+}
+
+// journalOperation records an operation started by the driver.
+func (cloud *CloudProvider) journalOperation(ctx context.Context, key string, op JournaledOperation) {
+	if cloud.OperationJournal == nil || op.Name == "" {
+		return
+	}
+	op.StartTime = time.Now()
+	if err := cloud.OperationJournal.Record(ctx, key, op); err != nil {
+		klog.Warningf("Failed to record in-flight %s operation %s of %s: %v", op.Kind, op.Name, key, err)
+	}
+}
+
+// finishJournaledOperation removes a recorded operation once waiting for it
+// returned err, unless the operation may still be running.
+func (cloud *CloudProvider) finishJournaledOperation(ctx context.Context, key, opName string, err error) {
+	if err != nil && !operationFailed(ctx, err) {
+		return
+	}
+	cloud.removeJournaledOperation(ctx, key, opName)
+}
+
+func (cloud *CloudProvider) removeJournaledOperation(ctx context.Context, key, opName string) {
+	if cloud.OperationJournal == nil || opName == "" {
+		return
+	}
+	// Remove the operation even if the call context is done, so that the
+	// next call does not wait for it.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := cloud.OperationJournal.Remove(ctx, key, opName); err != nil {
+		klog.Warningf("Failed to remove operation %s of %s from the journal: %v", opName, key, err)
+	}
+}
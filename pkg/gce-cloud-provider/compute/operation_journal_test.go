@@ -0,0 +1,242 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	computev1 "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var configMapsResource = schema.GroupResource{Resource: "configmaps"}
+
+// fakeConfigMapClient stores ConfigMaps and rejects updates and deletes of
+// stale versions of them.
+type fakeConfigMapClient struct {
+	mux     sync.Mutex
+	cms     map[string]*v1.ConfigMap
+	version int
+	// conflicts is the number of updates to reject with a conflict.
+	conflicts int
+}
+
+func newFakeConfigMapClient(conflicts int) *fakeConfigMapClient {
+	return &fakeConfigMapClient{cms: map[string]*v1.ConfigMap{}, conflicts: conflicts}
+}
+
+func (c *fakeConfigMapClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*v1.ConfigMap, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	cm, ok := c.cms[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(configMapsResource, name)
+	}
+	return cm.DeepCopy(), nil
+}
+
+func (c *fakeConfigMapClient) Create(_ context.Context, cm *v1.ConfigMap, _ metav1.CreateOptions) (*v1.ConfigMap, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.cms[cm.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(configMapsResource, cm.Name)
+	}
+	return c.storeLocked(cm), nil
+}
+
+func (c *fakeConfigMapClient) Update(_ context.Context, cm *v1.ConfigMap, _ metav1.UpdateOptions) (*v1.ConfigMap, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	current, ok := c.cms[cm.Name]
+	if !ok {
+		return nil, apierrors.NewNotFound(configMapsResource, cm.Name)
+	}
+	if c.conflicts > 0 || cm.ResourceVersion != current.ResourceVersion {
+		c.conflicts--
+		return nil, apierrors.NewConflict(configMapsResource, cm.Name, nil)
+	}
+	return c.storeLocked(cm), nil
+}
+
+func (c *fakeConfigMapClient) Delete(_ context.Context, name string, opts metav1.DeleteOptions) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	current, ok := c.cms[name]
+	if !ok {
+		return apierrors.NewNotFound(configMapsResource, name)
+	}
+	if opts.Preconditions != nil && opts.Preconditions.ResourceVersion != nil && *opts.Preconditions.ResourceVersion != current.ResourceVersion {
+		return apierrors.NewConflict(configMapsResource, name, nil)
+	}
+	delete(c.cms, name)
+	return nil
+}
+
+func (c *fakeConfigMapClient) storeLocked(cm *v1.ConfigMap) *v1.ConfigMap {
+	c.version++
+	stored := cm.DeepCopy()
+	stored.ResourceVersion = strconv.Itoa(c.version)
+	c.cms[cm.Name] = stored
+	return stored.DeepCopy()
+}
+
+func TestOperationJournals(t *testing.T) {
+	journals := map[string]func() OperationJournal{
+		"memory": NewMemoryOperationJournal,
+		"configmap": func() OperationJournal {
+			return NewConfigMapOperationJournal(newFakeConfigMapClient(2))
+		},
+	}
+	key := "projects/example.com:p/zones/z/disks/d"
+	for name, newJournal := range journals {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			j := newJournal()
+			if op, err := j.Get(ctx, key); err != nil || op != nil {
+				t.Fatalf("Get() on an empty journal = %v, %v, want nil", op, err)
+			}
+			// Recording an operation replaces the previous one of the key.
+			if err := j.Record(ctx, key, JournaledOperation{Name: "op-0", Kind: journalKindAttach}); err != nil {
+				t.Fatalf("Record() failed: %v", err)
+			}
+			if err := j.Record(ctx, key, JournaledOperation{Name: "op-1", Kind: journalKindResize, Target: "20", Project: "p", Zone: "z"}); err != nil {
+				t.Fatalf("Record() failed: %v", err)
+			}
+			if err := j.Record(ctx, "other", JournaledOperation{Name: "op-2", Kind: journalKindAttach}); err != nil {
+				t.Fatalf("Record() failed: %v", err)
+			}
+			op, err := j.Get(ctx, key)
+			if err != nil || op == nil || op.Name != "op-1" || op.Kind != journalKindResize || op.Target != "20" || op.Zone != "z" {
+				t.Fatalf("Get() = %+v, %v, want op-1", op, err)
+			}
+
+			// Removing another operation of the key keeps the recorded one.
+			if err := j.Remove(ctx, key, "op-0"); err != nil {
+				t.Fatalf("Remove() failed: %v", err)
+			}
+			if op, _ := j.Get(ctx, key); op == nil {
+				t.Errorf("Remove() of another operation removed op-1")
+			}
+			if err := j.Remove(ctx, key, "op-1"); err != nil {
+				t.Fatalf("Remove() failed: %v", err)
+			}
+			if op, _ := j.Get(ctx, key); op != nil {
+				t.Errorf("Get() after Remove() = %+v, want nil", op)
+			}
+			if op, _ := j.Get(ctx, "other"); op == nil || op.Name != "op-2" {
+				t.Errorf("Get() of another key = %+v, want op-2", op)
+			}
+		})
+	}
+}
+
+func TestConfigMapOperationJournalObjectPerKey(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeConfigMapClient(0)
+	j := NewConfigMapOperationJournal(client)
+	keys := []string{"projects/p/zones/z/disks/a", "projects/p/zones/z/disks/b"}
+	for i, key := range keys {
+		if err := j.Record(ctx, key, JournaledOperation{Name: fmt.Sprintf("op-%d", i)}); err != nil {
+			t.Fatalf("Record() failed: %v", err)
+		}
+	}
+	if len(client.cms) != len(keys) {
+		t.Errorf("Record() stored %d ConfigMaps, want %d", len(client.cms), len(keys))
+	}
+	for i, key := range keys {
+		if err := j.Remove(ctx, key, fmt.Sprintf("op-%d", i)); err != nil {
+			t.Fatalf("Remove() failed: %v", err)
+		}
+	}
+	if len(client.cms) != 0 {
+		t.Errorf("Remove() left %d ConfigMaps, want none", len(client.cms))
+	}
+}
+
+// journalServer serves the calls of attach and detach, and records them.
+type journalServer struct {
+	mux   sync.Mutex
+	calls []string
+}
+
+func (s *journalServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	segments := strings.Split(r.URL.Path, "/")
+	switch last := segments[len(segments)-1]; last {
+	case "attachDisk", "detachDisk":
+		s.calls = append(s.calls, last)
+		w.Write([]byte(`{"name": "op-` + last + `", "status": "RUNNING"}`))
+	case "wait":
+		opName := segments[len(segments)-2]
+		s.calls = append(s.calls, "wait "+opName)
+		w.Write([]byte(`{"name": "` + opName + `", "status": "DONE"}`))
+	default:
+		w.Write([]byte(`{"name": "instance", "fingerprint": "fp"}`))
+	}
+}
+
+func TestAttachDetachResumeJournaledOperation(t *testing.T) {
+	server := &journalServer{}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("Failed to create compute service: %v", err)
+	}
+	ctx := context.Background()
+	journal := NewMemoryOperationJournal()
+	cloud := &CloudProvider{service: svc, project: "p", OperationJournal: journal}
+	journalKey := deviceJournalKey("p", "z", "instance", "disk")
+
+	// An attach recorded before a restart is waited for, and not started
+	// again.
+	journal.Record(ctx, journalKey, JournaledOperation{Name: "op-before-restart", Kind: journalKindAttach, Project: "p", Zone: "z"})
+	if err := cloud.AttachDisk(ctx, "p", meta.ZonalKey("disk", "z"), "READ_WRITE", "PERSISTENT", "z", "instance", "fp", false); err != nil {
+		t.Fatalf("AttachDisk() failed: %v", err)
+	}
+	// A detach waits for the recorded attach, then detaches.
+	journal.Record(ctx, journalKey, JournaledOperation{Name: "op-before-restart", Kind: journalKindAttach, Project: "p", Zone: "z"})
+	if err := cloud.DetachDisk(ctx, "p", "disk", "z", "instance", "fp"); err != nil {
+		t.Fatalf("DetachDisk() failed: %v", err)
+	}
+
+	want := []string{
+		"wait op-before-restart",
+		"wait op-before-restart",
+		"detachDisk",
+		"wait op-detachDisk",
+	}
+	if strings.Join(server.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("server got calls %v, want %v", server.calls, want)
+	}
+	if op, _ := journal.Get(ctx, journalKey); op != nil {
+		t.Errorf("journal still has operation %+v", op)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	computev1 "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// requestIDNamespace is the UUID namespace of the request IDs of the driver.
+var requestIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/kubernetes-sigs/gcp-compute-persistent-disk-csi-driver"))
+
+// requestID is the GCE request ID of a mutating call. GCE deduplicates calls
+// with the same request ID for a while, and returns the operation started by
+// the first call instead of starting another one.
+type requestID struct {
+	// key identifies the call, e.g. the attach of a disk to an instance.
+	key string
+	// id is the UUID sent to GCE.
+	id string
+}
+
+// requestIDGenerator derives deterministic request IDs from the calls, so
+// that a call retried after a timeout or a restart of the driver resumes the
+// operation started by the first call.
+//
+// GCE also returns the operation of the first call when it failed, so a key
+// whose operation failed gets a new request ID for the next call. The zero
+// value is ready to use.
+type requestIDGenerator struct {
+	mux sync.Mutex
+	// generations counts the failed operations of each key.
+	generations map[string]int
+}
+
+// next returns the request ID of the call identified by op and parts.
+func (g *requestIDGenerator) next(op string, parts ...string) requestID {
+	key := strings.Join(append([]string{op}, parts...), "\x00")
+	g.mux.Lock()
+	generation := g.generations[key]
+	g.mux.Unlock()
+	name := key + "\x00" + strconv.Itoa(generation)
+	return requestID{
+		key: key,
+		id:  uuid.NewSHA1(requestIDNamespace, []byte(name)).String(),
+	}
+}
+
+// done records the outcome of waiting for the operation of rid. If the
+// operation failed, the next call with the same key gets a new request ID.
+func (g *requestIDGenerator) done(ctx context.Context, rid requestID, err error) {
+	if !operationFailed(ctx, err) {
+		return
+	}
+	klog.V(5).Infof("Operation of request %s failed, not reusing the request ID: %v", rid.id, err)
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if g.generations == nil {
+		g.generations = map[string]int{}
+	}
+	g.generations[rid.key]++
+}
+
+// maxFailedInstanceOperations bounds the failed operations of earlier calls
+// callWithInstanceRequestID skips before calling without a request ID.
+const maxFailedInstanceOperations = 5
+
+// instanceRequestID returns the request ID of attaching or detaching a disk
+// to the instance with the given fingerprint, or "" if the fingerprint is
+// unknown. The fingerprint changes with each attach and detach, so attaching
+// a disk again after detaching it does not resume the first attach, while a
+// retry against the same instance state, even from a restarted driver, does.
+func instanceRequestID(op, project, instanceZone, instanceName, deviceName, fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	name := strings.Join([]string{op, project, instanceZone, instanceName, deviceName, fingerprint}, "\x00")
+	return uuid.NewSHA1(requestIDNamespace, []byte(name)).String()
+}
+
+// callWithInstanceRequestID makes call with the request ID rid. The instance
+// fingerprint does not change when an attach or detach fails, and GCE returns
+// the failed operation of the first call for the same request ID, so an
+// operation that already failed is skipped by calling again with a request ID
+// derived from it. This is as deterministic as rid, without keeping any state.
+func callWithInstanceRequestID(rid string, call func(rid string) (*computev1.Operation, error)) (*computev1.Operation, error) {
+	for i := 0; ; i++ {
+		op, err := call(rid)
+		if err != nil || rid == "" || op.Status != "DONE" || op.Error == nil {
+			return op, err
+		}
+		if i == maxFailedInstanceOperations {
+			rid = ""
+			klog.V(5).Infof("Operations of %d earlier calls failed, calling without a request ID", i+1)
+			continue
+		}
+		klog.V(5).Infof("Operation %s of request %s already failed, not reusing the request ID", op.Name, rid)
+		rid = uuid.NewSHA1(requestIDNamespace, []byte(rid+"\x00"+op.Name)).String()
+	}
+}
+
+// operationFailed returns true if err, returned waiting for an operation, is
+// the error the operation completed with rather than a failure to wait for
+// it. An operation that could not be waited for may still be running, and is
+// resumed by calling again with the same request ID.
+func operationFailed(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || wait.Interrupted(err) {
+		return false
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return false
+	}
+	var unsupportedErr *UnsupportedDiskError
+	if errors.As(err, &unsupportedErr) {
+		return true
+	}
+	// wrapOpErr returns the operation errors as status errors.
+	_, ok := status.FromError(err)
+	return ok
+}
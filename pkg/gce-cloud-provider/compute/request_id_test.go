@@ -0,0 +1,192 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"github.com/google/uuid"
+	computev1 "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestIDGenerator(t *testing.T) {
+	var g requestIDGenerator
+	ctx := context.Background()
+
+	rid := g.next("attach", "p", "z", "i", "d", "fp")
+	if parsed, err := uuid.Parse(rid.id); err != nil || parsed.Version() != 5 {
+		t.Fatalf("next() = %q, want a version 5 UUID (error %v)", rid.id, err)
+	}
+	if again := g.next("attach", "p", "z", "i", "d", "fp"); again != rid {
+		t.Errorf("next() for the same call = %v, want %v", again, rid)
+	}
+	if other := g.next("detach", "p", "z", "i", "d", "fp"); other.id == rid.id {
+		t.Errorf("next() for another call returned the same request ID %s", rid.id)
+	}
+
+	// Failures to wait for the operation keep the request ID, to resume the
+	// operation.
+	ctxDone, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, tc := range []struct {
+		ctx context.Context
+		err error
+	}{
+		{ctx, nil},
+		{ctxDone, status.Error(codes.Internal, "operation failed")},
+		{ctx, &googleapi.Error{Code: http.StatusServiceUnavailable}},
+		{ctx, fmt.Errorf("failed when waiting for zonal op: %w", context.DeadlineExceeded)},
+	} {
+		g.done(tc.ctx, rid, tc.err)
+		if again := g.next("attach", "p", "z", "i", "d", "fp"); again != rid {
+			t.Errorf("next() after done(%v) = %v, want %v", tc.err, again, rid)
+		}
+	}
+
+	// A failed operation gets a new request ID.
+	g.done(ctx, rid, fmt.Errorf("failed when waiting for zonal op: %w", status.Error(codes.InvalidArgument, "operation failed")))
+	retried := g.next("attach", "p", "z", "i", "d", "fp")
+	if retried.id == rid.id {
+		t.Errorf("next() after a failed operation returned the same request ID %s", rid.id)
+	}
+	g.done(ctx, retried, &UnsupportedDiskError{DiskType: "pd-standard"})
+	if again := g.next("attach", "p", "z", "i", "d", "fp"); again.id == retried.id || again.id == rid.id {
+		t.Errorf("next() after an unsupported disk error returned a used request ID %s", again.id)
+	}
+}
+
+// attachServer serves the calls of CloudProvider.AttachDisk and records the
+// request IDs of the attach calls. Like GCE, it returns the operation of the
+// first call with a request ID, even if it failed.
+type attachServer struct {
+	mux sync.Mutex
+	// opError is the error code of new attach operations, if set.
+	opError    string
+	requestIDs []string
+	// ops are the operations started for each request ID.
+	ops map[string]string
+}
+
+func (s *attachServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/attachDisk"):
+		rid := r.URL.Query().Get("requestId")
+		s.requestIDs = append(s.requestIDs, rid)
+		if op, ok := s.ops[rid]; ok && rid != "" {
+			w.Write([]byte(op))
+			return
+		}
+		name := fmt.Sprintf("op-%d", len(s.requestIDs))
+		op := fmt.Sprintf(`{"name": %q, "status": "DONE"}`, name)
+		if s.opError != "" {
+			op = fmt.Sprintf(`{"name": %q, "status": "DONE", "error": {"errors": [{"code": %q, "message": "failed"}]}}`, name, s.opError)
+		}
+		if s.ops == nil {
+			s.ops = map[string]string{}
+		}
+		s.ops[rid] = op
+		fmt.Fprintf(w, `{"name": %q, "status": "RUNNING"}`, name)
+	case strings.HasSuffix(r.URL.Path, "/wait"):
+		segments := strings.Split(r.URL.Path, "/")
+		opName := segments[len(segments)-2]
+		for _, op := range s.ops {
+			if strings.Contains(op, fmt.Sprintf("%q", opName)) {
+				w.Write([]byte(op))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAttachDiskRequestID(t *testing.T) {
+	server := &attachServer{}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	svc, err := computev1.NewService(context.Background(), option.WithEndpoint(srv.URL), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("Failed to create compute service: %v", err)
+	}
+	volKey := meta.ZonalKey("disk", "z")
+	attach := func(fingerprint string) error {
+		// A new CloudProvider for each attach, as after a restart.
+		cloud := &CloudProvider{service: svc, project: "p"}
+		return cloud.AttachDisk(context.Background(), "p", volKey, "READ_WRITE", "PERSISTENT", "z", "instance", fingerprint, false)
+	}
+
+	// A retried attach to the same instance resumes the first one.
+	for i := 0; i < 2; i++ {
+		if err := attach("fp1"); err != nil {
+			t.Fatalf("AttachDisk() failed: %v", err)
+		}
+	}
+	// Once the instance changed, the disk is attached again.
+	server.opError = "RESOURCE_NOT_FOUND"
+	if err := attach("fp2"); err == nil {
+		t.Fatalf("AttachDisk() succeeded, want the operation error")
+	}
+	// A failed attach is not resumed, even though the fingerprint is the
+	// same.
+	server.opError = ""
+	if err := attach("fp2"); err != nil {
+		t.Fatalf("AttachDisk() failed: %v", err)
+	}
+	// Without a fingerprint, no request ID is sent.
+	if err := attach(""); err != nil {
+		t.Fatalf("AttachDisk() failed: %v", err)
+	}
+
+	ids := server.requestIDs
+	if len(ids) != 6 {
+		t.Fatalf("server got %d attach calls (%v), want 6", len(ids), ids)
+	}
+	for i, id := range ids[:5] {
+		if id == "" {
+			t.Errorf("attach call %d has no request ID", i)
+		}
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("retried attach got request ID %s, want %s", ids[1], ids[0])
+	}
+	if ids[2] == ids[1] {
+		t.Errorf("attach after the instance changed reused request ID %s", ids[2])
+	}
+	if ids[3] != ids[2] {
+		t.Errorf("attach with the same fingerprint got request ID %s, want %s", ids[3], ids[2])
+	}
+	if ids[4] == ids[3] {
+		t.Errorf("attach after a failed operation reused request ID %s", ids[4])
+	}
+	if ids[5] != "" {
+		t.Errorf("attach without a fingerprint got request ID %s", ids[5])
+	}
+}
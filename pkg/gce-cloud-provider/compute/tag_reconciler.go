@@ -0,0 +1,587 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	rscmgr "cloud.google.com/go/resourcemanager/apiv3"
+	rscmgrpb "cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/iterator"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// tagReconcileInitialBackoff and tagReconcileMaxBackoff bound the delay
+	// before a failed resource is retried.
+	tagReconcileInitialBackoff = 30 * time.Second
+	tagReconcileMaxBackoff     = 30 * time.Minute
+)
+
+// TagReconcilerConfig configures the asynchronous reconciliation of resource
+// manager tags on disks, snapshots and images created by the driver.
+type TagReconcilerConfig struct {
+	// Enabled makes tag bindings asynchronous. When disabled, tags are bound
+	// synchronously when a snapshot or image is created.
+	Enabled bool
+	// QueueFile is the path where the retry queue is persisted, so that
+	// pending resources survive a restart. If empty, the queue is in memory.
+	QueueFile string
+	// ResyncPeriod is how often queued resources are re-checked.
+	ResyncPeriod time.Duration
+	// ListPeriod is how often the disks, snapshots and images created by
+	// DriverName are listed, and queued for the ExtraTags they miss.
+	// Resources are otherwise only queued when created, and would never be
+	// checked again once dropped from the queue, e.g. with an in-memory
+	// queue on restart. If 0, or without extra tags, resources are not
+	// listed.
+	ListPeriod time.Duration
+	DriverName string
+	ExtraTags  map[string]string
+}
+
+// tagBindingsClientPool caches a TagBindingsClient per location, so that
+// tag requests do not create a client, and wait for a token, every time.
+type tagBindingsClientPool struct {
+	tokenSource oauth2.TokenSource
+	endpoints   *apiEndpoints
+
+	mux     sync.Mutex
+	clients map[string]*rscmgr.TagBindingsClient
+}
+
+func newTagBindingsClientPool(tokenSource oauth2.TokenSource, endpoints *apiEndpoints) *tagBindingsClientPool {
+	return &tagBindingsClientPool{
+		tokenSource: tokenSource,
+		endpoints:   endpoints,
+		clients:     make(map[string]*rscmgr.TagBindingsClient),
+	}
+}
+
+// get returns the client for location, creating it if needed. The empty
+// location is the global endpoint.
+func (p *tagBindingsClientPool) get(location string) (*rscmgr.TagBindingsClient, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if client, ok := p.clients[location]; ok {
+		return client, nil
+	}
+	// The client outlives the request that created it.
+	client, err := createTagBindingsClient(context.Background(), p.tokenSource, location, p.endpoints)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[location] = client
+	return client, nil
+}
+
+// tagBinder lists and creates tag bindings on compute resources.
+type tagBinder interface {
+	// effectiveTags returns the tags bound to parent, as a map of namespaced
+	// tag keys to namespaced tag values.
+	effectiveTags(ctx context.Context, location, parent string) (map[string]string, error)
+	// createTagBinding binds the namespaced tag value to parent. A binding
+	// that already exists is not an error.
+	createTagBinding(ctx context.Context, location, parent, tagValue string) error
+}
+
+// resourceManagerTagBinder is the tagBinder backed by the resource manager
+// API.
+type resourceManagerTagBinder struct {
+	clients *tagBindingsClientPool
+}
+
+var _ tagBinder = &resourceManagerTagBinder{}
+
+func (b *resourceManagerTagBinder) effectiveTags(ctx context.Context, location, parent string) (map[string]string, error) {
+	client, err := b.clients.get(location)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	it := client.ListEffectiveTags(ctx, &rscmgrpb.ListEffectiveTagsRequest{Parent: parent})
+	for {
+		tag, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return tags, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		tags[tag.GetNamespacedTagKey()] = tag.GetNamespacedTagValue()
+	}
+}
+
+func (b *resourceManagerTagBinder) createTagBinding(ctx context.Context, location, parent, tagValue string) error {
+	client, err := b.clients.get(location)
+	if err != nil {
+		return err
+	}
+	req := &rscmgrpb.CreateTagBindingRequest{
+		TagBinding: &rscmgrpb.TagBinding{
+			Parent:                 parent,
+			TagValueNamespacedName: tagValue,
+		},
+	}
+	op, err := client.CreateTagBinding(ctx, req, getRetryCallOptions()...)
+	if err != nil {
+		if isTagAPIError(err, http.StatusConflict) {
+			return nil
+		}
+		return err
+	}
+	_, err = op.Wait(ctx)
+	return err
+}
+
+// isTagAPIError returns true if err is a resource manager API error with the
+// given HTTP code.
+func isTagAPIError(err error, code int) bool {
+	var apiErr *apierror.APIError
+	return errors.As(err, &apiErr) && apiErr.HTTPCode() == code
+}
+
+// tagBindingParent returns the full resource name of a compute resource, as
+// used for the parent of its tag bindings.
+func tagBindingParent(project string, resourceType ResourceType, location string, isZonal bool, resourceID uint64) string {
+	if location == "" {
+		return fmt.Sprintf(globalComputeParentPathFmt, project, resourceType, resourceID)
+	}
+	if isZonal {
+		return fmt.Sprintf(zonalOrRegionalComputeParentPathFmt, project, "zones", location, resourceType, resourceID)
+	}
+	return fmt.Sprintf(zonalOrRegionalComputeParentPathFmt, project, "regions", location, resourceType, resourceID)
+}
+
+// tagBindingRequestForSelfLink returns a request without tags for the compute
+// resource of selfLink, e.g.
+// https://compute.googleapis.com/compute/v1/projects/p/zones/z/disks/d.
+func tagBindingRequestForSelfLink(resourceType ResourceType, selfLink string, id uint64) (TagBindingRequest, error) {
+	u, err := url.Parse(selfLink)
+	if err != nil {
+		return TagBindingRequest{}, fmt.Errorf("could not parse resource %s: %w", selfLink, err)
+	}
+	elts := strings.Split(u.Path, "/")
+	i := slices.Index(elts, "projects")
+	if i < 0 {
+		return TagBindingRequest{}, fmt.Errorf("no project in resource %s", selfLink)
+	}
+	elts = elts[i+1:]
+	req := TagBindingRequest{ResourceType: resourceType, ID: id}
+	switch {
+	case len(elts) == 4 && elts[1] == "global" && elts[2] == string(resourceType):
+		req.Project, req.Name = elts[0], elts[3]
+	case len(elts) == 5 && (elts[1] == "zones" || elts[1] == "regions") && elts[3] == string(resourceType):
+		req.Project, req.Location, req.Name = elts[0], elts[2], elts[4]
+		req.IsZonal = elts[1] == "zones"
+	default:
+		return TagBindingRequest{}, fmt.Errorf("unexpected %s resource %s", resourceType, selfLink)
+	}
+	return req, nil
+}
+
+// TagBindingRequest asks for resource manager tags to be bound to a compute
+// resource created by the driver.
+type TagBindingRequest struct {
+	Project      string       `json:"project"`
+	ResourceType ResourceType `json:"resourceType"`
+	// Location is the zone or region of the resource, empty for global
+	// resources.
+	Location string `json:"location,omitempty"`
+	IsZonal  bool   `json:"isZonal,omitempty"`
+	Name     string `json:"name"`
+	// ID is the numeric ID of the resource. If zero, it is looked up by name.
+	ID uint64 `json:"id,omitempty"`
+	// Tags maps namespaced tag keys ({parent}/{key}) to tag value short names.
+	Tags map[string]string `json:"tags"`
+	// AddOnly only binds the tags whose keys are not bound to the resource,
+	// leaving keys bound to other values as they are. It is set for the extra
+	// tags of listed resources, which a StorageClass may override.
+	AddOnly bool `json:"addOnly,omitempty"`
+}
+
+// clone returns a copy of the request that does not share its tags.
+func (r TagBindingRequest) clone() TagBindingRequest {
+	tags := make(map[string]string, len(r.Tags))
+	for k, v := range r.Tags {
+		tags[k] = v
+	}
+	r.Tags = tags
+	return r
+}
+
+func (r *TagBindingRequest) key() string {
+	return fmt.Sprintf("projects/%s/%s/%s/%s", r.Project, r.Location, r.ResourceType, r.Name)
+}
+
+// queuedTagBinding is an entry of the reconciler retry queue.
+type queuedTagBinding struct {
+	Request     TagBindingRequest `json:"request"`
+	Attempts    int               `json:"attempts,omitempty"`
+	NextAttempt time.Time         `json:"nextAttempt"`
+}
+
+// TagReconciler binds resource manager tags to compute resources in the
+// background. Resources stay queued, and are re-checked every resync period,
+// until their effective tags match the requested tags. Failures are retried
+// with backoff. The resources created by the driver are also periodically
+// listed, and queued for the extra tags they miss.
+type TagReconciler struct {
+	binder    tagBinder
+	limiter   *rate.Limiter
+	resolveID func(context.Context, *TagBindingRequest) (uint64, error)
+	// listCreated lists the resources created by the driver, as requests
+	// without tags.
+	listCreated  func(ctx context.Context, driverName string) ([]TagBindingRequest, error)
+	queueFile    string
+	resyncPeriod time.Duration
+	listPeriod   time.Duration
+	driverName   string
+	extraTags    map[string]string
+	now          func() time.Time
+
+	mux   sync.Mutex
+	queue map[string]*queuedTagBinding
+	// nextList is when the resources created by the driver are listed next.
+	nextList time.Time
+	wake     chan struct{}
+}
+
+func newTagReconciler(binder tagBinder, limiter *rate.Limiter, resolveID func(context.Context, *TagBindingRequest) (uint64, error), listCreated func(context.Context, string) ([]TagBindingRequest, error), config TagReconcilerConfig) (*TagReconciler, error) {
+	r := &TagReconciler{
+		binder:       binder,
+		limiter:      limiter,
+		resolveID:    resolveID,
+		listCreated:  listCreated,
+		queueFile:    config.QueueFile,
+		resyncPeriod: config.ResyncPeriod,
+		listPeriod:   config.ListPeriod,
+		driverName:   config.DriverName,
+		extraTags:    config.ExtraTags,
+		now:          time.Now,
+		queue:        make(map[string]*queuedTagBinding),
+		wake:         make(chan struct{}, 1),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Enqueue queues the resource for tag reconciliation. Tags requested for a
+// resource that is already queued are merged.
+func (r *TagReconciler) Enqueue(req TagBindingRequest) {
+	if len(req.Tags) == 0 {
+		return
+	}
+	r.mux.Lock()
+	r.enqueueLocked(req)
+	r.persistLocked()
+	r.mux.Unlock()
+
+	klog.V(4).Infof("Queued tag bindings %v for %s", req.Tags, req.key())
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *TagReconciler) enqueueLocked(req TagBindingRequest) {
+	key := req.key()
+	queued, ok := r.queue[key]
+	if !ok {
+		r.queue[key] = &queuedTagBinding{Request: req.clone(), NextAttempt: r.now()}
+		return
+	}
+	for k, v := range req.Tags {
+		// Tags added only if missing do not override requested ones.
+		if _, ok := queued.Request.Tags[k]; ok && req.AddOnly {
+			continue
+		}
+		queued.Request.Tags[k] = v
+	}
+	if req.ID != 0 {
+		queued.Request.ID = req.ID
+	}
+	queued.Request.AddOnly = queued.Request.AddOnly && req.AddOnly
+	queued.NextAttempt = r.now()
+}
+
+// Run reconciles queued resources until ctx is done.
+func (r *TagReconciler) Run(ctx context.Context) {
+	klog.Infof("Starting tag reconciler with %d queued resources", r.Len())
+	for {
+		r.listDue(ctx)
+		r.reconcileDue(ctx)
+
+		timer := time.NewTimer(r.nextWakeup())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-r.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Len returns the number of queued resources.
+func (r *TagReconciler) Len() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return len(r.queue)
+}
+
+// nextWakeup returns how long to sleep until the next queued resource is due,
+// at most the resync period.
+func (r *TagReconciler) nextWakeup() time.Duration {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	wakeup := r.resyncPeriod
+	now := r.now()
+	if r.listing() {
+		if d := r.nextList.Sub(now); d < wakeup {
+			wakeup = d
+		}
+	}
+	for _, q := range r.queue {
+		if d := q.NextAttempt.Sub(now); d < wakeup {
+			wakeup = d
+		}
+	}
+	if wakeup < 0 {
+		wakeup = 0
+	}
+	return wakeup
+}
+
+// listing returns true if the resources created by the driver are listed.
+func (r *TagReconciler) listing() bool {
+	return r.listPeriod > 0 && len(r.extraTags) > 0 && r.listCreated != nil
+}
+
+// listDue lists the resources created by the driver, if due, and queues them
+// for the extra tags they miss.
+func (r *TagReconciler) listDue(ctx context.Context) {
+	if !r.listing() {
+		return
+	}
+	r.mux.Lock()
+	due := !r.nextList.After(r.now())
+	r.mux.Unlock()
+	if !due {
+		return
+	}
+	// Listing is not urgent, leave the compute API budget to the calls
+	// serving CSI requests.
+	reqs, err := r.listCreated(WithAPIPriority(ctx, APIPriorityLow), r.driverName)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.nextList = r.now().Add(r.listPeriod)
+	if err != nil {
+		klog.Errorf("Failed to list the resources created by %s for tag reconciliation: %v", r.driverName, err)
+		return
+	}
+	for _, req := range reqs {
+		req.Tags = r.extraTags
+		req.AddOnly = true
+		r.enqueueLocked(req)
+	}
+	r.persistLocked()
+	klog.V(4).Infof("Queued %d resources created by %s for their extra tags", len(reqs), r.driverName)
+}
+
+// reconcileDue reconciles the queued resources whose next attempt is due.
+func (r *TagReconciler) reconcileDue(ctx context.Context) {
+	r.mux.Lock()
+	now := r.now()
+	due := []queuedTagBinding{}
+	for _, q := range r.queue {
+		if !q.NextAttempt.After(now) {
+			due = append(due, queuedTagBinding{Request: q.Request.clone(), Attempts: q.Attempts, NextAttempt: q.NextAttempt})
+		}
+	}
+	r.mux.Unlock()
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttempt.Before(due[j].NextAttempt) })
+
+	for _, q := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		done, err := r.reconcile(ctx, &q.Request)
+		r.finish(q, done, err)
+	}
+	r.mux.Lock()
+	tagBindingsPendingMetric.Set(float64(len(r.queue)))
+	r.mux.Unlock()
+}
+
+// reconcile binds the missing tags of the resource. It returns true once the
+// effective tags match the request, or the resource no longer exists.
+func (r *TagReconciler) reconcile(ctx context.Context, req *TagBindingRequest) (bool, error) {
+	if req.ID == 0 {
+		id, err := r.resolveID(ctx, req)
+		if IsGCENotFoundError(err) {
+			klog.Warningf("Dropping tag bindings for %s, the resource no longer exists", req.key())
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to get ID of %s: %w", req.key(), err)
+		}
+		req.ID = id
+	}
+
+	parent := tagBindingParent(req.Project, req.ResourceType, req.Location, req.IsZonal, req.ID)
+	effective, err := r.binder.effectiveTags(ctx, req.Location, parent)
+	if isTagAPIError(err, http.StatusNotFound) {
+		klog.Warningf("Dropping tag bindings for %s, the resource no longer exists", req.key())
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to list effective tags of %s: %w", parent, err)
+	}
+
+	var errs []error
+	missing := 0
+	for key, value := range req.Tags {
+		tagValue := fmt.Sprintf("%s/%s", key, value)
+		if bound, ok := effective[key]; ok {
+			if bound != tagValue && !req.AddOnly {
+				// A resource has a single value per tag key, so this cannot be
+				// fixed by adding a binding.
+				errs = append(errs, fmt.Errorf("tag %s of %s is bound to %s, want %s", key, parent, bound, tagValue))
+			}
+			continue
+		}
+		missing++
+		if err := r.limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+		if err := r.binder.createTagBinding(ctx, req.Location, parent, tagValue); err != nil {
+			tagBindingFailuresMetric.WithLabelValues(string(req.ResourceType)).Inc()
+			errs = append(errs, fmt.Errorf("failed to bind tag %s to %s: %w", tagValue, parent, err))
+		}
+	}
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	if missing > 0 {
+		// Keep the resource queued, its bindings are verified on the next
+		// resync.
+		klog.V(4).Infof("Bound %d tags to %s", missing, parent)
+		return false, nil
+	}
+	return true, nil
+}
+
+// finish updates the queue after q was reconciled.
+func (r *TagReconciler) finish(q queuedTagBinding, done bool, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	key := q.Request.key()
+	queued, ok := r.queue[key]
+	if !ok {
+		return
+	}
+	// Tags may have been added while the resource was being reconciled.
+	requeued := queued.NextAttempt.After(q.NextAttempt)
+	queued.Request.ID = q.Request.ID
+	switch {
+	case requeued:
+	case done:
+		klog.V(4).Infof("Tags of %s match the requested tags", key)
+		delete(r.queue, key)
+	case err != nil:
+		queued.Attempts++
+		backoff := tagReconcileInitialBackoff << min(queued.Attempts-1, 16)
+		if backoff > tagReconcileMaxBackoff {
+			backoff = tagReconcileMaxBackoff
+		}
+		queued.NextAttempt = r.now().Add(backoff)
+		klog.Errorf("Failed to reconcile tags of %s (attempt %d), retrying in %v: %v", key, queued.Attempts, backoff, err)
+	default:
+		queued.Attempts = 0
+		queued.NextAttempt = r.now().Add(r.resyncPeriod)
+	}
+	r.persistLocked()
+}
+
+// load reads the persisted queue, if any.
+func (r *TagReconciler) load() error {
+	if r.queueFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.queueFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tag reconciler queue %s: %w", r.queueFile, err)
+	}
+	var queued []*queuedTagBinding
+	if err := json.Unmarshal(data, &queued); err != nil {
+		return fmt.Errorf("failed to parse tag reconciler queue %s: %w", r.queueFile, err)
+	}
+	for _, q := range queued {
+		r.queue[q.Request.key()] = q
+	}
+	return nil
+}
+
+// persistLocked writes the queue to the queue file, if any. Failures are only
+// logged, the queue is still reconciled from memory.
+func (r *TagReconciler) persistLocked() {
+	tagBindingsPendingMetric.Set(float64(len(r.queue)))
+	if r.queueFile == "" {
+		return
+	}
+	queued := make([]*queuedTagBinding, 0, len(r.queue))
+	for _, q := range r.queue {
+		queued = append(queued, q)
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].Request.key() < queued[j].Request.key() })
+	data, err := json.Marshal(queued)
+	if err != nil {
+		klog.Errorf("Failed to encode tag reconciler queue: %v", err)
+		return
+	}
+	// Write a temporary file and rename it, so that a crash does not leave a
+	// truncated queue.
+	tmp := filepath.Join(filepath.Dir(r.queueFile), "."+filepath.Base(r.queueFile)+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		klog.Errorf("Failed to write tag reconciler queue %s: %v", tmp, err)
+		return
+	}
+	if err := os.Rename(tmp, r.queueFile); err != nil {
+		klog.Errorf("Failed to write tag reconciler queue %s: %v", r.queueFile, err)
+	}
+}
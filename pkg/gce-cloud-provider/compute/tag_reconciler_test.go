@@ -0,0 +1,302 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+)
+
+// fakeTagBinder keeps tag bindings in memory. Bindings of tag values listed
+// in failValues fail.
+type fakeTagBinder struct {
+	mux        sync.Mutex
+	bindings   map[string]map[string]string
+	failValues map[string]bool
+	creates    int
+}
+
+func newFakeTagBinder() *fakeTagBinder {
+	return &fakeTagBinder{
+		bindings:   map[string]map[string]string{},
+		failValues: map[string]bool{},
+	}
+}
+
+func (f *fakeTagBinder) effectiveTags(ctx context.Context, location, parent string) (map[string]string, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	tags := map[string]string{}
+	for k, v := range f.bindings[parent] {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func (f *fakeTagBinder) createTagBinding(ctx context.Context, location, parent, tagValue string) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.creates++
+	if f.failValues[tagValue] {
+		return errors.New("binding failed")
+	}
+	if f.bindings[parent] == nil {
+		f.bindings[parent] = map[string]string{}
+	}
+	f.bindings[parent][tagValue[:strings.LastIndex(tagValue, "/")]] = tagValue
+	return nil
+}
+
+func newTestTagReconciler(t *testing.T, binder tagBinder, queueFile string, resolveID func(context.Context, *TagBindingRequest) (uint64, error)) (*TagReconciler, *time.Time) {
+	t.Helper()
+	if resolveID == nil {
+		resolveID = func(context.Context, *TagBindingRequest) (uint64, error) { return 42, nil }
+	}
+	r, err := newTagReconciler(binder, rate.NewLimiter(rate.Inf, 1), resolveID, nil, TagReconcilerConfig{
+		QueueFile:    queueFile,
+		ResyncPeriod: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("newTagReconciler() failed: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func TestTagReconcilerBindsAndVerifiesTags(t *testing.T) {
+	binder := newFakeTagBinder()
+	r, now := newTestTagReconciler(t, binder, "", nil)
+	r.Enqueue(TagBindingRequest{
+		Project:      "project",
+		ResourceType: SnapshotsType,
+		Name:         "snapshot",
+		ID:           7,
+		Tags:         map[string]string{"parent/key1": "value1", "parent/key2": "value2"},
+	})
+
+	// The first pass binds the tags, the resource stays queued until they are
+	// verified on the next resync.
+	r.reconcileDue(context.Background())
+	want := map[string]string{"parent/key1": "parent/key1/value1", "parent/key2": "parent/key2/value2"}
+	if got := binder.bindings["//compute.googleapis.com/projects/project/global/snapshots/7"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("bindings = %v, want %v", got, want)
+	}
+	if r.Len() != 1 {
+		t.Fatalf("queue length = %d after binding, want 1", r.Len())
+	}
+
+	// Nothing is due before the resync period.
+	r.reconcileDue(context.Background())
+	if binder.creates != 2 || r.Len() != 1 {
+		t.Errorf("got %d creates and %d queued before resync, want 2 and 1", binder.creates, r.Len())
+	}
+
+	*now = now.Add(time.Minute)
+	r.reconcileDue(context.Background())
+	if r.Len() != 0 {
+		t.Errorf("queue length = %d after verification, want 0", r.Len())
+	}
+}
+
+func TestTagReconcilerRetriesFailures(t *testing.T) {
+	binder := newFakeTagBinder()
+	binder.failValues["parent/key/value"] = true
+	r, now := newTestTagReconciler(t, binder, "", nil)
+
+	labels := map[string]string{"resource_type": string(ImagesType)}
+	before := gatherMetric(t, "csidriver_tag_binding_failures", labels).GetCounter().GetValue()
+	r.Enqueue(TagBindingRequest{Project: "project", ResourceType: ImagesType, Name: "image", ID: 7, Tags: map[string]string{"parent/key": "value"}})
+	r.reconcileDue(context.Background())
+
+	if failures := gatherMetric(t, "csidriver_tag_binding_failures", labels).GetCounter().GetValue() - before; failures != 1 {
+		t.Errorf("tag_binding_failures = %v, want 1", failures)
+	}
+	if pending := gatherMetric(t, "csidriver_tag_bindings_pending", nil).GetGauge().GetValue(); pending != 1 {
+		t.Errorf("tag_bindings_pending = %v, want 1", pending)
+	}
+	if wakeup := r.nextWakeup(); wakeup != tagReconcileInitialBackoff {
+		t.Errorf("nextWakeup() = %v, want %v", wakeup, tagReconcileInitialBackoff)
+	}
+
+	delete(binder.failValues, "parent/key/value")
+	*now = now.Add(tagReconcileInitialBackoff)
+	r.reconcileDue(context.Background())
+	*now = now.Add(time.Minute)
+	r.reconcileDue(context.Background())
+	if r.Len() != 0 {
+		t.Errorf("queue length = %d after retry, want 0", r.Len())
+	}
+}
+
+func TestTagReconcilerDropsDeletedResources(t *testing.T) {
+	binder := newFakeTagBinder()
+	resolveID := func(context.Context, *TagBindingRequest) (uint64, error) {
+		return 0, &googleapi.Error{Code: http.StatusNotFound, Errors: []googleapi.ErrorItem{{Reason: "notFound"}}}
+	}
+	r, _ := newTestTagReconciler(t, binder, "", resolveID)
+	r.Enqueue(TagBindingRequest{Project: "project", ResourceType: DisksType, Location: "zone", IsZonal: true, Name: "disk", Tags: map[string]string{"parent/key": "value"}})
+	r.reconcileDue(context.Background())
+	if r.Len() != 0 || binder.creates != 0 {
+		t.Errorf("got %d queued and %d creates, want 0 and 0", r.Len(), binder.creates)
+	}
+}
+
+func TestTagReconcilerPersistsQueue(t *testing.T) {
+	queueFile := filepath.Join(t.TempDir(), "queue.json")
+	binder := newFakeTagBinder()
+	binder.failValues["parent/key/value"] = true
+	r, _ := newTestTagReconciler(t, binder, queueFile, nil)
+	req := TagBindingRequest{Project: "project", ResourceType: DisksType, Location: "region", Name: "disk", Tags: map[string]string{"parent/key": "value"}}
+	r.Enqueue(req)
+	r.reconcileDue(context.Background())
+
+	// A new reconciler resumes the queue, including the resolved ID and the
+	// retry state.
+	restarted, _ := newTestTagReconciler(t, binder, queueFile, func(context.Context, *TagBindingRequest) (uint64, error) {
+		t.Errorf("resolveID called for a resource with a persisted ID")
+		return 0, nil
+	})
+	queued, ok := restarted.queue[req.key()]
+	if !ok {
+		t.Fatalf("restarted queue %v does not contain %s", restarted.queue, req.key())
+	}
+	if queued.Request.ID != 42 || queued.Attempts != 1 || !reflect.DeepEqual(queued.Request.Tags, req.Tags) {
+		t.Errorf("restarted queue entry = %+v, want ID 42, 1 attempt and tags %v", queued, req.Tags)
+	}
+}
+
+func TestTagReconcilerListsCreatedResources(t *testing.T) {
+	binder := newFakeTagBinder()
+	// The second tag of the snapshot is bound to another value, e.g. by its
+	// StorageClass, which is left as it is.
+	snapshotParent := "//compute.googleapis.com/projects/project/global/snapshots/7"
+	binder.bindings[snapshotParent] = map[string]string{"parent/key2": "parent/key2/other"}
+	listed := 0
+	listCreated := func(_ context.Context, driverName string) ([]TagBindingRequest, error) {
+		listed++
+		if driverName != "driver" {
+			t.Errorf("listCreated() called for driver %q, want driver", driverName)
+		}
+		return []TagBindingRequest{
+			{Project: "project", ResourceType: SnapshotsType, Name: "snapshot", ID: 7},
+			{Project: "project", ResourceType: DisksType, Location: "zone", IsZonal: true, Name: "disk", ID: 8},
+		}, nil
+	}
+	r, err := newTagReconciler(binder, rate.NewLimiter(rate.Inf, 1), nil, listCreated, TagReconcilerConfig{
+		ResyncPeriod: time.Minute,
+		ListPeriod:   time.Hour,
+		DriverName:   "driver",
+		ExtraTags:    map[string]string{"parent/key1": "value1", "parent/key2": "value2"},
+	})
+	if err != nil {
+		t.Fatalf("newTagReconciler() failed: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.listDue(context.Background())
+	r.reconcileDue(context.Background())
+	now = now.Add(time.Minute)
+	r.reconcileDue(context.Background())
+	wantSnapshot := map[string]string{"parent/key1": "parent/key1/value1", "parent/key2": "parent/key2/other"}
+	if got := binder.bindings[snapshotParent]; !reflect.DeepEqual(got, wantSnapshot) {
+		t.Errorf("snapshot bindings = %v, want %v", got, wantSnapshot)
+	}
+	wantDisk := map[string]string{"parent/key1": "parent/key1/value1", "parent/key2": "parent/key2/value2"}
+	if got := binder.bindings["//compute.googleapis.com/projects/project/zones/zone/disks/8"]; !reflect.DeepEqual(got, wantDisk) {
+		t.Errorf("disk bindings = %v, want %v", got, wantDisk)
+	}
+	if r.Len() != 0 {
+		t.Errorf("queue length = %d after verification, want 0", r.Len())
+	}
+
+	// Resources are listed again after the list period.
+	r.listDue(context.Background())
+	if listed != 1 {
+		t.Errorf("listed %d times before the list period, want 1", listed)
+	}
+	if wakeup := r.nextWakeup(); wakeup != time.Minute {
+		t.Errorf("nextWakeup() = %v, want %v", wakeup, time.Minute)
+	}
+	now = now.Add(time.Hour)
+	r.listDue(context.Background())
+	if listed != 2 {
+		t.Errorf("listed %d times after the list period, want 2", listed)
+	}
+}
+
+func TestTagReconcilerListedTagsDoNotOverrideRequestedTags(t *testing.T) {
+	r, _ := newTestTagReconciler(t, newFakeTagBinder(), "", nil)
+	r.Enqueue(TagBindingRequest{Project: "project", ResourceType: ImagesType, Name: "image", Tags: map[string]string{"parent/key": "requested"}})
+	r.mux.Lock()
+	r.enqueueLocked(TagBindingRequest{Project: "project", ResourceType: ImagesType, Name: "image", Tags: map[string]string{"parent/key": "extra", "parent/other": "extra"}, AddOnly: true})
+	queued := r.queue["projects/project//images/image"].Request
+	r.mux.Unlock()
+	want := map[string]string{"parent/key": "requested", "parent/other": "extra"}
+	if !reflect.DeepEqual(queued.Tags, want) || queued.AddOnly {
+		t.Errorf("queued request = %+v, want tags %v and not add only", queued, want)
+	}
+}
+
+func TestTagBindingRequestForSelfLink(t *testing.T) {
+	testCases := []struct {
+		selfLink     string
+		resourceType ResourceType
+		want         TagBindingRequest
+		wantErr      bool
+	}{
+		{
+			selfLink:     "https://compute.googleapis.com/compute/v1/projects/p/zones/us-central1-a/disks/d",
+			resourceType: DisksType,
+			want:         TagBindingRequest{Project: "p", ResourceType: DisksType, Location: "us-central1-a", IsZonal: true, Name: "d", ID: 1},
+		},
+		{
+			selfLink:     "https://compute.googleapis.com/compute/v1/projects/p/regions/us-central1/disks/d",
+			resourceType: DisksType,
+			want:         TagBindingRequest{Project: "p", ResourceType: DisksType, Location: "us-central1", Name: "d", ID: 1},
+		},
+		{
+			selfLink:     "https://compute.googleapis.com/compute/v1/projects/p/global/snapshots/s",
+			resourceType: SnapshotsType,
+			want:         TagBindingRequest{Project: "p", ResourceType: SnapshotsType, Name: "s", ID: 1},
+		},
+		{
+			selfLink:     "https://compute.googleapis.com/compute/v1/projects/p/global/images/s",
+			resourceType: SnapshotsType,
+			wantErr:      true,
+		},
+	}
+	for _, tc := range testCases {
+		got, err := tagBindingRequestForSelfLink(tc.resourceType, tc.selfLink, 1)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("tagBindingRequestForSelfLink(%s) got error %v, want error %v", tc.selfLink, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("tagBindingRequestForSelfLink(%s) = %+v, want %+v", tc.selfLink, got, tc.want)
+		}
+	}
+}
@@ -25,7 +25,10 @@ type TenantsInformer interface {
 // TenantLifecycleHandler defines callbacks for tenant lifecycle events.
 // TenantMetadata should be the struct returned by GetMetadataFromTenantCR.
 type TenantLifecycleHandler struct {
-	AddFunc    func(tenantMeta *Metadata, zone string) (*computev1.Service, error)
+	AddFunc func(tenantMeta *Metadata, zone string) (*computev1.Service, error)
+	// UpdateFunc is optional and is called whenever an existing Tenant CR is
+	// updated or resynced, e.g. to pick up changes to the tenant limits.
+	UpdateFunc func(tenantMeta *Metadata)
 	DeleteFunc func(tenantMeta *Metadata)
 }
 
@@ -78,7 +81,6 @@ func RegisterTenantEventHandlers(ti TenantsInformer, handler TenantLifecycleHand
 			defer mutex.Unlock()
 			if _, ok := tenantServiceMap[tenantMeta.ProjectNumber]; ok {
 				klog.Infof("Tenant GCE client already exists for tenant project number %s, skipping GCE client instantiation.", tenantMeta.ProjectNumber)
-				mutex.Unlock()
 				return
 			}
 
@@ -93,6 +95,17 @@ func RegisterTenantEventHandlers(ti TenantsInformer, handler TenantLifecycleHand
 				klog.Infof("Successfully processed AddFunc for tenant %s (project %s) and updated service map.", tenantMeta.TenantName, tenantMeta.ProjectNumber)
 			}
 		},
+		UpdateFunc: func(_, newObj any) {
+			if handler.UpdateFunc == nil {
+				return
+			}
+			tenantMeta, err := GetMetadataFromTenantCR(newObj)
+			if err != nil {
+				klog.Errorf("Error extracting tenant metadata from CR on update: %v", err)
+				return
+			}
+			handler.UpdateFunc(&tenantMeta)
+		},
 		DeleteFunc: func(obj any) {
 			klog.Infof("Tenant CR deleted: %v", obj)
 			tenantMeta, err := GetMetadataFromTenantCR(obj)
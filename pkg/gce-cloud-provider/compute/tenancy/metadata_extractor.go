@@ -18,6 +18,12 @@ const (
 	// All K8s objects that belong to a tenant must have this label.
 	// The value of this label is the tenant name.
 	TenantNameLabelKey = "tenancy.gke.io/tenant"
+
+	// TenantDiskLabelKey is the GCE label key used to attribute a disk to a
+	// tenant. GCE label keys cannot contain '.' or '/', so this is the
+	// sanitized form of TenantProjectLabelKey. The value uses the same
+	// `t<tenant_project_number>` format.
+	TenantDiskLabelKey = "tenancy-gke-io-project"
 )
 
 // MetadataResolver is a helper class that resolves multitenancy-related
@@ -46,6 +52,38 @@ type Metadata struct {
 	// Name of a tenant that a k8s object belongs to. If the object doesn't belong
 	// to a tenant, this will be the cluster name.
 	TenantName string
+	// Limits are the optional persistent disk guardrails configured on the
+	// Tenant CR. The zero value places no restrictions on the tenant.
+	Limits Limits
+}
+
+// Limits contains the persistent disk guardrails for a tenant, read from
+// spec.persistentDiskLimits of the Tenant CR.
+type Limits struct {
+	// MaxDisks is the maximum number of disks the tenant may own. Zero means
+	// unlimited.
+	MaxDisks int64
+	// MaxTotalGiB is the maximum provisioned capacity, in GiB, summed over all
+	// disks the tenant owns. Zero means unlimited.
+	MaxTotalGiB int64
+	// AllowedDiskTypes is the set of disk types the tenant may use. Empty
+	// means any disk type is allowed.
+	AllowedDiskTypes []string
+	// AllowedKMSKeys is the set of KMS keys the tenant may encrypt disks with.
+	// Empty means any key (or none) is allowed.
+	AllowedKMSKeys []string
+	// MaxDiskIOPS is the maximum IOPS provisioned for each disk of the tenant.
+	// Zero means unlimited.
+	MaxDiskIOPS int64
+	// MaxDiskThroughputMiBps is the maximum throughput, in MiB/s, provisioned
+	// for each disk of the tenant. Zero means unlimited.
+	MaxDiskThroughputMiBps int64
+}
+
+// IsEmpty returns true if no limit is configured.
+func (l Limits) IsEmpty() bool {
+	return l.MaxDisks == 0 && l.MaxTotalGiB == 0 && len(l.AllowedDiskTypes) == 0 && len(l.AllowedKMSKeys) == 0 &&
+		l.MaxDiskIOPS == 0 && l.MaxDiskThroughputMiBps == 0
 }
 
 // NewMetadataResolver creates a new MetadataResolver.
@@ -137,12 +175,66 @@ func GetMetadataFromTenantCR(obj interface{}) (Metadata, error) {
 	if !ok {
 		return Metadata{}, fmt.Errorf("failed to cast spec.projectNumber of type %T to int64", spec["project_number"])
 	}
+	limits, err := getLimitsFromTenantSpec(spec)
+	if err != nil {
+		return Metadata{}, err
+	}
 	return Metadata{
 		ProjectNumber: fmt.Sprintf("%d", projectNumber),
 		TenantName:    unstructuredObj.GetName(),
+		Limits:        limits,
 	}, nil
 }
 
+// getLimitsFromTenantSpec parses the optional spec.persistentDiskLimits field
+// of a Tenant CR.
+func getLimitsFromTenantSpec(spec map[string]interface{}) (Limits, error) {
+	limits := Limits{}
+	if _, ok := spec["persistentDiskLimits"]; !ok {
+		return limits, nil
+	}
+	var err error
+	if limits.MaxDisks, _, err = unstructured.NestedInt64(spec, "persistentDiskLimits", "maxDisks"); err != nil {
+		return Limits{}, fmt.Errorf("failed to parse spec.persistentDiskLimits.maxDisks: %w", err)
+	}
+	if limits.MaxTotalGiB, _, err = unstructured.NestedInt64(spec, "persistentDiskLimits", "maxTotalGiB"); err != nil {
+		return Limits{}, fmt.Errorf("failed to parse spec.persistentDiskLimits.maxTotalGiB: %w", err)
+	}
+	if limits.AllowedDiskTypes, _, err = unstructured.NestedStringSlice(spec, "persistentDiskLimits", "allowedDiskTypes"); err != nil {
+		return Limits{}, fmt.Errorf("failed to parse spec.persistentDiskLimits.allowedDiskTypes: %w", err)
+	}
+	if limits.AllowedKMSKeys, _, err = unstructured.NestedStringSlice(spec, "persistentDiskLimits", "allowedKmsKeys"); err != nil {
+		return Limits{}, fmt.Errorf("failed to parse spec.persistentDiskLimits.allowedKmsKeys: %w", err)
+	}
+	if limits.MaxDiskIOPS, _, err = unstructured.NestedInt64(spec, "persistentDiskLimits", "maxDiskIops"); err != nil {
+		return Limits{}, fmt.Errorf("failed to parse spec.persistentDiskLimits.maxDiskIops: %w", err)
+	}
+	if limits.MaxDiskThroughputMiBps, _, err = unstructured.NestedInt64(spec, "persistentDiskLimits", "maxDiskThroughputMiBps"); err != nil {
+		return Limits{}, fmt.Errorf("failed to parse spec.persistentDiskLimits.maxDiskThroughputMiBps: %w", err)
+	}
+	if limits.MaxDisks < 0 || limits.MaxTotalGiB < 0 || limits.MaxDiskIOPS < 0 || limits.MaxDiskThroughputMiBps < 0 {
+		return Limits{}, fmt.Errorf("spec.persistentDiskLimits must not be negative, got maxDisks=%d maxTotalGiB=%d maxDiskIops=%d maxDiskThroughputMiBps=%d",
+			limits.MaxDisks, limits.MaxTotalGiB, limits.MaxDiskIOPS, limits.MaxDiskThroughputMiBps)
+	}
+	return limits, nil
+}
+
+// GetTenantProjectNumberFromDiskLabels returns the tenant project number a
+// GCE disk is attributed to through TenantDiskLabelKey. The boolean is false
+// if the disk is not attributed to a tenant.
+func GetTenantProjectNumberFromDiskLabels(labels map[string]string) (string, bool) {
+	return parseTenantProjectNumber(labels[TenantDiskLabelKey])
+}
+
+// parseTenantProjectNumber returns the project number of a label value in the
+// `t<tenant_project_number>` format.
+func parseTenantProjectNumber(v string) (string, bool) {
+	if len(v) < 2 || v[0] != 't' {
+		return "", false
+	}
+	return v[1:], true
+}
+
 func (m *Metadata) Equal(c Metadata) bool {
 	if m.ProjectNumber != c.ProjectNumber {
 		return false
@@ -172,6 +172,54 @@ spec:
 	}
 }
 
+func TestGetMetadataFromTenantCRWithLimits(t *testing.T) {
+	tenantCRYaml := `
+apiVersion: tenancy.gke.io/v1
+kind: Tenant
+metadata:
+  name: t123123456456-default
+spec:
+  projectNumber: 123123456456
+  persistentDiskLimits:
+    maxDisks: 10
+    maxTotalGiB: 1000
+    allowedDiskTypes:
+    - pd-balanced
+    - hyperdisk-balanced
+    allowedKmsKeys:
+    - projects/p/locations/l/keyRings/r/cryptoKeys/k
+    maxDiskIops: 20000
+    maxDiskThroughputMiBps: 600
+`
+	var tenantCR unstructured.Unstructured
+	err := yaml.Unmarshal([]byte(tenantCRYaml), &tenantCR)
+	if err != nil {
+		t.Fatalf("Failed to convert %s to unstructured.Unstructured: %v", tenantCRYaml, err)
+	}
+
+	got, err := GetMetadataFromTenantCR(&tenantCR)
+	if err != nil {
+		t.Fatalf("GetMetadataFromTenantCR(%v) failed: %v", tenantCR, err)
+	}
+	want := Metadata{
+		TenantName:    "t123123456456-default",
+		ProjectNumber: "123123456456",
+		Limits: Limits{
+			MaxDisks:         10,
+			MaxTotalGiB:      1000,
+			AllowedDiskTypes: []string{"pd-balanced", "hyperdisk-balanced"},
+			AllowedKMSKeys:   []string{"projects/p/locations/l/keyRings/r/cryptoKeys/k"},
+
+			MaxDiskIOPS:            20000,
+			MaxDiskThroughputMiBps: 600,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetMetadataFromTenantCR(%v) returned diff (-want/+got):\n%s", tenantCR, diff)
+	}
+}
+
 func TestGetMetadataFromTenantCR_Errors(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -201,6 +249,32 @@ func TestGetMetadataFromTenantCR_Errors(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "spec.persistentDiskLimits.maxDisks is not an int64",
+			tenantCR: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"projectNumber": int64(1234),
+						"persistentDiskLimits": map[string]interface{}{
+							"maxDisks": "wrong-type",
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "spec.persistentDiskLimits.maxTotalGiB is negative",
+			tenantCR: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"projectNumber": int64(1234),
+						"persistentDiskLimits": map[string]interface{}{
+							"maxTotalGiB": int64(-1),
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "spec.projectNumber is not an int64",
 			tenantCR: &unstructured.Unstructured{
@@ -296,3 +370,39 @@ func TestMetadataIsEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTenantProjectNumberFromDiskLabels(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		labels map[string]string
+		want   string
+		wantOk bool
+	}{
+		{
+			desc:   "valid label",
+			labels: map[string]string{TenantDiskLabelKey: "t1234"},
+			want:   "1234",
+			wantOk: true,
+		},
+		{
+			desc:   "missing prefix",
+			labels: map[string]string{TenantDiskLabelKey: "1234"},
+		},
+		{
+			desc:   "no project number",
+			labels: map[string]string{TenantDiskLabelKey: "t"},
+		},
+		{
+			desc:   "no label",
+			labels: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, ok := GetTenantProjectNumberFromDiskLabels(tc.labels)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("GetTenantProjectNumberFromDiskLabels(%v) = (%q, %v), want (%q, %v)", tc.labels, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
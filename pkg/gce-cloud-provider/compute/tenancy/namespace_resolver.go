@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceClient gets Namespaces, as the Namespaces client of client-go.
+type NamespaceClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Namespace, error)
+}
+
+// NamespaceResolver resolves the tenant that the K8s objects of a namespace
+// belong to from the tenancy labels of the namespace.
+type NamespaceResolver struct {
+	namespaces NamespaceClient
+}
+
+// NewNamespaceResolver creates a new NamespaceResolver.
+func NewNamespaceResolver(namespaces NamespaceClient) *NamespaceResolver {
+	return &NamespaceResolver{namespaces: namespaces}
+}
+
+// GetTenantProjectNumber returns the tenant project number of the namespace.
+// The boolean is false if the namespace does not belong to a tenant.
+func (r *NamespaceResolver) GetTenantProjectNumber(ctx context.Context, namespace string) (string, bool, error) {
+	ns, err := r.namespaces.Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	if _, ok := ns.Labels[TenantNameLabelKey]; !ok {
+		return "", false, nil
+	}
+	projectNumber, ok := parseTenantProjectNumber(ns.Labels[TenantProjectLabelKey])
+	if !ok {
+		return "", false, fmt.Errorf("namespace %s has an invalid %s label %q", namespace, TenantProjectLabelKey, ns.Labels[TenantProjectLabelKey])
+	}
+	return projectNumber, true, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeNamespaceClient map[string]map[string]string
+
+func (c fakeNamespaceClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.Namespace, error) {
+	labels, ok := c[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, name)
+	}
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}, nil
+}
+
+func TestNamespaceResolverGetTenantProjectNumber(t *testing.T) {
+	resolver := NewNamespaceResolver(fakeNamespaceClient{
+		"tenant":  {TenantNameLabelKey: "tenant-a", TenantProjectLabelKey: "t987987987"},
+		"cluster": {},
+		"invalid": {TenantNameLabelKey: "tenant-a", TenantProjectLabelKey: "987987987"},
+	})
+	testCases := []struct {
+		name          string
+		namespace     string
+		expected      string
+		expectedFound bool
+		expectErr     bool
+	}{
+		{
+			name:          "Namespace of a tenant: returns tenant project number",
+			namespace:     "tenant",
+			expected:      "987987987",
+			expectedFound: true,
+		},
+		{
+			name:      "Namespace without tenant labels: returns not found",
+			namespace: "cluster",
+		},
+		{
+			name:      "Namespace with an invalid tenant project label: returns error",
+			namespace: "invalid",
+			expectErr: true,
+		},
+		{
+			name:      "Missing namespace: returns error",
+			namespace: "missing",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found, err := resolver.GetTenantProjectNumber(context.Background(), tc.namespace)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("GetTenantProjectNumber() returned error %v, expected error: %v", err, tc.expectErr)
+			}
+			if got != tc.expected || found != tc.expectedFound {
+				t.Errorf("GetTenantProjectNumber() = (%q, %v), expected (%q, %v)", got, found, tc.expected, tc.expectedFound)
+			}
+		})
+	}
+}
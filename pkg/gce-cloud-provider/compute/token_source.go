@@ -17,14 +17,20 @@ limitations under the License.
 package gcecloudprovider
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
 
 	"golang.org/x/oauth2"
@@ -37,9 +43,41 @@ const (
 	tokenURLQPS = .05 // back off to once every 20 seconds when failing
 	// Maximum burst of requests to token URL before limiting.
 	tokenURLBurst = 3
+
+	// impersonatedTokenLifetime is the lifetime requested for impersonated
+	// access tokens. One hour is the maximum allowed by default.
+	impersonatedTokenLifetime = time.Hour
+
+	// tokenRefreshMargin is how long before expiry a token is refreshed.
+	tokenRefreshMargin = 5 * time.Minute
+	// tokenRefreshJitter is the maximum additional margin, as a fraction of
+	// tokenRefreshMargin, so that replicas do not refresh at the same time.
+	tokenRefreshJitter = 0.5
+	// maxTokenRefreshMargin is the largest margin, with jitter, at which a
+	// proactiveTokenSource refreshes. Caching sources must consider tokens
+	// stale by then, or a refresh would return the same token.
+	maxTokenRefreshMargin = tokenRefreshMargin + time.Duration(tokenRefreshJitter*float64(tokenRefreshMargin))
+	// tokenRefreshRetryInterval is how long a proactiveTokenSource waits
+	// before asking its source again after a failed refresh, or one returning
+	// the same token. It is longer than the throttling of AltTokenSource.
+	tokenRefreshRetryInterval = 30 * time.Second
+
+	// Token source names used as the token_source metric label.
+	tokenSourceAlt             = "token-url"
+	tokenSourceCredentialsFile = "credentials-file"
+	tokenSourceDefault         = "default"
+	tokenSourceImpersonated    = "impersonated"
+	tokenSourceTenant          = "tenant"
 )
 
-// TODO(#276) add metrics around token requests once the driver integrates with Prometheus.
+var tokenRefreshErrorsMetric = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "csidriver",
+		Name:           "token_refresh_errors",
+		Help:           "Failures to refresh the access token used for GCE API requests",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"token_source"})
 
 // AltTokenSource is the structure holding the data for the functionality needed to generates tokens
 type AltTokenSource struct {
@@ -83,14 +121,19 @@ func (a *AltTokenSource) token() (*oauth2.Token, error) {
 
 // NewAltTokenSource constructs a new alternate token source for generating tokens.
 func NewAltTokenSource(tokenURL, tokenBody string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, newAltTokenSource(tokenURL, tokenBody))
+}
+
+// newAltTokenSource constructs an alternate token source that fetches a new
+// token on every call, for wrapping in a proactiveTokenSource.
+func newAltTokenSource(tokenURL, tokenBody string) *AltTokenSource {
 	client := oauth2.NewClient(oauth2.NoContext, google.ComputeTokenSource(""))
-	a := &AltTokenSource{
+	return &AltTokenSource{
 		oauthClient: client,
 		tokenURL:    tokenURL,
 		tokenBody:   tokenBody,
 		throttle:    flowcontrol.NewTokenBucketRateLimiter(tokenURLQPS, tokenURLBurst),
 	}
-	return oauth2.ReuseTokenSource(nil, a)
 }
 
 func NewTenantTokenSource(tenantMeta *tenancy.Metadata, region, existingTokenURL, existingTokenBody string) (oauth2.TokenSource, error) {
@@ -102,7 +145,7 @@ func NewTenantTokenSource(tenantMeta *tenancy.Metadata, region, existingTokenURL
 	if err != nil {
 		return nil, err
 	}
-	return NewAltTokenSource(tenantTokenUrl, tenantTokenBody), nil
+	return newProactiveTokenSource(newAltTokenSource(tenantTokenUrl, tenantTokenBody), tokenSourceTenant), nil
 }
 
 func getTenantTokenURL(tenantMeta *tenancy.Metadata, existingTokenURL string) (string, error) {
@@ -173,3 +216,168 @@ func getTenantTokenBody(tenantMeta *tenancy.Metadata, existingTokenBody string)
 
 	return string(newTokenBodyBytes), nil
 }
+
+// ImpersonatedTokenSource generates access tokens for a target service
+// account using the IAM Credentials API, authenticated with a base token
+// source.
+type ImpersonatedTokenSource struct {
+	oauthClient     *http.Client
+	endpoint        string
+	targetPrincipal string
+	delegates       []string
+	scopes          []string
+}
+
+// NewImpersonatedTokenSource constructs a token source that impersonates
+// targetPrincipal through the (optional) chain of delegates, using the IAM
+// Credentials API at endpoint.
+func NewImpersonatedTokenSource(ctx context.Context, base oauth2.TokenSource, endpoint string, targetPrincipal string, delegates []string, scopes ...string) oauth2.TokenSource {
+	return &ImpersonatedTokenSource{
+		oauthClient:     oauth2.NewClient(ctx, base),
+		endpoint:        endpoint,
+		targetPrincipal: targetPrincipal,
+		delegates:       delegates,
+		scopes:          scopes,
+	}
+}
+
+// Token returns an access token for the target principal.
+func (i *ImpersonatedTokenSource) Token() (*oauth2.Token, error) {
+	delegates := make([]string, 0, len(i.delegates))
+	for _, d := range i.delegates {
+		delegates = append(delegates, "projects/-/serviceAccounts/"+d)
+	}
+	body, err := json.Marshal(struct {
+		Delegates []string `json:"delegates,omitempty"`
+		Scope     []string `json:"scope"`
+		Lifetime  string   `json:"lifetime"`
+	}{
+		Delegates: delegates,
+		Scope:     i.scopes,
+		Lifetime:  fmt.Sprintf("%.0fs", impersonatedTokenLifetime.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:generateAccessToken", i.endpoint, i.targetPrincipal)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := i.oauthClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if err := googleapi.CheckResponse(res); err != nil {
+		return nil, fmt.Errorf("failed to impersonate service account %s: %w", i.targetPrincipal, err)
+	}
+	var tok struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tok); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: tok.AccessToken,
+		Expiry:      tok.ExpireTime,
+	}, nil
+}
+
+// proactiveTokenSource caches a token and refreshes it ahead of its expiry,
+// with jitter, instead of waiting for requests to fail. Refresh failures are
+// recorded in tokenRefreshErrorsMetric, and the cached token keeps being
+// served for as long as it is valid. Failed refreshes, and ones returning the
+// same token, are retried after tokenRefreshRetryInterval rather than on
+// every call.
+//
+// The source must not cache tokens for longer than maxTokenRefreshMargin
+// before their expiry, and the proactiveTokenSource must be the outermost
+// source of the HTTP client (see newOauthClient), otherwise refreshes are
+// served from another cache.
+type proactiveTokenSource struct {
+	mux       sync.Mutex
+	source    oauth2.TokenSource
+	name      string
+	token     *oauth2.Token
+	refreshAt time.Time
+	// refreshErr is the error of the last refresh, returned until refreshAt
+	// if there is no valid token to serve.
+	refreshErr error
+
+	// Overridden in tests.
+	now    func() time.Time
+	jitter func() float64
+}
+
+func newProactiveTokenSource(source oauth2.TokenSource, name string) *proactiveTokenSource {
+	return &proactiveTokenSource{
+		source: source,
+		name:   name,
+		now:    time.Now,
+		jitter: rand.Float64,
+	}
+}
+
+// Token returns the cached token, refreshing it first if it is within the
+// refresh margin of its expiry.
+func (p *proactiveTokenSource) Token() (*oauth2.Token, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	now := p.now()
+	valid := p.token != nil && (p.token.Expiry.IsZero() || now.Before(p.token.Expiry))
+	// Tokens without expiry never need to be refreshed.
+	if p.token != nil && p.token.Expiry.IsZero() {
+		return p.token, nil
+	}
+	if now.Before(p.refreshAt) {
+		if valid {
+			return p.token, nil
+		}
+		if p.refreshErr != nil {
+			return nil, p.refreshErr
+		}
+	}
+
+	token, err := p.source.Token()
+	if err != nil {
+		tokenRefreshErrorsMetric.WithLabelValues(p.name).Inc()
+		p.retryAt(now)
+		if valid {
+			klog.Warningf("Failed to refresh %s token, using cached token until it expires at %v: %v", p.name, p.token.Expiry, err)
+			return p.token, nil
+		}
+		p.refreshErr = err
+		return nil, err
+	}
+	p.refreshErr = nil
+	if p.token != nil && token.AccessToken == p.token.AccessToken {
+		// A caching source may still be refreshing the token in the
+		// background; ask again later.
+		p.token = token
+		p.retryAt(now)
+		return token, nil
+	}
+
+	p.token = token
+	margin := tokenRefreshMargin + time.Duration(p.jitter()*tokenRefreshJitter*float64(tokenRefreshMargin))
+	p.refreshAt = token.Expiry.Add(-margin)
+	if !p.refreshAt.After(now) {
+		// The new token is already within the refresh margin.
+		p.retryAt(now)
+	}
+	klog.V(4).Infof("Refreshed %s token, next refresh at %v", p.name, p.refreshAt)
+	return token, nil
+}
+
+// retryAt sets the next refresh after tokenRefreshRetryInterval, or at the
+// expiry of the cached token if it is sooner.
+func (p *proactiveTokenSource) retryAt(now time.Time) {
+	p.refreshAt = now.Add(tokenRefreshRetryInterval)
+	if p.token != nil && !p.token.Expiry.IsZero() && p.token.Expiry.After(now) && p.token.Expiry.Before(p.refreshAt) {
+		p.refreshAt = p.token.Expiry
+	}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcecloudprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenRefreshErrors returns the token_refresh_errors count for the named
+// token source.
+func tokenRefreshErrors(t *testing.T, name string) float64 {
+	t.Helper()
+	m := gatherMetric(t, "csidriver_token_refresh_errors", map[string]string{"token_source": name})
+	return m.GetCounter().GetValue()
+}
+
+type countingTokenSource struct {
+	calls  int
+	expiry time.Time
+	err    error
+	cached bool
+}
+
+func (c *countingTokenSource) Token() (*oauth2.Token, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.cached {
+		return &oauth2.Token{AccessToken: "access-1", Expiry: c.expiry}, nil
+	}
+	return &oauth2.Token{AccessToken: fmt.Sprintf("access-%d", c.calls), Expiry: c.expiry}, nil
+}
+
+func TestProactiveTokenSource(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := start.Add(time.Hour)
+	testCases := []struct {
+		name       string
+		jitter     float64
+		elapsed    time.Duration
+		expiry     time.Time
+		refreshErr error
+		cached     bool
+		// keepExpiry makes the source issue new tokens with the old expiry.
+		keepExpiry bool
+		wantCalls  int
+		// wantRetryCalls is the number of calls to the source once
+		// tokenRefreshRetryInterval elapsed.
+		wantRetryCalls int
+		wantErr        bool
+	}{
+		{
+			name:           "token is cached well before expiry",
+			elapsed:        30 * time.Minute,
+			expiry:         expiry,
+			wantCalls:      1,
+			wantRetryCalls: 1,
+		},
+		{
+			name:           "token is refreshed within the refresh margin",
+			elapsed:        time.Hour - tokenRefreshMargin,
+			expiry:         expiry,
+			wantCalls:      2,
+			wantRetryCalls: 2,
+		},
+		{
+			name:           "jitter refreshes earlier than the refresh margin",
+			jitter:         1,
+			elapsed:        time.Hour - tokenRefreshMargin - time.Minute,
+			expiry:         expiry,
+			wantCalls:      2,
+			wantRetryCalls: 2,
+		},
+		{
+			name:           "same token from a caching source is refreshed again later",
+			elapsed:        time.Hour - tokenRefreshMargin,
+			expiry:         expiry,
+			cached:         true,
+			wantCalls:      2,
+			wantRetryCalls: 3,
+		},
+		{
+			name:           "new token already within the refresh margin is refreshed again later",
+			elapsed:        time.Hour - tokenRefreshMargin,
+			expiry:         expiry,
+			keepExpiry:     true,
+			wantCalls:      2,
+			wantRetryCalls: 3,
+		},
+		{
+			name:           "token without expiry is never refreshed",
+			elapsed:        24 * time.Hour,
+			wantCalls:      1,
+			wantRetryCalls: 1,
+		},
+		{
+			name:           "cached token is served while valid if refresh fails",
+			elapsed:        time.Hour - time.Minute,
+			expiry:         expiry,
+			refreshErr:     errors.New("refresh failed"),
+			wantCalls:      2,
+			wantRetryCalls: 3,
+		},
+		{
+			name:           "refresh failure is returned once the token expired",
+			elapsed:        time.Hour + time.Minute,
+			expiry:         expiry,
+			refreshErr:     errors.New("refresh failed"),
+			wantCalls:      2,
+			wantRetryCalls: 3,
+			wantErr:        true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := tokenRefreshErrors(t, "test")
+			source := &countingTokenSource{expiry: tc.expiry}
+			now := start
+			ts := newProactiveTokenSource(source, "test")
+			ts.now = func() time.Time { return now }
+			ts.jitter = func() float64 { return tc.jitter }
+
+			if _, err := ts.Token(); err != nil {
+				t.Fatalf("Token() failed: %v", err)
+			}
+			now = start.Add(tc.elapsed)
+			source.err = tc.refreshErr
+			source.cached = tc.cached
+			if !tc.cached && !tc.keepExpiry && !tc.expiry.IsZero() {
+				// Fresh tokens expire an hour after they are issued.
+				source.expiry = tc.expiry.Add(tc.elapsed)
+			}
+			// Calls right after a refresh, or a failed one, do not ask the
+			// source again.
+			for i := 0; i < 2; i++ {
+				if _, err := ts.Token(); (err != nil) != tc.wantErr {
+					t.Errorf("Token() got error %v, want error %v", err, tc.wantErr)
+				}
+			}
+			if source.calls != tc.wantCalls {
+				t.Errorf("Token() called the source %d times, want %d", source.calls, tc.wantCalls)
+			}
+			now = now.Add(tokenRefreshRetryInterval)
+			if _, err := ts.Token(); (err != nil) != tc.wantErr {
+				t.Errorf("Token() after the retry interval got error %v, want error %v", err, tc.wantErr)
+			}
+			if source.calls != tc.wantRetryCalls {
+				t.Errorf("Token() after the retry interval called the source %d times, want %d", source.calls, tc.wantRetryCalls)
+			}
+			wantFailures := float64(tc.wantRetryCalls - 1)
+			if tc.refreshErr == nil {
+				wantFailures = 0
+			}
+			if failures := tokenRefreshErrors(t, "test") - before; failures != wantFailures {
+				t.Errorf("token_refresh_errors = %v, want %v", failures, wantFailures)
+			}
+		})
+	}
+}
+
+func TestImpersonatedTokenSource(t *testing.T) {
+	expireTime := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/-/serviceAccounts/target@p.iam.gserviceaccount.com:generateAccessToken" {
+			http.Error(w, "unexpected path "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer access" {
+			http.Error(w, "unexpected authorization "+got, http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Delegates []string `json:"delegates"`
+			Scope     []string `json:"scope"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body.Delegates) != 1 || body.Delegates[0] != "projects/-/serviceAccounts/delegate@p.iam.gserviceaccount.com" {
+			http.Error(w, "unexpected delegates", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"accessToken": "impersonated",
+			"expireTime":  expireTime,
+		})
+	}))
+	defer srv.Close()
+
+	ts := NewImpersonatedTokenSource(context.Background(), &mockTokenSource{}, "", "target@p.iam.gserviceaccount.com", []string{"delegate@p.iam.gserviceaccount.com"}, "scope").(*ImpersonatedTokenSource)
+	ts.endpoint = srv.URL
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() failed: %v", err)
+	}
+	if token.AccessToken != "impersonated" || !token.Expiry.Equal(expireTime) {
+		t.Errorf("Token() = %+v, want access token %q expiring at %v", token, "impersonated", expireTime)
+	}
+}
+
+func TestGenerateTokenSourceFromCredentialsFile(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials.json")
+	externalAccount := map[string]interface{}{
+		"type":               "external_account",
+		"audience":           "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+		"subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":          "https://sts.googleapis.com/v1/token",
+		"credential_source": map[string]interface{}{
+			"file": filepath.Join(dir, "token"),
+		},
+	}
+	data, err := json.Marshal(externalAccount)
+	if err != nil {
+		t.Fatalf("Failed to marshal credentials: %v", err)
+	}
+	if err := os.WriteFile(credentialsFile, data, 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		config   ConfigGlobal
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "external account credentials file",
+			config:   ConfigGlobal{CredentialsFile: credentialsFile},
+			wantName: tokenSourceCredentialsFile,
+		},
+		{
+			name: "impersonation with external account credentials file",
+			config: ConfigGlobal{
+				CredentialsFile:           credentialsFile,
+				ImpersonateServiceAccount: "target@p.iam.gserviceaccount.com",
+			},
+			wantName: tokenSourceImpersonated,
+		},
+		{
+			name:    "missing credentials file",
+			config:  ConfigGlobal{CredentialsFile: filepath.Join(dir, "missing.json")},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configFile := &ConfigFile{Global: tc.config}
+			endpoints, err := newAPIEndpoints(configFile, nil)
+			if err != nil {
+				t.Fatalf("newAPIEndpoints() failed: %v", err)
+			}
+			ts, err := generateTokenSource(context.Background(), configFile, endpoints)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("generateTokenSource() got error %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			pts, ok := ts.(*proactiveTokenSource)
+			if !ok {
+				t.Fatalf("generateTokenSource() returned %T, want *proactiveTokenSource", ts)
+			}
+			if pts.name != tc.wantName {
+				t.Errorf("generateTokenSource() returned token source %q, want %q", pts.name, tc.wantName)
+			}
+		})
+	}
+}
+
+func TestOauthClientUsesTokenSourceDirectly(t *testing.T) {
+	source := &countingTokenSource{expiry: time.Now().Add(time.Hour)}
+	var gotAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client, err := newOauthClient(context.Background(), source)
+	if err != nil {
+		t.Fatalf("newOauthClient() failed: %v", err)
+	}
+	for range 2 {
+		res, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		res.Body.Close()
+	}
+	// Each request must get its token from the source rather than from a
+	// cache in the client, so the source decides when to refresh.
+	want := []string{"Bearer access-2", "Bearer access-3"}
+	if len(gotAuth) != len(want) || gotAuth[0] != want[0] || gotAuth[1] != want[1] {
+		t.Errorf("requests used tokens %v, want %v", gotAuth, want)
+	}
+}
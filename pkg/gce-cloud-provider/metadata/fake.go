@@ -25,6 +25,7 @@ var (
 	FakeZone        = "country-region-zone"
 	FakeProject     = "test-project"
 	FakeName        = "test-name"
+	FakeInstanceID  = "1234567890"
 )
 
 func NewFakeService() MetadataService {
@@ -47,6 +48,10 @@ func (manager *fakeServiceManager) GetMachineType() string {
 	return FakeMachineType
 }
 
+func (manager *fakeServiceManager) GetInstanceID() string {
+	return FakeInstanceID
+}
+
 func SetMachineType(s string) {
 	FakeMachineType = s
 }
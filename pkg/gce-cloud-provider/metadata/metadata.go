@@ -30,6 +30,9 @@ type MetadataService interface {
 	GetProject() string
 	GetName() string
 	GetMachineType() string
+	// GetInstanceID returns the numeric ID of the instance, which changes
+	// when the instance is recreated with the same name.
+	GetInstanceID() string
 }
 
 type metadataServiceManager struct {
@@ -38,6 +41,7 @@ type metadataServiceManager struct {
 	project     string
 	name        string
 	machineType string
+	instanceID  string
 }
 
 var _ MetadataService = &metadataServiceManager{}
@@ -59,6 +63,10 @@ func NewMetadataService() (MetadataService, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get machine-type: %w", err)
 	}
+	instanceID, err := metadata.InstanceID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance id: %w", err)
+	}
 	// Response format: "projects/[NUMERIC_PROJECT_ID]/machineTypes/[MACHINE_TYPE]"
 	splits := strings.Split(fullMachineType, "/")
 	machineType := splits[len(splits)-1]
@@ -68,6 +76,7 @@ func NewMetadataService() (MetadataService, error) {
 		zone:        zone,
 		name:        name,
 		machineType: machineType,
+		instanceID:  instanceID,
 	}, nil
 }
 
@@ -86,3 +95,7 @@ func (manager *metadataServiceManager) GetName() string {
 func (manager *metadataServiceManager) GetMachineType() string {
 	return manager.machineType
 }
+
+func (manager *metadataServiceManager) GetInstanceID() string {
+	return manager.instanceID
+}
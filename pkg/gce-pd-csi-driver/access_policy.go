@@ -0,0 +1,154 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"slices"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+)
+
+// VolumeAccessPolicy restricts the disks and instances the controller
+// operates on. Volume handles of static PVs and node IDs are not trusted:
+// without a policy, they may point at any project the service account of the
+// driver can reach. A nil policy, or an empty list, allows everything.
+type VolumeAccessPolicy struct {
+	// AllowedProjects are the projects of the disks and instances.
+	AllowedProjects []string
+	// AllowedZones and AllowedRegions are the locations of the disks and
+	// instances. If either is set, a zonal location is allowed if its zone or
+	// its region is listed, and a regional location if its region or a zone
+	// of it is listed.
+	AllowedZones   []string
+	AllowedRegions []string
+	// RequiredDiskLabels are labels the disks must have, with the same
+	// values.
+	RequiredDiskLabels map[string]string
+}
+
+// checkVolume returns PermissionDenied if the disk of volKey in project is
+// not allowed. The unspecified location of an underspecified volume key is
+// not checked, the volume must be checked again once its key is repaired.
+func (p *VolumeAccessPolicy) checkVolume(project string, volKey *meta.Key) error {
+	if p == nil {
+		return nil
+	}
+	if !p.projectAllowed(project) {
+		return status.Errorf(codes.PermissionDenied, "volume project %s is not allowed", project)
+	}
+	switch {
+	case volKey.Type() == meta.Zonal && volKey.Zone == common.MultiZoneValue:
+		// The zone of multi-zone volumes is the zone of the node, checked
+		// once it is known.
+	case volKey.Type() == meta.Zonal && volKey.Zone == common.UnspecifiedValue:
+	case volKey.Type() == meta.Regional && volKey.Region == common.UnspecifiedValue:
+	case volKey.Type() == meta.Zonal && !p.zoneAllowed(volKey.Zone):
+		return status.Errorf(codes.PermissionDenied, "volume zone %s is not allowed", volKey.Zone)
+	case volKey.Type() == meta.Regional && !p.regionAllowed(volKey.Region):
+		return status.Errorf(codes.PermissionDenied, "volume region %s is not allowed", volKey.Region)
+	}
+	return nil
+}
+
+// checkNode returns PermissionDenied if the instance of nodeID is not
+// allowed.
+func (p *VolumeAccessPolicy) checkNode(nodeID string) error {
+	if p == nil {
+		return nil
+	}
+	project, err := common.NodeIDToProject(nodeID)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "node ID is invalid: %v", err.Error())
+	}
+	zone, _, err := common.NodeIDToZoneAndName(nodeID)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "node ID is invalid: %v", err.Error())
+	}
+	if !p.projectAllowed(project) {
+		return status.Errorf(codes.PermissionDenied, "node project %s is not allowed", project)
+	}
+	if !p.zoneAllowed(zone) {
+		return status.Errorf(codes.PermissionDenied, "node zone %s is not allowed", zone)
+	}
+	return nil
+}
+
+// checkSnapshot returns PermissionDenied if the snapshot or image of
+// snapshotID, e.g. the source of a volume, is not allowed. Invalid IDs are
+// left to the caller.
+func (p *VolumeAccessPolicy) checkSnapshot(snapshotID string) error {
+	if p == nil {
+		return nil
+	}
+	project, _, _, err := common.SnapshotIDToProjectKey(snapshotID)
+	if err != nil {
+		return nil
+	}
+	if !p.projectAllowed(project) {
+		return status.Errorf(codes.PermissionDenied, "snapshot project %s is not allowed", project)
+	}
+	return nil
+}
+
+// checkDisk returns PermissionDenied if disk is missing a required label.
+func (p *VolumeAccessPolicy) checkDisk(disk *gce.CloudDisk) error {
+	if p == nil || disk == nil {
+		return nil
+	}
+	labels := disk.GetLabels()
+	for key, value := range p.RequiredDiskLabels {
+		if got, ok := labels[key]; !ok || got != value {
+			return status.Errorf(codes.PermissionDenied, "disk %s does not have the required label %s=%s", disk.GetName(), key, value)
+		}
+	}
+	return nil
+}
+
+func (p *VolumeAccessPolicy) projectAllowed(project string) bool {
+	return len(p.AllowedProjects) == 0 || slices.Contains(p.AllowedProjects, project)
+}
+
+func (p *VolumeAccessPolicy) zoneAllowed(zone string) bool {
+	if len(p.AllowedZones) == 0 && len(p.AllowedRegions) == 0 {
+		return true
+	}
+	if slices.Contains(p.AllowedZones, zone) {
+		return true
+	}
+	region, err := common.GetRegionFromZones([]string{zone})
+	return err == nil && slices.Contains(p.AllowedRegions, region)
+}
+
+func (p *VolumeAccessPolicy) regionAllowed(region string) bool {
+	if len(p.AllowedZones) == 0 && len(p.AllowedRegions) == 0 {
+		return true
+	}
+	if slices.Contains(p.AllowedRegions, region) {
+		return true
+	}
+	for _, zone := range p.AllowedZones {
+		if zoneRegion, err := common.GetRegionFromZones([]string{zone}); err == nil && zoneRegion == region {
+			return true
+		}
+	}
+	return false
+}
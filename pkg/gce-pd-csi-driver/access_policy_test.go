@@ -0,0 +1,270 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+)
+
+func TestVolumeAccessPolicyCheckVolume(t *testing.T) {
+	policy := &VolumeAccessPolicy{
+		AllowedProjects: []string{"allowed-project"},
+		AllowedZones:    []string{"us-east1-b"},
+		AllowedRegions:  []string{"us-central1"},
+	}
+	testCases := []struct {
+		name       string
+		policy     *VolumeAccessPolicy
+		project    string
+		volKey     *meta.Key
+		expErrCode codes.Code
+	}{
+		{
+			name:       "no policy",
+			project:    "other-project",
+			volKey:     meta.ZonalKey("disk", "europe-west1-b"),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "project not allowed",
+			policy:     policy,
+			project:    "other-project",
+			volKey:     meta.ZonalKey("disk", "us-east1-b"),
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "allowed zone",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.ZonalKey("disk", "us-east1-b"),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "zone of an allowed region",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.ZonalKey("disk", "us-central1-a"),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "zone not allowed",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.ZonalKey("disk", "us-east1-c"),
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "region of an allowed zone",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.RegionalKey("disk", "us-east1"),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "region not allowed",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.RegionalKey("disk", "europe-west1"),
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "multi-zone volume",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.ZonalKey("disk", common.MultiZoneValue),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "unspecified zone",
+			policy:     policy,
+			project:    "allowed-project",
+			volKey:     meta.ZonalKey("disk", common.UnspecifiedValue),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "unspecified zone in a project not allowed",
+			policy:     policy,
+			project:    "other-project",
+			volKey:     meta.ZonalKey("disk", common.UnspecifiedValue),
+			expErrCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.checkVolume(tc.project, tc.volKey)
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Errorf("checkVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+		})
+	}
+}
+
+func TestVolumeAccessPolicyCheckNodeAndDisk(t *testing.T) {
+	policy := &VolumeAccessPolicy{
+		AllowedProjects:    []string{"allowed-project"},
+		AllowedRegions:     []string{"us-central1"},
+		RequiredDiskLabels: map[string]string{"team": "storage"},
+	}
+	for nodeID, want := range map[string]codes.Code{
+		common.CreateNodeID("allowed-project", "us-central1-a", "node"): codes.OK,
+		common.CreateNodeID("other-project", "us-central1-a", "node"):   codes.PermissionDenied,
+		common.CreateNodeID("allowed-project", "us-east1-b", "node"):    codes.PermissionDenied,
+		"malformed": codes.InvalidArgument,
+	} {
+		if got := status.Code(policy.checkNode(nodeID)); got != want {
+			t.Errorf("checkNode(%q) got error code %v, want %v", nodeID, got, want)
+		}
+	}
+	for labels, want := range map[string]codes.Code{
+		"team=storage":          codes.OK,
+		"team=storage,env=prod": codes.OK,
+		"team=compute":          codes.PermissionDenied,
+		"":                      codes.PermissionDenied,
+	} {
+		labelsMap, _ := common.ConvertLabelsStringToMap(labels)
+		disk := gce.CloudDiskFromV1(&compute.Disk{Name: "disk", Labels: labelsMap})
+		if got := status.Code(policy.checkDisk(disk)); got != want {
+			t.Errorf("checkDisk() of a disk with labels %q got error code %v, want %v", labels, got, want)
+		}
+	}
+}
+
+func TestVolumeAccessPolicyCheckSnapshot(t *testing.T) {
+	policy := &VolumeAccessPolicy{AllowedProjects: []string{"allowed-project"}}
+	for snapshotID, want := range map[string]codes.Code{
+		"projects/allowed-project/global/snapshots/snapshot": codes.OK,
+		"projects/allowed-project/global/images/image":       codes.OK,
+		"projects/other-project/global/snapshots/snapshot":   codes.PermissionDenied,
+		"projects/other-project/global/images/image":         codes.PermissionDenied,
+		"malformed": codes.OK,
+	} {
+		if got := status.Code(policy.checkSnapshot(snapshotID)); got != want {
+			t.Errorf("checkSnapshot(%q) got error code %v, want %v", snapshotID, got, want)
+		}
+	}
+}
+
+func TestControllerAccessPolicy(t *testing.T) {
+	policy := &VolumeAccessPolicy{
+		AllowedProjects:    []string{project},
+		AllowedZones:       []string{zone},
+		RequiredDiskLabels: map[string]string{"team": "storage"},
+	}
+	disks := []*gce.CloudDisk{
+		gce.CloudDiskFromV1(&compute.Disk{Name: "labeled", Labels: map[string]string{"team": "storage"}}),
+		gce.CloudDiskFromV1(&compute.Disk{Name: "unlabeled"}),
+	}
+	testCases := []struct {
+		name       string
+		volumeID   string
+		expErrCode codes.Code
+	}{
+		{
+			name:       "allowed volume",
+			volumeID:   fmt.Sprintf("projects/%s/zones/%s/disks/labeled", project, zone),
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "volume in another project",
+			volumeID:   fmt.Sprintf("projects/other-project/zones/%s/disks/labeled", zone),
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "volume without the required labels",
+			volumeID:   fmt.Sprintf("projects/%s/zones/%s/disks/unlabeled", project, zone),
+			expErrCode: codes.PermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gceDriver := initGCEDriver(t, disks, &GCEControllerServerArgs{AccessPolicy: policy})
+			_, err := gceDriver.cs.ControllerExpandVolume(context.Background(), &csi.ControllerExpandVolumeRequest{
+				VolumeId:      tc.volumeID,
+				CapacityRange: &csi.CapacityRange{RequiredBytes: common.GbToBytes(20)},
+			})
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Errorf("ControllerExpandVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+			_, err = gceDriver.cs.CreateSnapshot(context.Background(), &csi.CreateSnapshotRequest{
+				Name:           "snapshot",
+				SourceVolumeId: tc.volumeID,
+			})
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Errorf("CreateSnapshot() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+		})
+	}
+
+	// Underspecified volumes are checked in the default project.
+	gceDriver := initGCEDriver(t, disks, &GCEControllerServerArgs{AccessPolicy: policy})
+	_, err := gceDriver.cs.ControllerExpandVolume(context.Background(), &csi.ControllerExpandVolumeRequest{
+		VolumeId:      fmt.Sprintf("projects/%s/zones/%s/disks/labeled", common.UnspecifiedValue, zone),
+		CapacityRange: &csi.CapacityRange{RequiredBytes: common.GbToBytes(20)},
+	})
+	if err != nil {
+		t.Errorf("ControllerExpandVolume() of an underspecified volume failed: %v", err)
+	}
+
+	// Nodes in other projects are denied before the instance is looked up.
+	_, err = gceDriver.cs.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+		VolumeId: fmt.Sprintf("projects/%s/zones/%s/disks/labeled", project, zone),
+		NodeId:   common.CreateNodeID("other-project", zone, "node"),
+		VolumeCapability: &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("ControllerPublishVolume() to a node in another project got error code %v, want PermissionDenied: %v", got, err)
+	}
+
+	// Labels are not required to detach disks.
+	_, err = gceDriver.cs.ControllerUnpublishVolume(context.Background(), &csi.ControllerUnpublishVolumeRequest{
+		VolumeId: fmt.Sprintf("projects/%s/zones/%s/disks/unlabeled", project, zone),
+		NodeId:   common.CreateNodeID(project, zone, "node"),
+	})
+	if got := status.Code(err); got == codes.PermissionDenied {
+		t.Errorf("ControllerUnpublishVolume() of a disk without the required labels got error code %v: %v", got, err)
+	}
+
+	// Volumes are not restored from snapshots in other projects.
+	_, err = gceDriver.cs.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name:               "restored",
+		CapacityRange:      &csi.CapacityRange{RequiredBytes: common.GbToBytes(20)},
+		VolumeCapabilities: []*csi.VolumeCapability{stdVolCap},
+		VolumeContentSource: &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Snapshot{
+				Snapshot: &csi.VolumeContentSource_SnapshotSource{
+					SnapshotId: "projects/other-project/global/snapshots/snapshot",
+				},
+			},
+		},
+	})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("CreateVolume() from a snapshot in another project got error code %v, want PermissionDenied: %v", got, err)
+	}
+}
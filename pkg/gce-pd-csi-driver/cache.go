@@ -573,6 +573,24 @@ func isCachingSetup(mainLvName string) (error, bool) {
 	return nil, false
 }
 
+// dataCacheHealthStatus returns the LVM health status of the main logical
+// volume of volumeId, e.g. "partial" if a local SSD of the cache is missing,
+// or "" if it is healthy or not cached.
+func dataCacheHealthStatus(volumeId string) (string, error) {
+	args := []string{
+		"--select",
+		"lv_name=" + getLvName(mainLvSuffix, volumeId),
+		"-o",
+		"lv_health_status",
+		"--noheadings",
+	}
+	info, err := common.RunCommand("" /* pipedCmd */, nil /* pipedCmdArg */, "lvs", args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the health status of the data cache of %s %w: %s", volumeId, err, info)
+	}
+	return strings.TrimSpace(string(info)), nil
+}
+
 func fetchChunkSizeKiB(cacheSize string) (string, error) {
 	var chunkSize float64
 
@@ -21,6 +21,7 @@ import (
 	"math/rand"
 	neturl "net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +48,10 @@ type GCEControllerServer struct {
 	CloudProvider gce.GCECompute
 	Metrics       metrics.MetricsManager
 
+	// The pages of ListVolumes and ListSnapshots are kept in memory, so all
+	// pages of a listing must be requested from the same replica. The
+	// sidecars calling them, e.g. the leader elected csi-attacher, only call
+	// the replica they run with.
 	volumeEntries     []*csi.ListVolumesResponse_Entry
 	volumeEntriesSeen map[string]int
 
@@ -54,9 +59,10 @@ type GCEControllerServer struct {
 	snapshotTokens map[string]int
 
 	// A map storing all volumes with ongoing operations so that additional
-	// operations for that same volume (as defined by Volume Key) return an
-	// Aborted error
-	volumeLocks *common.VolumeLocks
+	// operations for that same volume (as defined by Volume Key) wait for it,
+	// up to the configured wait, then return an Aborted error. The locks are
+	// shared by the replicas of the controller if they are Lease based.
+	volumeLocks *common.QueuedVolumeLocks
 
 	// There are several kinds of errors that are immediately retried by either
 	// the CSI sidecars or the k8s control plane. The retries consume GCP api
@@ -122,10 +128,40 @@ type GCEControllerServer struct {
 	csi.UnimplementedControllerServer
 
 	EnableDiskTopology bool
+
+	// accessPolicy restricts the disks and instances of the volume handles
+	// and node IDs of requests.
+	accessPolicy *VolumeAccessPolicy
+
+	// If set to true, the node IDs of ListVolumes are qualified with the
+	// numeric ID of the instance, like the node IDs reported by the nodes.
+	nodeIDIncludesInstanceID bool
+
+	// tenantResolver resolves the tenant of the disks created in multi-tenant
+	// mode, it is nil otherwise.
+	tenantResolver TenantResolver
+	tenantLocks    tenantLocks
 }
 
 type GCEControllerServerArgs struct {
 	EnableDiskTopology bool
+	// VolumeLocks are the volume locks of the controller. If nil, the locks
+	// are in-process, and only one replica of the controller may run. Shared
+	// locks allow the sidecars of different replicas to be leaders, but each
+	// sidecar must still be leader elected: ListVolumes pagination and the
+	// error backoff are kept per replica.
+	VolumeLocks common.VolumeLocker
+	// VolumeLockWait configures waiting for the lock of a volume.
+	VolumeLockWait common.VolumeLockWaitConfig
+	// AccessPolicy restricts the disks and instances the controller operates
+	// on. If nil, all are allowed.
+	AccessPolicy *VolumeAccessPolicy
+	// NodeIDIncludesInstanceID must be set if the nodes qualify their node ID
+	// with the numeric ID of their instance.
+	NodeIDIncludesInstanceID bool
+	// TenantResolver must be set in multi-tenant mode, to attribute the
+	// disks created to the tenant of the namespace of their PVC.
+	TenantResolver TenantResolver
 }
 
 type MultiZoneVolumeHandleConfig struct {
@@ -221,12 +257,13 @@ const (
 )
 
 var (
-	validResourceApiVersions = map[string]bool{"v1": true, "alpha": true, "beta": true, "staging_v1": true, "staging_beta": true, "staging_alpha": true}
+	validResourceApiVersions = sets.New(gce.ComputeAPIVersionPaths()...)
 
 	// By default GCE returns a lot of data for each instance. Request only a subset of the fields.
 	listInstancesFields = []googleapi.Field{
 		"items/disks/deviceName",
 		"items/disks/source",
+		"items/id",
 		"items/selfLink",
 		"nextPageToken",
 	}
@@ -319,6 +356,9 @@ func (gceCS *GCEControllerServer) createVolumeInternal(ctx context.Context, req
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "failed to extract parameters: %v", err.Error())
 	}
+	if err := gceCS.attributeDiskToTenant(ctx, req.GetParameters(), &params); err != nil {
+		return nil, err
+	}
 
 	// Validate arguments
 	volumeCapabilities := req.GetVolumeCapabilities()
@@ -485,10 +525,12 @@ func (gceCS *GCEControllerServer) createMultiZoneDisk(ctx context.Context, req *
 	if err != nil {
 		return nil, err
 	}
-	if acquired := gceCS.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, volumeID, "CreateVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer gceCS.volumeLocks.Release(volumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, volumeID)
+	defer cancel()
 
 	// If creating an empty disk (content source nil), always create RWO disks (when supported)
 	// This allows disks to be created as underlying RWO disks, so they can be hydrated.
@@ -618,10 +660,12 @@ func (gceCS *GCEControllerServer) createSingleDeviceDisk(ctx context.Context, re
 		accessMode = common.GCEReadWriteOnceAccessMode
 	}
 
-	if acquired := gceCS.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, volumeID, "CreateVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer gceCS.volumeLocks.Release(volumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, volumeID)
+	defer cancel()
 
 	disk, err := gceCS.createSingleDisk(ctx, req, params, volKey, zones, accessMode)
 	if err != nil {
@@ -700,6 +744,9 @@ func (gceCS *GCEControllerServer) createSingleDisk(ctx context.Context, req *csi
 	if content != nil {
 		if content.GetSnapshot() != nil {
 			snapshotID = content.GetSnapshot().GetSnapshotId()
+			if err := gceCS.accessPolicy.checkSnapshot(snapshotID); err != nil {
+				return nil, err
+			}
 
 			// Verify that snapshot exists
 			sl, err := gceCS.getSnapshotByID(ctx, snapshotID)
@@ -717,6 +764,9 @@ func (gceCS *GCEControllerServer) createSingleDisk(ctx context.Context, req *csi
 			if err != nil {
 				return nil, status.Errorf(codes.InvalidArgument, "CreateVolume source volume id is invalid: %v", err.Error())
 			}
+			if err := gceCS.accessPolicy.checkVolume(project, sourceVolKey); err != nil {
+				return nil, err
+			}
 
 			// Verify that the volume in VolumeContentSource exists.
 			diskFromSourceVolume, err := gceCS.CloudProvider.GetDisk(ctx, project, sourceVolKey)
@@ -727,6 +777,9 @@ func (gceCS *GCEControllerServer) createSingleDisk(ctx context.Context, req *csi
 					return nil, common.LoggedError("CreateVolume, getDisk error when validating: ", err)
 				}
 			}
+			if err := gceCS.accessPolicy.checkDisk(diskFromSourceVolume); err != nil {
+				return nil, err
+			}
 			// Verify the disk type and encryption key of the clone are the same as that of the source disk.
 			if diskFromSourceVolume.GetPDType() != params.DiskType || !gce.KmsKeyEqual(diskFromSourceVolume.GetKMSKeyName(), params.DiskEncryptionKMSKey) {
 				return nil, status.Errorf(codes.InvalidArgument, "CreateVolume Parameters %v do not match source volume Parameters", params)
@@ -769,6 +822,13 @@ func (gceCS *GCEControllerServer) createSingleDisk(ctx context.Context, req *csi
 		}
 	}
 
+	tenantOpStarted, releaseTenant, err := gceCS.validateTenantLimitsForCreate(ctx, params, capBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseTenant()
+	ctx = gce.WithOperationStarted(ctx, tenantOpStarted)
+
 	// Create the disk
 	var disk *gce.CloudDisk
 	name := req.GetName()
@@ -839,6 +899,9 @@ func (gceCS *GCEControllerServer) ControllerModifyVolume(ctx context.Context, re
 		err = status.Errorf(codes.NotFound, "volume ID is invalid: %v", err.Error())
 		return nil, err
 	}
+	if err := gceCS.accessPolicy.checkVolume(project, volKey); err != nil {
+		return nil, err
+	}
 
 	volumeModifyParams, err := common.ExtractModifyVolumeParameters(req.GetMutableParameters())
 	if err != nil {
@@ -860,6 +923,9 @@ func (gceCS *GCEControllerServer) ControllerModifyVolume(ctx context.Context, re
 		err = status.Errorf(codes.Internal, "failed to get volume : %s", volumeID)
 		return nil, err
 	}
+	if err := gceCS.accessPolicy.checkDisk(existingDisk); err != nil {
+		return nil, err
+	}
 
 	// Check if the disk supports dynamic IOPS/Throughput provisioning
 	diskType := existingDisk.GetPDType()
@@ -878,6 +944,10 @@ func (gceCS *GCEControllerServer) ControllerModifyVolume(ctx context.Context, re
 		return nil, err
 	}
 
+	if err = gceCS.validateTenantLimitsForModify(existingDisk, volumeModifyParams); err != nil {
+		return nil, err
+	}
+
 	err = gceCS.CloudProvider.UpdateDisk(ctx, project, volKey, existingDisk, volumeModifyParams)
 	if err != nil {
 		klog.Errorf("Failed to modify volume %s: %v", volumeID, err)
@@ -932,10 +1002,12 @@ func (gceCS *GCEControllerServer) deleteMultiZoneDisk(ctx context.Context, req *
 	}
 
 	volumeID := req.GetVolumeId()
-	if acquired := gceCS.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, volumeID, "DeleteVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer gceCS.volumeLocks.Release(volumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, volumeID)
+	defer cancel()
 
 	deleteDiskErrs := []error{}
 	for _, zone := range zones {
@@ -973,10 +1045,12 @@ func (gceCS *GCEControllerServer) deleteSingleDeviceDisk(ctx context.Context, re
 		return nil, common.LoggedError("DeleteVolume error repairing underspecified volume key: ", err)
 	}
 
-	if acquired := gceCS.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, volumeID, "DeleteVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer gceCS.volumeLocks.Release(volumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, volumeID)
+	defer cancel()
 	disk, _ := gceCS.CloudProvider.GetDisk(ctx, project, volKey)
 	metrics.UpdateRequestMetadataFromDisk(ctx, disk)
 	err = gceCS.CloudProvider.DeleteDisk(ctx, project, volKey)
@@ -1101,6 +1175,31 @@ func isMultiZoneVolKey(volumeKey *meta.Key) bool {
 	return volumeKey.Type() == meta.Zonal && volumeKey.Zone == common.MultiZoneValue
 }
 
+// instanceMatchesNodeID returns false if nodeID is qualified with an instance
+// ID other than the ID of instance, i.e. if the instance of the node was
+// recreated with the same name.
+func instanceMatchesNodeID(nodeID string, instance *compute.Instance) bool {
+	instanceID, err := common.NodeIDToInstanceID(nodeID)
+	if err != nil || instanceID == "" {
+		return true
+	}
+	return instanceID == strconv.FormatUint(instance.Id, 10)
+}
+
+// nodeIDWithInstanceID qualifies nodeID with the numeric ID of its instance,
+// as done by the nodes.
+func nodeIDWithInstanceID(nodeID string, instanceID uint64) (string, error) {
+	project, err := common.NodeIDToProject(nodeID)
+	if err != nil {
+		return "", err
+	}
+	zone, name, err := common.NodeIDToZoneAndName(nodeID)
+	if err != nil {
+		return "", err
+	}
+	return common.CreateNodeIDWithInstanceID(project, zone, name, strconv.FormatUint(instanceID, 10)), nil
+}
+
 func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Context, req *csi.ControllerPublishVolumeRequest) (*csi.ControllerPublishVolumeResponse, error, *gce.CloudDisk) {
 	project, volKey, pdcsiContext, err := gceCS.validateControllerPublishVolumeRequest(ctx, req)
 	if err != nil {
@@ -1140,6 +1239,12 @@ func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Con
 		volKey = convertMultiZoneVolKeyToZoned(volKey, instanceZone)
 	}
 
+	if err := gceCS.checkVolumeAccess(project, volKey); err != nil {
+		return nil, err, nil
+	}
+	if err := gceCS.accessPolicy.checkNode(nodeID); err != nil {
+		return nil, err, nil
+	}
 	project, volKey, err = gceCS.CloudProvider.RepairUnderspecifiedVolumeKey(ctx, project, volKey)
 	if err != nil {
 		if gce.IsGCENotFoundError(err) {
@@ -1147,14 +1252,19 @@ func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Con
 		}
 		return nil, common.LoggedError("ControllerPublishVolume error repairing underspecified volume key: ", err), nil
 	}
+	if err := gceCS.accessPolicy.checkVolume(project, volKey); err != nil {
+		return nil, err, nil
+	}
 
 	// Acquires the lock for the volume on that node only, because we need to support the ability
 	// to publish the same volume onto different nodes concurrently
 	lockingVolumeID := fmt.Sprintf("%s/%s", nodeID, volumeID)
-	if acquired := gceCS.volumeLocks.TryAcquire(lockingVolumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, lockingVolumeID, "ControllerPublishVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, lockingVolumeID), nil
 	}
 	defer gceCS.volumeLocks.Release(lockingVolumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, lockingVolumeID)
+	defer cancel()
 	disk, err := gceCS.CloudProvider.GetDisk(ctx, project, volKey)
 	if err != nil {
 		if gce.IsGCENotFoundError(err) {
@@ -1162,6 +1272,10 @@ func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Con
 		}
 		return nil, common.LoggedError("Failed to getDisk: ", err), disk
 	}
+	if err := gceCS.accessPolicy.checkDisk(disk); err != nil {
+		return nil, err, disk
+	}
+	pubVolResp.PublishContext = addDiskPublishContext(pubVolResp.PublishContext, disk)
 	instance, err := gceCS.CloudProvider.GetInstanceOrError(ctx, project, instanceZone, instanceName)
 	if err != nil {
 		if gce.IsGCENotFoundError(err) {
@@ -1169,6 +1283,9 @@ func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Con
 		}
 		return nil, common.LoggedError("Failed to get instance: ", err), disk
 	}
+	if !instanceMatchesNodeID(nodeID, instance) {
+		return nil, status.Errorf(codes.NotFound, "Could not find instance %v: instance %s was recreated with ID %d", nodeID, instanceName, instance.Id), disk
+	}
 
 	if gceCS.multiZoneVolumeHandleConfig.Enable && volumeIsMultiZone {
 		if err := gceCS.validateMultiZoneDisk(volumeID, disk); err != nil {
@@ -1202,7 +1319,7 @@ func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Con
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "could not split nodeID: %v", err.Error()), disk
 	}
-	err = gceCS.CloudProvider.AttachDisk(ctx, project, volKey, readWrite, attachableDiskTypePersistent, instanceZone, instanceName, pdcsiContext.ForceAttach)
+	err = gceCS.CloudProvider.AttachDisk(ctx, project, volKey, readWrite, attachableDiskTypePersistent, instanceZone, instanceName, instance.Fingerprint, pdcsiContext.ForceAttach)
 	if err != nil {
 		var udErr *gce.UnsupportedDiskError
 		if errors.As(err, &udErr) {
@@ -1223,6 +1340,24 @@ func (gceCS *GCEControllerServer) executeControllerPublishVolume(ctx context.Con
 	return pubVolResp, nil, disk
 }
 
+// addDiskPublishContext adds the type and the provisioned performance of disk
+// to publishContext, so that the node can tune the volume for the disk type.
+func addDiskPublishContext(publishContext map[string]string, disk *gce.CloudDisk) map[string]string {
+	if publishContext == nil {
+		publishContext = map[string]string{}
+	}
+	if diskType := disk.GetPDType(); diskType != "" {
+		publishContext[common.ContextDiskType] = diskType
+	}
+	if iops := disk.GetProvisionedIops(); iops > 0 {
+		publishContext[common.ContextProvisionedIOPS] = strconv.FormatInt(iops, 10)
+	}
+	if throughput := disk.GetProvisionedThroughput(); throughput > 0 {
+		publishContext[common.ContextProvisionedThroughput] = strconv.FormatInt(throughput, 10)
+	}
+	return publishContext
+}
+
 func (gceCS *GCEControllerServer) ControllerUnpublishVolume(ctx context.Context, req *csi.ControllerUnpublishVolumeRequest) (*csi.ControllerUnpublishVolumeResponse, error) {
 	var err error
 	_, _, err = gceCS.validateControllerUnpublishVolumeRequest(ctx, req)
@@ -1285,6 +1420,12 @@ func (gceCS *GCEControllerServer) executeControllerUnpublishVolume(ctx context.C
 		volKey = convertMultiZoneVolKeyToZoned(volKey, instanceZone)
 	}
 
+	if err := gceCS.checkVolumeAccess(project, volKey); err != nil {
+		return nil, err, nil
+	}
+	if err := gceCS.accessPolicy.checkNode(nodeID); err != nil {
+		return nil, err, nil
+	}
 	project, volKey, err = gceCS.CloudProvider.RepairUnderspecifiedVolumeKey(ctx, project, volKey)
 	if err != nil {
 		if gce.IsGCENotFoundError(err) {
@@ -1293,14 +1434,21 @@ func (gceCS *GCEControllerServer) executeControllerUnpublishVolume(ctx context.C
 		}
 		return nil, common.LoggedError("ControllerUnpublishVolume error repairing underspecified volume key: ", err), nil
 	}
+	if err := gceCS.accessPolicy.checkVolume(project, volKey); err != nil {
+		return nil, err, nil
+	}
 
 	// Acquires the lock for the volume on that node only, because we need to support the ability
 	// to unpublish the same volume from different nodes concurrently
 	lockingVolumeID := fmt.Sprintf("%s/%s", nodeID, volumeID)
-	if acquired := gceCS.volumeLocks.TryAcquire(lockingVolumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, lockingVolumeID, "ControllerUnpublishVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, lockingVolumeID), nil
 	}
 	defer gceCS.volumeLocks.Release(lockingVolumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, lockingVolumeID)
+	defer cancel()
+	// The labels of the disk are not required: detaching must not fail for
+	// disks published before the labels were required.
 	diskToUnpublish, _ := gceCS.CloudProvider.GetDisk(ctx, project, volKey)
 	instance, err := gceCS.CloudProvider.GetInstanceOrError(ctx, project, instanceZone, instanceName)
 	if err != nil {
@@ -1311,6 +1459,11 @@ func (gceCS *GCEControllerServer) executeControllerUnpublishVolume(ctx context.C
 		}
 		return nil, common.LoggedError("error getting instance: ", err), diskToUnpublish
 	}
+	if !instanceMatchesNodeID(nodeID, instance) {
+		// The instance of the node was recreated, which detached its disks.
+		klog.Warningf("Treating volume %v as unpublished because node %v was recreated with instance ID %d", volKey.String(), nodeID, instance.Id)
+		return &csi.ControllerUnpublishVolumeResponse{}, nil, diskToUnpublish
+	}
 
 	deviceName, err := common.GetDeviceName(volKey)
 	if err != nil {
@@ -1324,7 +1477,7 @@ func (gceCS *GCEControllerServer) executeControllerUnpublishVolume(ctx context.C
 		klog.V(4).Infof("ControllerUnpublishVolume succeeded for disk %v from node %v. Already not attached.", volKey, nodeID)
 		return &csi.ControllerUnpublishVolumeResponse{}, nil, diskToUnpublish
 	}
-	err = gceCS.CloudProvider.DetachDisk(ctx, project, deviceName, instanceZone, instanceName)
+	err = gceCS.CloudProvider.DetachDisk(ctx, project, deviceName, instanceZone, instanceName, instance.Fingerprint)
 	if err != nil {
 		return nil, common.LoggedError("Failed to detach: ", err), diskToUnpublish
 	}
@@ -1365,7 +1518,7 @@ func (gceCS *GCEControllerServer) ValidateVolumeCapabilities(ctx context.Context
 		return nil, common.LoggedError("ValidateVolumeCapabilities error repairing underspecified volume key: ", err)
 	}
 
-	if acquired := gceCS.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, volumeID, "ValidateVolumeCapabilities"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer gceCS.volumeLocks.Release(volumeID)
@@ -1421,13 +1574,21 @@ func generateFailedValidationMessage(format string, a ...interface{}) *csi.Valid
 }
 
 func (gceCS *GCEControllerServer) listVolumeEntries(ctx context.Context) ([]*csi.ListVolumesResponse_Entry, error) {
-	diskList, _, err := gceCS.CloudProvider.ListDisks(ctx, gceCS.listVolumesConfig.listDisksFields())
+	// Listing volumes gives way to attach and detach calls when the compute
+	// API budget is tight.
+	ctx = gce.WithAPIPriority(ctx, gce.APIPriorityLow)
+	listVolumesConfig := gceCS.listVolumesConfig
+	if gceCS.nodeIDIncludesInstanceID {
+		// The users of the disks do not include the IDs of the instances.
+		listVolumesConfig.UseInstancesAPIForPublishedNodes = true
+	}
+	diskList, _, err := gceCS.CloudProvider.ListDisks(ctx, listVolumesConfig.listDisksFields())
 	if err != nil {
 		return nil, err
 	}
 
 	var instanceList []*compute.Instance = nil
-	if gceCS.listVolumesConfig.UseInstancesAPIForPublishedNodes {
+	if listVolumesConfig.UseInstancesAPIForPublishedNodes {
 		instanceList, _, err = gceCS.CloudProvider.ListInstances(ctx, listInstancesFields)
 		if err != nil {
 			return nil, err
@@ -1546,6 +1707,12 @@ func (gceCS *GCEControllerServer) disksAndInstancesToVolumeEntries(disks []*comp
 			klog.Warningf("Bad ListVolumes instance resource %s, skipped: %v (%+v)", instance.SelfLink, err, instance)
 			continue
 		}
+		if gceCS.nodeIDIncludesInstanceID {
+			if instanceId, err = nodeIDWithInstanceID(instanceId, instance.Id); err != nil {
+				klog.Warningf("Bad ListVolumes instance resource %s, skipped: %v (%+v)", instance.SelfLink, err, instance)
+				continue
+			}
+		}
 		for _, disk := range instance.Disks {
 			volumeId, err := getResourceId(disk.Source)
 			if err != nil {
@@ -1600,16 +1767,21 @@ func (gceCS *GCEControllerServer) CreateSnapshot(ctx context.Context, req *csi.C
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "CreateSnapshot Volume ID is invalid: %v", err.Error())
 	}
+	if err := gceCS.accessPolicy.checkVolume(project, volKey); err != nil {
+		return nil, err
+	}
 
 	volumeIsMultiZone := isMultiZoneVolKey(volKey)
 	if gceCS.multiZoneVolumeHandleConfig.Enable && volumeIsMultiZone {
 		return nil, status.Errorf(codes.InvalidArgument, "CreateSnapshot for volume %v failed. Snapshots are not supported with the multi-zone PV volumeHandle feature", volumeID)
 	}
 
-	if acquired := gceCS.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := gceCS.volumeLocks.Acquire(ctx, volumeID, "CreateSnapshot"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer gceCS.volumeLocks.Release(volumeID)
+	ctx, cancel := gceCS.volumeLocks.WithLock(ctx, volumeID)
+	defer cancel()
 
 	// Check if volume exists
 	disk, err := gceCS.CloudProvider.GetDisk(ctx, project, volKey)
@@ -1620,6 +1792,9 @@ func (gceCS *GCEControllerServer) CreateSnapshot(ctx context.Context, req *csi.C
 		}
 		return nil, common.LoggedError("CreateSnapshot, failed to getDisk: ", err)
 	}
+	if err := gceCS.accessPolicy.checkDisk(disk); err != nil {
+		return nil, err
+	}
 
 	snapshotParams, err := common.ExtractAndDefaultSnapshotParameters(req.GetParameters(), gceCS.Driver.name, gceCS.Driver.extraTags)
 	if err != nil {
@@ -1943,6 +2118,9 @@ func (gceCS *GCEControllerServer) ControllerExpandVolume(ctx context.Context, re
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "ControllerExpandVolume Volume ID is invalid: %v", err.Error())
 	}
+	if err := gceCS.checkVolumeAccess(project, volKey); err != nil {
+		return nil, err
+	}
 	project, volKey, err = gceCS.CloudProvider.RepairUnderspecifiedVolumeKey(ctx, project, volKey)
 
 	if err != nil {
@@ -1951,6 +2129,9 @@ func (gceCS *GCEControllerServer) ControllerExpandVolume(ctx context.Context, re
 		}
 		return nil, common.LoggedError("ControllerExpandVolume error repairing underspecified volume key: ", err)
 	}
+	if err := gceCS.accessPolicy.checkVolume(project, volKey); err != nil {
+		return nil, err
+	}
 
 	volumeIsMultiZone := isMultiZoneVolKey(volKey)
 	if gceCS.multiZoneVolumeHandleConfig.Enable && volumeIsMultiZone {
@@ -1959,6 +2140,17 @@ func (gceCS *GCEControllerServer) ControllerExpandVolume(ctx context.Context, re
 
 	sourceDisk, err := gceCS.CloudProvider.GetDisk(ctx, project, volKey)
 	metrics.UpdateRequestMetadataFromDisk(ctx, sourceDisk)
+	if err == nil {
+		if err := gceCS.accessPolicy.checkDisk(sourceDisk); err != nil {
+			return nil, err
+		}
+		tenantOpStarted, releaseTenant, err := gceCS.validateTenantLimitsForExpand(ctx, sourceDisk, reqBytes)
+		if err != nil {
+			return nil, err
+		}
+		defer releaseTenant()
+		ctx = gce.WithOperationStarted(ctx, tenantOpStarted)
+	}
 	resizedGb, err := gceCS.CloudProvider.ResizeDisk(ctx, project, volKey, reqBytes)
 
 	if err != nil {
@@ -2017,6 +2209,16 @@ func (gceCS *GCEControllerServer) getSnapshots(ctx context.Context, req *csi.Lis
 	return entries, nil
 }
 
+// checkVolumeAccess checks a volume against the access policy before its
+// key is repaired, which calls the compute API for underspecified keys. The
+// project of an underspecified key is the default project.
+func (gceCS *GCEControllerServer) checkVolumeAccess(project string, volKey *meta.Key) error {
+	if project == common.UnspecifiedValue {
+		project = gceCS.CloudProvider.GetDefaultProject()
+	}
+	return gceCS.accessPolicy.checkVolume(project, volKey)
+}
+
 func (gceCS *GCEControllerServer) getSnapshotByID(ctx context.Context, snapshotID string) (*csi.ListSnapshotsResponse, error) {
 	project, snapshotType, key, err := common.SnapshotIDToProjectKey(snapshotID)
 	if err != nil {
@@ -2384,6 +2586,18 @@ func paramsToVolumeContext(params common.DiskParameters) map[string]string {
 	if params.ForceAttach {
 		context[contextForceAttach] = "true"
 	}
+	for fsType, options := range params.MkfsOptions {
+		context[common.MkfsOptionsKey(fsType)] = options
+	}
+	if params.FsckPolicy != "" {
+		context[common.ParameterKeyFsckPolicy] = params.FsckPolicy
+	}
+	if params.QueueSettings != "" {
+		context[common.ParameterKeyQueueSettings] = params.QueueSettings
+	}
+	if params.FstrimInterval != "" {
+		context[common.ParameterKeyFstrimInterval] = params.FstrimInterval
+	}
 	if len(context) > 0 {
 		return context
 	}
@@ -2491,7 +2705,8 @@ func getResourceId(resourceLink string) (string, error) {
 	}
 
 	// Note that the resource host can basically be anything, if we are running in
-	// a distributed cloud or trusted partner environment.
+	// a distributed cloud, trusted partner environment or another universe, or
+	// the compute endpoint is overridden in the cloud config.
 
 	// The path should be /compute/VERSION/project/....
 	elts := strings.Split(url.Path, "/")
@@ -2501,7 +2716,7 @@ func getResourceId(resourceLink string) (string, error) {
 	if elts[1] != resourceApiService {
 		return "", fmt.Errorf("bad resource service %s in %s", elts[1], resourceLink)
 	}
-	if _, ok := validResourceApiVersions[elts[2]]; !ok {
+	if !validResourceApiVersions.Has(elts[2]) {
 		return "", fmt.Errorf("bad version %s in %s", elts[2], resourceLink)
 	}
 	if elts[3] != resourceProject {
@@ -615,6 +615,24 @@ func TestCreateVolumeArguments(t *testing.T) {
 				AccessibleTopology: stdTopology,
 			},
 		},
+		{
+			name: "success with mkfs options",
+			req: &csi.CreateVolumeRequest{
+				Name:               "test-name",
+				CapacityRange:      stdCapRange,
+				VolumeCapabilities: stdVolCaps,
+				Parameters: map[string]string{
+					common.ParameterKeyType:           stdDiskType,
+					common.ParameterKeyMkfsOptionsXfs: "-m reflink=1",
+				},
+			},
+			expVol: &csi.Volume{
+				CapacityBytes:      common.GbToBytes(20),
+				VolumeId:           testVolumeID,
+				VolumeContext:      map[string]string{common.ParameterKeyMkfsOptionsXfs: "-m reflink=1"},
+				AccessibleTopology: stdTopology,
+			},
+		},
 		{
 			name: "fail with MULTI_NODE_READER_ONLY",
 			req: &csi.CreateVolumeRequest{
@@ -3058,6 +3076,50 @@ func TestListVolumeResponse(t *testing.T) {
 	}
 }
 
+func TestListVolumeNodeIDWithInstanceID(t *testing.T) {
+	for _, includeInstanceID := range []bool{false, true} {
+		t.Run(fmt.Sprintf("includeInstanceID=%v", includeInstanceID), func(t *testing.T) {
+			disk := &compute.Disk{
+				Name:     "pv-1",
+				SelfLink: fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/disks/%s", project, zone, "pv-1"),
+			}
+			fakeCloudProvider, err := gce.CreateFakeCloudProvider(project, zone, []*gce.CloudDisk{gce.CloudDiskFromV1(disk)})
+			if err != nil {
+				t.Fatalf("Failed to create fake cloud provider: %v", err)
+			}
+			fakeCloudProvider.InsertInstance(&compute.Instance{
+				Name:     "node-1",
+				Id:       1234,
+				SelfLink: fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/instances/%s", project, zone, "node-1"),
+				Disks: []*compute.AttachedDisk{
+					{
+						DeviceName: "pv-1",
+						Source:     disk.SelfLink,
+					},
+				},
+			}, zone, "node-1")
+			gceDriver := initGCEDriverWithCloudProvider(t, fakeCloudProvider, &GCEControllerServerArgs{NodeIDIncludesInstanceID: includeInstanceID})
+			// Qualified node IDs are always listed with the instances API.
+			gceDriver.cs.listVolumesConfig.UseInstancesAPIForPublishedNodes = !includeInstanceID
+
+			resp, err := gceDriver.cs.ListVolumes(context.TODO(), &csi.ListVolumesRequest{})
+			if err != nil {
+				t.Fatalf("ListVolumes unexpected error: %v", err)
+			}
+			wantNodeID := common.CreateNodeID(project, zone, "node-1")
+			if includeInstanceID {
+				wantNodeID = common.CreateNodeIDWithInstanceID(project, zone, "node-1", "1234")
+			}
+			if len(resp.Entries) != 1 {
+				t.Fatalf("ListVolumes returned %d entries, want 1", len(resp.Entries))
+			}
+			if diff := cmp.Diff([]string{wantNodeID}, resp.Entries[0].GetStatus().GetPublishedNodeIds()); diff != "" {
+				t.Errorf("ListVolumes published node IDs: -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
 func entryToVolumeId(e *csi.ListVolumesResponse_Entry) string {
 	return e.Volume.VolumeId
 }
@@ -5612,6 +5674,16 @@ func TestGetResource(t *testing.T) {
 			in:   "https://www.googleapis.com/compute/alpha/projects/projectv1/zones/zone/disks/disk",
 			want: "projects/projectv1/zones/zone/disks/disk",
 		},
+		{
+			name: "staging api",
+			in:   "https://www.googleapis.com/compute/staging_v1/projects/project/zones/zone/disks/disk",
+			want: "projects/project/zones/zone/disks/disk",
+		},
+		{
+			name: "other universe",
+			in:   "https://compute.example-universe.com/compute/v1/projects/project/zones/zone/disks/disk",
+			want: "projects/project/zones/zone/disks/disk",
+		},
 		{
 			name: "random host",
 			in:   "https://npr.org/compute/v1/projects/project/zones/zone/disks/disk",
@@ -5782,7 +5854,7 @@ func backoffDriver(t *testing.T, config *backoffDriverConfig) *GCEDriver {
 	driver.cs = &GCEControllerServer{
 		Driver:            driver,
 		volumeEntriesSeen: map[string]int{},
-		volumeLocks:       common.NewVolumeLocks(),
+		volumeLocks:       common.NewQueuedVolumeLocks(common.NewVolumeLocks(), common.VolumeLockWaitConfig{}),
 		errorBackoff:      newFakeCSIErrorBackoff(config.clock),
 	}
 
@@ -5841,3 +5913,118 @@ func mergeParameters(a map[string]string, b map[string]string) map[string]string
 	}
 	return merged
 }
+
+func TestControllerPublishUnpublishRecreatedInstance(t *testing.T) {
+	volumeID := fmt.Sprintf("projects/%s/zones/%s/disks/%s", project, zone, name)
+	currentNodeID := common.CreateNodeIDWithInstanceID(project, zone, node, "42")
+	staleNodeID := common.CreateNodeIDWithInstanceID(project, zone, node, "41")
+	volumeCapability := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+	}
+	newDriver := func(attachedDisks []*compute.AttachedDisk) (*GCEDriver, *gce.FakeCloudProvider) {
+		fcp, err := gce.CreateFakeCloudProvider(project, zone, []*gce.CloudDisk{createZonalCloudDisk(name)})
+		if err != nil {
+			t.Fatalf("Failed to create fake cloud provider: %v", err)
+		}
+		fcp.InsertInstance(&compute.Instance{Name: node, Id: 42, Disks: attachedDisks}, zone, node)
+		return initGCEDriverWithCloudProvider(t, fcp, &GCEControllerServerArgs{}), fcp
+	}
+
+	// Publishing to the node of a previous instance with the same name fails.
+	gceDriver, _ := newDriver(nil)
+	_, err := gceDriver.cs.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+		VolumeId:         volumeID,
+		NodeId:           staleNodeID,
+		VolumeCapability: volumeCapability,
+	})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("ControllerPublishVolume() to a recreated instance got error code %v, want NotFound: %v", got, err)
+	}
+	_, err = gceDriver.cs.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+		VolumeId:         volumeID,
+		NodeId:           currentNodeID,
+		VolumeCapability: volumeCapability,
+	})
+	if err != nil {
+		t.Errorf("ControllerPublishVolume() to the current instance failed: %v", err)
+	}
+
+	// Unpublishing from the node of a previous instance leaves the disk
+	// attached to the current one.
+	gceDriver, fcp := newDriver([]*compute.AttachedDisk{{DeviceName: name}})
+	_, err = gceDriver.cs.ControllerUnpublishVolume(context.Background(), &csi.ControllerUnpublishVolumeRequest{
+		VolumeId: volumeID,
+		NodeId:   staleNodeID,
+	})
+	if err != nil {
+		t.Errorf("ControllerUnpublishVolume() from a recreated instance failed: %v", err)
+	}
+	instance, err := fcp.GetInstanceOrError(context.Background(), project, zone, node)
+	if err != nil {
+		t.Fatalf("Failed to get instance: %v", err)
+	}
+	if len(instance.Disks) != 1 {
+		t.Errorf("ControllerUnpublishVolume() from a recreated instance detached the disk of the current instance")
+	}
+}
+
+func TestControllerPublishVolumeDiskPublishContext(t *testing.T) {
+	volumeID := fmt.Sprintf("projects/%s/zones/%s/disks/%s", project, zone, name)
+	nodeID := common.CreateNodeID(project, zone, node)
+	testCases := []struct {
+		name              string
+		disk              *compute.Disk
+		expPublishContext map[string]string
+	}{
+		{
+			name: "provisioned performance",
+			disk: &compute.Disk{
+				Name:                  name,
+				Type:                  fmt.Sprintf("projects/%s/zones/%s/diskTypes/hyperdisk-balanced", project, zone),
+				ProvisionedIops:       3000,
+				ProvisionedThroughput: 140,
+			},
+			expPublishContext: map[string]string{
+				common.ContextDiskType:              "hyperdisk-balanced",
+				common.ContextProvisionedIOPS:       "3000",
+				common.ContextProvisionedThroughput: "140",
+			},
+		},
+		{
+			name: "no provisioned performance",
+			disk: &compute.Disk{
+				Name: name,
+				Type: fmt.Sprintf("projects/%s/zones/%s/diskTypes/pd-balanced", project, zone),
+			},
+			expPublishContext: map[string]string{
+				common.ContextDiskType: "pd-balanced",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fcp, err := gce.CreateFakeCloudProvider(project, zone, []*gce.CloudDisk{gce.CloudDiskFromV1(tc.disk)})
+			if err != nil {
+				t.Fatalf("Failed to create fake cloud provider: %v", err)
+			}
+			fcp.InsertInstance(&compute.Instance{Name: node}, zone, node)
+			gceDriver := initGCEDriverWithCloudProvider(t, fcp, &GCEControllerServerArgs{})
+
+			resp, err := gceDriver.cs.ControllerPublishVolume(context.Background(), &csi.ControllerPublishVolumeRequest{
+				VolumeId: volumeID,
+				NodeId:   nodeID,
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+				},
+			})
+			if err != nil {
+				t.Fatalf("ControllerPublishVolume() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expPublishContext, resp.GetPublishContext()); diff != "" {
+				t.Errorf("ControllerPublishVolume() returned unexpected publish context (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+)
+
+type deviceHoldersResponse struct {
+	VolumeID   string `json:"volumeID"`
+	Device     string `json:"device"`
+	HasHolders bool   `json:"hasHolders"`
+	Summary    string `json:"summary"`
+	*deviceutils.DeviceHolders
+}
+
+// DeviceHoldersHandler returns an HTTP handler listing what still holds the
+// device of the volume of the volume_id query parameter of GET requests: its
+// mounted filesystem, its mounts in all mount namespaces, the block devices
+// built on top of it and the processes with an open handle on it.
+func (ns *GCENodeServer) DeviceHoldersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		volumeID := r.URL.Query().Get("volume_id")
+		if volumeID == "" {
+			http.Error(w, "the volume_id query parameter must be provided", http.StatusBadRequest)
+			return
+		}
+		device, holders, err := ns.deviceHolders(volumeID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		resp := deviceHoldersResponse{
+			VolumeID:      volumeID,
+			Device:        device,
+			HasHolders:    holders.HasHolders(),
+			Summary:       holders.String(),
+			DeviceHolders: holders,
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			klog.Errorf("Failed to write the device holders of volume %s: %v", volumeID, err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+// holdersDeviceUtils returns holders for the device at devicePath.
+type holdersDeviceUtils struct {
+	deviceutils.DeviceUtils
+	devicePath string
+	holders    *deviceutils.DeviceHolders
+}
+
+func (d *holdersDeviceUtils) VerifyDevicePath(devicePaths []string, deviceName string) (string, error) {
+	return d.devicePath, nil
+}
+
+func (d *holdersDeviceUtils) IsDeviceFilesystemInUse(mounter *mount.SafeFormatAndMount, devicePath, devFsPath string) (*deviceutils.DeviceHolders, error) {
+	return d.holders, nil
+}
+
+func TestConfirmDeviceUnused(t *testing.T) {
+	devicePath := filepath.Join(t.TempDir(), "sdb")
+	if err := os.WriteFile(devicePath, nil, 0o644); err != nil {
+		t.Fatalf("Failed to create %s: %v", devicePath, err)
+	}
+
+	testCases := []struct {
+		name    string
+		holders *deviceutils.DeviceHolders
+		expErr  string
+	}{
+		{
+			name:    "no holders",
+			holders: &deviceutils.DeviceHolders{},
+		},
+		{
+			name: "holders without a mounted filesystem",
+			holders: &deviceutils.DeviceHolders{
+				Holders:   []string{"dm-0"},
+				Processes: []deviceutils.ProcessHolder{{PID: 1400, Command: "udevd"}},
+			},
+		},
+		{
+			name: "mounted filesystem",
+			holders: &deviceutils.DeviceHolders{
+				FilesystemInUse: true,
+				Holders:         []string{"dm-0"},
+			},
+			expErr: "is still in use: filesystem mounted; holders: dm-0",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deviceUtils := &holdersDeviceUtils{DeviceUtils: deviceutils.NewFakeDeviceUtils(false), devicePath: devicePath, holders: tc.holders}
+			ns := getCustomTestGCEDriver(t, mountmanager.NewFakeSafeMounter(), deviceUtils, metadataservice.NewFakeService(), &NodeServerArgs{}).ns
+
+			err := ns.confirmDeviceUnused(defaultVolumeID)
+			var ignoreableErr *ignoreableError
+			if errors.As(err, &ignoreableErr) {
+				t.Fatalf("confirmDeviceUnused() failed to check the device: %v", err)
+			}
+			if tc.expErr == "" && err != nil || tc.expErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expErr)) {
+				t.Errorf("confirmDeviceUnused() returned error %v, want %q", err, tc.expErr)
+			}
+		})
+	}
+}
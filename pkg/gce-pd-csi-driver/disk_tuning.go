@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+// DiskTuningProfiles are the mount flags applied by default to the volumes of
+// each disk type, e.g. read_ahead_kb=<kb>, queue/<name>=<value> or noatime.
+// The mount flags of the volume capability take precedence over them.
+type DiskTuningProfiles map[string][]string
+
+// ParseDiskTuningProfiles parses disk tuning profiles from a JSON object
+// mapping disk types to lists of mount flags, like
+// {"hyperdisk-extreme": ["read_ahead_kb=0", "queue/scheduler=none", "noatime"]}.
+func ParseDiskTuningProfiles(data []byte) (DiskTuningProfiles, error) {
+	profiles := DiskTuningProfiles{}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse disk tuning profiles: %w", err)
+	}
+	for diskType, flags := range profiles {
+		if _, _, err := extractReadAheadKBMountFlag(flags); err != nil {
+			return nil, fmt.Errorf("invalid tuning profile of disk type %s: %w", diskType, err)
+		}
+		if _, err := common.ParseQueueSettings(flags); err != nil {
+			return nil, fmt.Errorf("invalid tuning profile of disk type %s: %w", diskType, err)
+		}
+		if _, err := extractFstrimInterval(nil, flags); err != nil {
+			return nil, fmt.Errorf("invalid tuning profile of disk type %s: %w", diskType, err)
+		}
+		for _, flag := range flags {
+			if flag == "ro" || flag == "rw" || fsckPolicyMountFlagRegex.MatchString(flag) {
+				return nil, fmt.Errorf("invalid tuning profile of disk type %s: mount flag %q is not allowed", diskType, flag)
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// applyTuningProfile returns the mount flags of profile which are not
+// overridden by mountFlags, followed by mountFlags.
+func applyTuningProfile(profile, mountFlags []string) []string {
+	if len(profile) == 0 {
+		return mountFlags
+	}
+	explicit := map[string]bool{}
+	for _, flag := range mountFlags {
+		explicit[mountFlagKey(flag)] = true
+	}
+	flags := []string{}
+	for _, flag := range profile {
+		if !explicit[mountFlagKey(flag)] {
+			flags = append(flags, flag)
+		}
+	}
+	return append(flags, mountFlags...)
+}
+
+// mountFlagKey returns the setting changed by a mount flag: the name of
+// name=value flags, and the option of boolean flags without their no prefix,
+// so that e.g. dev and nodev override each other. The flags selecting when
+// access times are updated all override each other.
+func mountFlagKey(flag string) string {
+	name, _, _ := strings.Cut(flag, "=")
+	switch name {
+	case "atime", "noatime", "relatime", "norelatime", "strictatime", "nostrictatime":
+		return "atime"
+	}
+	return strings.TrimPrefix(name, "no")
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/mount-utils"
+	testingexec "k8s.io/utils/exec/testing"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+func TestNodeStageVolumeTuningProfile(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+	profiles := DiskTuningProfiles{
+		"hyperdisk-balanced": {"noatime", "nodev", "read_ahead_kb=4096"},
+	}
+
+	testCases := []struct {
+		name         string
+		diskType     string
+		mountFlags   []string
+		expOptions   []string
+		expReadAhead string
+	}{
+		{
+			name:         "profile of the disk type",
+			diskType:     "hyperdisk-balanced",
+			expOptions:   []string{"noatime", "nodev", "ro", "defaults"},
+			expReadAhead: "8192",
+		},
+		{
+			name:         "mount flags take precedence",
+			diskType:     "hyperdisk-balanced",
+			mountFlags:   []string{"relatime", "read_ahead_kb=128"},
+			expOptions:   []string{"nodev", "relatime", "ro", "defaults"},
+			expReadAhead: "256",
+		},
+		{
+			name:       "no profile for the disk type",
+			diskType:   "pd-balanced",
+			mountFlags: []string{"relatime"},
+			expOptions: []string{"relatime", "ro", "defaults"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The volume is read-only, so that it is neither checked nor
+			// resized.
+			cmds := []fakeCmd{{cmd: "blkid", args: "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path", stdout: "DEVNAME=/dev/sdb\nTYPE=ext4"}}
+			if tc.expReadAhead != "" {
+				cmds = append(cmds,
+					fakeCmd{cmd: "blockdev", args: "--getss /dev/disk/fake-path", stdout: "512"},
+					fakeCmd{cmd: "blockdev", args: "--setra " + tc.expReadAhead + " /dev/disk/fake-path"})
+			}
+			actionList := []testingexec.FakeCommandAction{}
+			for _, cmd := range cmds {
+				action := []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(cmd.stdout), nil, cmd.err
+					},
+				}
+				actionList = append(actionList, makeFakeCmd(
+					&testingexec.FakeCmd{
+						CombinedOutputScript: action,
+						OutputScript:         action,
+					},
+					cmd.cmd,
+					strings.Split(cmd.args, " ")...,
+				))
+			}
+			fakeExec := &testingexec.FakeExec{CommandScript: actionList, ExactOrder: true}
+			mounter := mountmanager.NewFakeSafeMounterWithCustomExec(fakeExec)
+			ns := getCustomTestGCEDriver(t, mounter, deviceutils.NewFakeDeviceUtils(false), metadataservice.NewFakeService(), &NodeServerArgs{DiskTuningProfiles: profiles}).ns
+
+			_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{FsType: "ext4", MountFlags: tc.mountFlags},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY,
+					},
+				},
+				PublishContext: map[string]string{common.ContextDiskType: tc.diskType},
+			})
+			if err != nil {
+				t.Fatalf("NodeStageVolume() failed: %v", err)
+			}
+			if fakeExec.CommandCalls != len(actionList) {
+				t.Errorf("NodeStageVolume() ran %d commands, want %d", fakeExec.CommandCalls, len(actionList))
+			}
+			mountPoints := mounter.Interface.(*mount.FakeMounter).MountPoints
+			if len(mountPoints) != 1 {
+				t.Fatalf("NodeStageVolume() mounted %d times, want 1", len(mountPoints))
+			}
+			if diff := cmp.Diff(tc.expOptions, mountPoints[0].Opts); diff != "" {
+				t.Errorf("NodeStageVolume() mounted with unexpected options (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
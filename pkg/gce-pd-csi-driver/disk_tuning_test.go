@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDiskTuningProfiles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        string
+		expProfiles DiskTuningProfiles
+		expErr      bool
+	}{
+		{
+			name: "valid profiles",
+			data: `{"hyperdisk-extreme": ["read_ahead_kb=0", "queue/scheduler=none", "noatime"], "pd-standard": ["read_ahead_kb=4096"]}`,
+			expProfiles: DiskTuningProfiles{
+				"hyperdisk-extreme": {"read_ahead_kb=0", "queue/scheduler=none", "noatime"},
+				"pd-standard":       {"read_ahead_kb=4096"},
+			},
+		},
+		{
+			name:        "no profiles",
+			data:        `{}`,
+			expProfiles: DiskTuningProfiles{},
+		},
+		{
+			name:   "not a JSON object",
+			data:   `["noatime"]`,
+			expErr: true,
+		},
+		{
+			name:   "invalid read_ahead_kb",
+			data:   `{"pd-ssd": ["read_ahead_kb=-1"]}`,
+			expErr: true,
+		},
+		{
+			name:   "block queue attribute not allowed",
+			data:   `{"pd-ssd": ["queue/iosched=none"]}`,
+			expErr: true,
+		},
+		{
+			name:   "access mode",
+			data:   `{"pd-ssd": ["ro"]}`,
+			expErr: true,
+		},
+		{
+			name:   "fsck policy",
+			data:   `{"pd-ssd": ["fsck-policy=repair"]}`,
+			expErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			profiles, err := ParseDiskTuningProfiles([]byte(tc.data))
+			if gotErr := err != nil; gotErr != tc.expErr {
+				t.Fatalf("ParseDiskTuningProfiles() got error %v, want error %v", err, tc.expErr)
+			}
+			if diff := cmp.Diff(tc.expProfiles, profiles); diff != "" {
+				t.Errorf("ParseDiskTuningProfiles() returned unexpected profiles (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyTuningProfile(t *testing.T) {
+	testCases := []struct {
+		name       string
+		profile    []string
+		mountFlags []string
+		expFlags   []string
+	}{
+		{
+			name:       "no profile",
+			mountFlags: []string{"noatime"},
+			expFlags:   []string{"noatime"},
+		},
+		{
+			name:     "no mount flags",
+			profile:  []string{"noatime", "read_ahead_kb=0"},
+			expFlags: []string{"noatime", "read_ahead_kb=0"},
+		},
+		{
+			name:       "mount flags take precedence",
+			profile:    []string{"read_ahead_kb=0", "queue/scheduler=none", "nodev", "discard"},
+			mountFlags: []string{"read_ahead_kb=128", "dev", "queue/nr_requests=64"},
+			expFlags:   []string{"queue/scheduler=none", "discard", "read_ahead_kb=128", "dev", "queue/nr_requests=64"},
+		},
+		{
+			name:       "access time flags override each other",
+			profile:    []string{"noatime", "nodiratime"},
+			mountFlags: []string{"relatime"},
+			expFlags:   []string{"nodiratime", "relatime"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			flags := applyTuningProfile(tc.profile, tc.mountFlags)
+			if diff := cmp.Diff(tc.expFlags, flags); diff != "" {
+				t.Errorf("applyTuningProfile() returned unexpected mount flags (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
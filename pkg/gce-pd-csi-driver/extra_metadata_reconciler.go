@@ -0,0 +1,392 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+)
+
+var (
+	// Fields required to find the disks created by the driver and compare
+	// their labels.
+	extraMetadataDiskFields = []googleapi.Field{
+		"items/description",
+		"items/id",
+		"items/labelFingerprint",
+		"items/labels",
+		"items/name",
+		"items/region",
+		"items/selfLink",
+		"items/zone",
+		"nextPageToken",
+	}
+)
+
+// ExtraMetadataReconcilerConfig configures the reconciliation of the
+// --extra-labels and --extra-tags flags on existing resources.
+type ExtraMetadataReconcilerConfig struct {
+	// Interval is the time between two reconciliation passes.
+	Interval time.Duration
+	// DryRun only reports the drift, without updating any resource.
+	DryRun bool
+	// UpdateValues overwrites the labels and tags set to other values than
+	// the extra ones. Otherwise they are only reported.
+	UpdateValues bool
+	// QPS limits the rate of label and tag updates.
+	QPS float64
+}
+
+// ExtraMetadataReconciler periodically converges the labels and tag bindings
+// of the disks, snapshots and images created by the driver to the extra
+// labels and tags the driver is configured with. Those are otherwise only
+// applied when a resource is created.
+//
+// Missing labels and tags are added. Labels and tags set to other values are
+// only overwritten with UpdateValues, as they may be set on purpose, e.g. by
+// the labels parameter of a StorageClass which overrides --extra-labels. The
+// extra labels and tags a resource was created with are not recorded, so
+// ones removed from the flags are left in place.
+type ExtraMetadataReconciler struct {
+	cloud       gce.GCECompute
+	driverName  string
+	extraLabels map[string]string
+	extraTags   map[string]string
+	config      ExtraMetadataReconcilerConfig
+	limiter     *rate.Limiter
+}
+
+// metadataDrift describes the labels and tags missing from a resource or set
+// to other values.
+type metadataDrift struct {
+	resourceType gce.ResourceType
+	project      string
+	// key is the disk key, only set for disks.
+	key *meta.Key
+	// name is the snapshot or image name.
+	name             string
+	id               uint64
+	labels           map[string]string
+	labelFingerprint string
+	// missingLabels are the extra labels whose keys are missing from labels.
+	missingLabels map[string]string
+	// changedLabels are the extra labels set to other values in labels.
+	changedLabels map[string]string
+	// missingTags are the extra tags whose keys are not bound to the
+	// resource.
+	missingTags map[string]string
+	// changedTags are the extra tags whose keys are bound to other values.
+	changedTags map[string]string
+}
+
+func (d *metadataDrift) empty() bool {
+	return len(d.missingLabels) == 0 && len(d.changedLabels) == 0 && len(d.missingTags) == 0 && len(d.changedTags) == 0
+}
+
+func (d *metadataDrift) String() string {
+	if d.key != nil {
+		return fmt.Sprintf("%s %s/%s", d.resourceType, d.project, d.key)
+	}
+	return fmt.Sprintf("%s %s/%s", d.resourceType, d.project, d.name)
+}
+
+func (d *metadataDrift) tagBindingRequest(tags map[string]string) gce.TagBindingRequest {
+	req := gce.TagBindingRequest{
+		Project:      d.project,
+		ResourceType: d.resourceType,
+		Name:         d.name,
+		ID:           d.id,
+		Tags:         tags,
+	}
+	if d.key != nil {
+		req.Name = d.key.Name
+		req.Location = d.key.Zone
+		req.IsZonal = d.key.Type() == meta.Zonal
+		if !req.IsZonal {
+			req.Location = d.key.Region
+		}
+	}
+	return req
+}
+
+// NewExtraMetadataReconciler returns a reconciler converging the resources
+// created by driverName to extraLabels and extraTags.
+func NewExtraMetadataReconciler(cloud gce.GCECompute, driverName string, extraLabels, extraTags map[string]string, config ExtraMetadataReconcilerConfig) *ExtraMetadataReconciler {
+	limit := rate.Inf
+	if config.QPS > 0 {
+		limit = rate.Limit(config.QPS)
+	}
+	return &ExtraMetadataReconciler{
+		cloud:       cloud,
+		driverName:  driverName,
+		extraLabels: extraLabels,
+		extraTags:   extraTags,
+		config:      config,
+		limiter:     rate.NewLimiter(limit, 1),
+	}
+}
+
+// Run reconciles the resources every interval until ctx is done.
+func (r *ExtraMetadataReconciler) Run(ctx context.Context) {
+	if len(r.extraLabels) == 0 && len(r.extraTags) == 0 {
+		klog.Infof("No extra labels or tags configured, not reconciling existing resources")
+		return
+	}
+	klog.Infof("Reconciling extra labels and tags on existing resources every %v (dry run: %v)", r.config.Interval, r.config.DryRun)
+	// Reconciliation is not urgent, leave the compute API budget to the
+	// calls serving CSI requests.
+	ctx = gce.WithAPIPriority(ctx, gce.APIPriorityLow)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if _, err := r.reconcile(ctx); err != nil {
+			klog.Errorf("Failed to reconcile extra labels and tags: %v", err)
+		}
+	}, r.config.Interval)
+}
+
+// reconcile runs a reconciliation pass and returns the drift found. Unless
+// in dry run, the drift is corrected; errors updating a single resource are
+// logged and do not stop the pass.
+func (r *ExtraMetadataReconciler) reconcile(ctx context.Context) ([]*metadataDrift, error) {
+	drifts, err := r.findDrift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drifts {
+		if r.config.DryRun {
+			klog.Infof("Dry run: %s is missing extra labels %v and extra tags %v, and has other values of extra labels %v and extra tags %v", d, d.missingLabels, d.missingTags, d.changedLabels, d.changedTags)
+			continue
+		}
+		if err := r.apply(ctx, d); err != nil {
+			klog.Errorf("Failed to reconcile extra labels and tags of %s: %v", d, err)
+		}
+	}
+	klog.V(4).Infof("Found %d resources with stale extra labels or tags", len(drifts))
+	return drifts, nil
+}
+
+// findDrift lists the disks, snapshots and images created by the driver and
+// returns the ones whose labels or tags differ from the extra ones.
+func (r *ExtraMetadataReconciler) findDrift(ctx context.Context) ([]*metadataDrift, error) {
+	var drifts []*metadataDrift
+
+	disks, _, err := r.cloud.ListDisks(ctx, extraMetadataDiskFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disks: %w", err)
+	}
+	for _, disk := range disks {
+		if !common.IsCreatedByDriver(disk.Description, r.driverName) {
+			continue
+		}
+		volumeID, err := getResourceId(disk.SelfLink)
+		if err != nil {
+			klog.Warningf("Skipping disk %s: %v", disk.Name, err)
+			continue
+		}
+		project, key, err := common.VolumeIDToKey(volumeID)
+		if err != nil {
+			klog.Warningf("Skipping disk %s: %v", disk.Name, err)
+			continue
+		}
+		d := &metadataDrift{
+			resourceType:     gce.DisksType,
+			project:          project,
+			key:              key,
+			id:               disk.Id,
+			labels:           disk.Labels,
+			labelFingerprint: disk.LabelFingerprint,
+		}
+		if err := r.diff(ctx, d); err != nil {
+			return nil, err
+		}
+		if !d.empty() {
+			drifts = append(drifts, d)
+		}
+	}
+
+	snapshots, _, err := r.cloud.ListSnapshots(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	for _, snapshot := range snapshots {
+		if !common.IsCreatedByDriver(snapshot.Description, r.driverName) {
+			continue
+		}
+		d, err := r.globalResourceDrift(ctx, gce.SnapshotsType, snapshot.SelfLink, snapshot.Id, snapshot.Labels, snapshot.LabelFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			drifts = append(drifts, d)
+		}
+	}
+
+	images, _, err := r.cloud.ListImages(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	for _, image := range images {
+		if !common.IsCreatedByDriver(image.Description, r.driverName) {
+			continue
+		}
+		d, err := r.globalResourceDrift(ctx, gce.ImagesType, image.SelfLink, image.Id, image.Labels, image.LabelFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			drifts = append(drifts, d)
+		}
+	}
+	return drifts, nil
+}
+
+// globalResourceDrift returns the drift of a snapshot or image, or nil if it
+// is up to date.
+func (r *ExtraMetadataReconciler) globalResourceDrift(ctx context.Context, resourceType gce.ResourceType, selfLink string, id uint64, labels map[string]string, labelFingerprint string) (*metadataDrift, error) {
+	resourceID, err := getResourceId(selfLink)
+	if err != nil {
+		klog.Warningf("Skipping %s %s: %v", resourceType, selfLink, err)
+		return nil, nil
+	}
+	project, _, name, err := common.SnapshotIDToProjectKey(resourceID)
+	if err != nil {
+		klog.Warningf("Skipping %s %s: %v", resourceType, selfLink, err)
+		return nil, nil
+	}
+	d := &metadataDrift{
+		resourceType:     resourceType,
+		project:          project,
+		name:             name,
+		id:               id,
+		labels:           labels,
+		labelFingerprint: labelFingerprint,
+	}
+	if err := r.diff(ctx, d); err != nil {
+		return nil, err
+	}
+	if d.empty() {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// addDrift adds k=v to drift, allocating it if needed.
+func addDrift(drift *map[string]string, k, v string) {
+	if *drift == nil {
+		*drift = map[string]string{}
+	}
+	(*drift)[k] = v
+}
+
+// diff fills in the missing and changed labels and tags of d.
+func (r *ExtraMetadataReconciler) diff(ctx context.Context, d *metadataDrift) error {
+	for k, v := range r.extraLabels {
+		if current, ok := d.labels[k]; !ok {
+			addDrift(&d.missingLabels, k, v)
+		} else if current != v {
+			addDrift(&d.changedLabels, k, v)
+		}
+	}
+	if len(r.extraTags) == 0 {
+		return nil
+	}
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	effective, err := r.cloud.GetEffectiveTags(ctx, d.tagBindingRequest(nil))
+	if err != nil {
+		if gce.IsGCENotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the tags of %s: %w", d, err)
+	}
+	for k, v := range r.extraTags {
+		if current, ok := effective[k]; !ok {
+			addDrift(&d.missingTags, k, v)
+		} else if current != k+"/"+v {
+			addDrift(&d.changedTags, k, v)
+		}
+	}
+	return nil
+}
+
+// apply adds the missing labels and binds the missing tags of a resource,
+// and with UpdateValues the changed ones too.
+func (r *ExtraMetadataReconciler) apply(ctx context.Context, d *metadataDrift) error {
+	labelUpdates := maps.Clone(d.missingLabels)
+	tagUpdates := maps.Clone(d.missingTags)
+	if r.config.UpdateValues {
+		labelUpdates = mergeDrift(labelUpdates, d.changedLabels)
+		tagUpdates = mergeDrift(tagUpdates, d.changedTags)
+	} else if len(d.changedLabels) > 0 || len(d.changedTags) > 0 {
+		klog.V(4).Infof("Keeping other values of extra labels %v and extra tags %v of %s", d.changedLabels, d.changedTags, d)
+	}
+	if len(labelUpdates) > 0 {
+		labels := maps.Clone(d.labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, labelUpdates)
+		if err := r.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		var err error
+		switch d.resourceType {
+		case gce.DisksType:
+			err = r.cloud.SetDiskLabels(ctx, d.project, d.key, labels, d.labelFingerprint)
+		case gce.SnapshotsType:
+			err = r.cloud.SetSnapshotLabels(ctx, d.project, d.name, labels, d.labelFingerprint)
+		case gce.ImagesType:
+			err = r.cloud.SetImageLabels(ctx, d.project, d.name, labels, d.labelFingerprint)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set labels: %w", err)
+		}
+		klog.Infof("Updated extra labels %v of %s", labelUpdates, d)
+	}
+	if len(tagUpdates) > 0 {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := r.cloud.BindTags(ctx, d.tagBindingRequest(tagUpdates)); err != nil {
+			return fmt.Errorf("failed to bind tags: %w", err)
+		}
+		klog.Infof("Bound extra tags %v to %s", tagUpdates, d)
+	}
+	return nil
+}
+
+// mergeDrift returns the union of a and b, which may be nil.
+func mergeDrift(a, b map[string]string) map[string]string {
+	if len(b) == 0 {
+		return a
+	}
+	if a == nil {
+		a = map[string]string{}
+	}
+	maps.Copy(a, b)
+	return a
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+)
+
+func TestExtraMetadataReconciler(t *testing.T) {
+	ctx := context.Background()
+	fcp, err := gce.CreateFakeCloudProvider(project, zone, nil)
+	if err != nil {
+		t.Fatalf("Failed to create fake cloud provider: %v", err)
+	}
+	driverTags := map[string]string{"storage.gke.io/created-by": driver}
+	capacityRange := &csi.CapacityRange{RequiredBytes: common.GbToBytes(1)}
+
+	driverDisk := meta.ZonalKey("driver-disk", zone)
+	err = fcp.InsertDisk(ctx, project, driverDisk, common.DiskParameters{
+		DiskType: stdDiskType,
+		Labels:   map[string]string{"cost-center": "old", "team": "storage"},
+		Tags:     driverTags,
+	}, common.GbToBytes(1), capacityRange, nil, "", "", false, "")
+	if err != nil {
+		t.Fatalf("Failed to insert disk: %v", err)
+	}
+	// Disks not created by the driver are left alone.
+	otherDisk := meta.ZonalKey("other-disk", zone)
+	err = fcp.InsertDisk(ctx, project, otherDisk, common.DiskParameters{DiskType: stdDiskType}, common.GbToBytes(1), capacityRange, nil, "", "", false, "")
+	if err != nil {
+		t.Fatalf("Failed to insert disk: %v", err)
+	}
+	if _, err := fcp.CreateSnapshot(ctx, project, driverDisk, "snapshot", common.SnapshotParameters{Tags: driverTags}); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+
+	extraLabels := map[string]string{"cost-center": "new", "env": "prod"}
+	extraTags := map[string]string{"parent/key": "value"}
+	diskTags := gce.TagBindingRequest{Project: project, ResourceType: gce.DisksType, Location: zone, IsZonal: true, Name: driverDisk.Name}
+	snapshotTags := gce.TagBindingRequest{Project: project, ResourceType: gce.SnapshotsType, Name: "snapshot"}
+	// The snapshot is bound to another value of the extra tag.
+	if err := fcp.BindTags(ctx, gce.TagBindingRequest{Project: project, ResourceType: gce.SnapshotsType, Name: "snapshot", Tags: map[string]string{"parent/key": "other"}}); err != nil {
+		t.Fatalf("BindTags() failed: %v", err)
+	}
+
+	// A dry run reports the drift without applying it.
+	r := NewExtraMetadataReconciler(fcp, driver, extraLabels, extraTags, ExtraMetadataReconcilerConfig{DryRun: true})
+	drifts, err := r.reconcile(ctx)
+	if err != nil {
+		t.Fatalf("reconcile() failed: %v", err)
+	}
+	type drift struct{ missingLabels, changedLabels, missingTags, changedTags map[string]string }
+	checkDrifts := func(drifts []*metadataDrift, wantDrifts map[gce.ResourceType]drift) {
+		t.Helper()
+		if len(drifts) != len(wantDrifts) {
+			t.Fatalf("reconcile() found drift on %v, want drift on %v", drifts, wantDrifts)
+		}
+		for _, d := range drifts {
+			got := drift{d.missingLabels, d.changedLabels, d.missingTags, d.changedTags}
+			if want := wantDrifts[d.resourceType]; !reflect.DeepEqual(got, want) {
+				t.Errorf("drift of %s = %+v, want %+v", d, got, want)
+			}
+		}
+	}
+	checkDrifts(drifts, map[gce.ResourceType]drift{
+		gce.DisksType: {
+			missingLabels: map[string]string{"env": "prod"},
+			changedLabels: map[string]string{"cost-center": "new"},
+			missingTags:   extraTags,
+		},
+		gce.SnapshotsType: {
+			missingLabels: extraLabels,
+			changedTags:   extraTags,
+		},
+	})
+	disk, err := fcp.GetDisk(ctx, project, driverDisk)
+	if err != nil {
+		t.Fatalf("GetDisk() failed: %v", err)
+	}
+	if _, ok := disk.GetLabels()["env"]; ok {
+		t.Errorf("dry run added the env label")
+	}
+
+	// Without UpdateValues, only the missing labels and tags are added, and
+	// other values, e.g. set by the labels parameter of a StorageClass, are
+	// kept.
+	r.config.DryRun = false
+	if _, err := r.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile() failed: %v", err)
+	}
+	disk, err = fcp.GetDisk(ctx, project, driverDisk)
+	if err != nil {
+		t.Fatalf("GetDisk() failed: %v", err)
+	}
+	if want := map[string]string{"cost-center": "old", "env": "prod", "team": "storage"}; !reflect.DeepEqual(disk.GetLabels(), want) {
+		t.Errorf("disk labels = %v, want %v", disk.GetLabels(), want)
+	}
+	drifts, err = r.reconcile(ctx)
+	if err != nil {
+		t.Fatalf("reconcile() failed: %v", err)
+	}
+	checkDrifts(drifts, map[gce.ResourceType]drift{
+		gce.DisksType:     {changedLabels: map[string]string{"cost-center": "new"}},
+		gce.SnapshotsType: {changedTags: extraTags},
+	})
+
+	// With UpdateValues, other values are overwritten.
+	r.config.UpdateValues = true
+	if _, err := r.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile() failed: %v", err)
+	}
+	disk, err = fcp.GetDisk(ctx, project, driverDisk)
+	if err != nil {
+		t.Fatalf("GetDisk() failed: %v", err)
+	}
+	if want := map[string]string{"cost-center": "new", "env": "prod", "team": "storage"}; !reflect.DeepEqual(disk.GetLabels(), want) {
+		t.Errorf("disk labels = %v, want %v", disk.GetLabels(), want)
+	}
+	other, err := fcp.GetDisk(ctx, project, otherDisk)
+	if err != nil {
+		t.Fatalf("GetDisk() failed: %v", err)
+	}
+	if len(other.GetLabels()) != 0 {
+		t.Errorf("labels of a disk not created by the driver were updated to %v", other.GetLabels())
+	}
+	snapshot, err := fcp.GetSnapshot(ctx, project, "snapshot")
+	if err != nil {
+		t.Fatalf("GetSnapshot() failed: %v", err)
+	}
+	if !reflect.DeepEqual(snapshot.Labels, extraLabels) {
+		t.Errorf("snapshot labels = %v, want %v", snapshot.Labels, extraLabels)
+	}
+	wantTags := map[gce.ResourceType]map[string]string{
+		gce.DisksType:     {"parent/key": "parent/key/value"},
+		gce.SnapshotsType: {"parent/key": "parent/key/value"},
+	}
+	for _, req := range []gce.TagBindingRequest{diskTags, snapshotTags} {
+		tags, err := fcp.GetEffectiveTags(ctx, req)
+		if err != nil {
+			t.Fatalf("GetEffectiveTags() failed: %v", err)
+		}
+		if !reflect.DeepEqual(tags, wantTags[req.ResourceType]) {
+			t.Errorf("tags of %s %s = %v, want %v", req.ResourceType, req.Name, tags, wantTags[req.ResourceType])
+		}
+	}
+
+	// Once converged, there is no drift left.
+	drifts, err = r.reconcile(ctx)
+	if err != nil {
+		t.Fatalf("reconcile() failed: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("reconcile() found drift on %v after converging", drifts)
+	}
+}
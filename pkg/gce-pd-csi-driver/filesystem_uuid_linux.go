@@ -0,0 +1,154 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// uuidRegenerateCommands are the commands giving a new random UUID to the
+// filesystem of a device, by filesystem type. The device is appended to the
+// arguments. btrfstune -m only changes the fsid, without rewriting the
+// metadata. ext4 is left out, as it mounts filesystems with the same UUID, and
+// changing the UUID of an ext4 filesystem with metadata_csum rewrites all its
+// checksums.
+var uuidRegenerateCommands = map[string][]string{
+	fsTypeXFS:   {"xfs_admin", "-U", "generate"},
+	fsTypeBtrfs: {"btrfstune", "-m"},
+}
+
+// evalSymlinks resolves the /dev/disk/by-id links of devices.
+var evalSymlinks = filepath.EvalSymlinks
+
+// resolveDuplicateFilesystemUUID checks whether the fstype filesystem of
+// devicePath has the same UUID as a filesystem already mounted on the node,
+// as is the case for clones and volumes restored from snapshots of mounted
+// volumes. Writable filesystems are then given a new UUID, and read-only xfs
+// filesystems are mounted with nouuid, as are xfs filesystems whose UUID
+// cannot be checked. It returns the additional mount options of the
+// filesystem, or an error if it cannot be mounted next to the filesystem with
+// the same UUID.
+func (ns *GCENodeServer) resolveDuplicateFilesystemUUID(volumeID, devicePath, fstype string, readOnly bool) ([]string, error) {
+	regenerate, ok := uuidRegenerateCommands[fstype]
+	if !ok {
+		return nil, nil
+	}
+	// fallback are the mount options of the filesystem if it may have the
+	// same UUID as a mounted filesystem.
+	var fallback []string
+	if fstype == fsTypeXFS {
+		fallback = []string{"nouuid"}
+	}
+
+	mountPoints, err := ns.Mounter.List()
+	if err != nil {
+		klog.Warningf("Failed to list mounts to check the filesystem UUID of volume %s, mounting it with options %v: %v", volumeID, fallback, err)
+		return fallback, nil
+	}
+	device := resolveDevicePath(devicePath)
+	var mountedDevices []string
+	for _, mp := range mountPoints {
+		if mp.Type != fstype || !strings.HasPrefix(mp.Device, "/dev/") {
+			continue
+		}
+		if d := resolveDevicePath(mp.Device); d != device && !slices.Contains(mountedDevices, d) {
+			mountedDevices = append(mountedDevices, d)
+		}
+	}
+	if len(mountedDevices) == 0 {
+		return nil, nil
+	}
+
+	// blkid prints the devices of unformatted disks without a UUID, or fails
+	// if no device is formatted. It prints the devices by their resolved
+	// path, so links are resolved before.
+	args := append([]string{"-s", "UUID", "-o", "export", device}, mountedDevices...)
+	out, err := ns.Mounter.Exec.Command("blkid", args...).Output()
+	if err != nil {
+		klog.Warningf("Failed to get the filesystem UUIDs of %s and mounted devices %v, mounting volume %s with options %v: %v", device, mountedDevices, volumeID, fallback, err)
+		return fallback, nil
+	}
+	uuids := parseBlkidUUIDs(string(out))
+	uuid := uuids[device]
+	if uuid == "" {
+		return nil, nil
+	}
+	duplicate := ""
+	for _, d := range mountedDevices {
+		if uuids[d] == uuid {
+			duplicate = d
+			break
+		}
+	}
+	if duplicate == "" {
+		return nil, nil
+	}
+
+	if readOnly {
+		if fstype == fsTypeXFS {
+			klog.Infof("The filesystem of volume %s has the same UUID %s as mounted device %s, mounting it with nouuid", volumeID, uuid, duplicate)
+			return []string{"nouuid"}, nil
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "the %s filesystem of volume %s has the same UUID %s as mounted device %s, and cannot be given a new one as it is staged read-only", fstype, volumeID, uuid, duplicate)
+	}
+
+	klog.Infof("The filesystem of volume %s has the same UUID %s as mounted device %s, giving it a new UUID", volumeID, uuid, duplicate)
+	cmdArgs := append(slices.Clone(regenerate[1:]), devicePath)
+	if out, err := ns.Mounter.Exec.Command(regenerate[0], cmdArgs...).CombinedOutput(); err != nil {
+		// xfs_admin fails if the log of the filesystem must be replayed.
+		if fstype == fsTypeXFS {
+			klog.Warningf("Failed to give a new UUID to the filesystem of volume %s with %s %v, mounting it with nouuid: %v, output: %s", volumeID, regenerate[0], cmdArgs, err, string(out))
+			return []string{"nouuid"}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to give a new UUID to the %s filesystem of volume %s, which has the same UUID %s as mounted device %s, with %s %v: %v, output: %s", fstype, volumeID, uuid, duplicate, regenerate[0], cmdArgs, err, string(out))
+	}
+	return nil, nil
+}
+
+// resolveDevicePath returns the device devicePath links to, or devicePath if
+// it cannot be resolved.
+func resolveDevicePath(devicePath string) string {
+	if resolved, err := evalSymlinks(devicePath); err == nil {
+		return resolved
+	}
+	return devicePath
+}
+
+// parseBlkidUUIDs parses the output of blkid -s UUID -o export, and returns
+// the filesystem UUIDs by device.
+func parseBlkidUUIDs(out string) map[string]string {
+	uuids := map[string]string{}
+	device := ""
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch key {
+		case "DEVNAME":
+			device = value
+		case "UUID":
+			if device != "" {
+				uuids[device] = value
+			}
+		}
+	}
+	return uuids
+}
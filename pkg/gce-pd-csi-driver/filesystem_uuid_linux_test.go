@@ -0,0 +1,211 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/mount-utils"
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+func TestNodeStageVolumeDuplicateFilesystemUUID(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	uuidCmd := func(otherUUID string) fakeCmd {
+		return fakeCmd{
+			cmd:    "blkid",
+			args:   "-s UUID -o export /dev/sdb /dev/sdc",
+			stdout: "DEVNAME=/dev/sdb\nUUID=1b2a6ed4\n\nDEVNAME=/dev/sdc\nUUID=" + otherUUID + "\n",
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		fsType        string
+		mountedFsType string
+		readOnly      bool
+		uuidCmds      []fakeCmd
+		expNouuid     bool
+		expErrCode    codes.Code
+	}{
+		{
+			name:          "writable xfs gets a new UUID",
+			fsType:        "xfs",
+			mountedFsType: "xfs",
+			uuidCmds: []fakeCmd{
+				uuidCmd("1b2a6ed4"),
+				{cmd: "xfs_admin", args: "-U generate /dev/disk/fake-path"},
+			},
+		},
+		{
+			name:          "xfs with a dirty log is mounted with nouuid",
+			fsType:        "xfs",
+			mountedFsType: "xfs",
+			uuidCmds: []fakeCmd{
+				uuidCmd("1b2a6ed4"),
+				{cmd: "xfs_admin", args: "-U generate /dev/disk/fake-path", err: exec.CodeExitError{Err: errors.New("exit status"), Code: 1}},
+			},
+			expNouuid: true,
+		},
+		{
+			name:          "read-only xfs is mounted with nouuid",
+			fsType:        "xfs",
+			mountedFsType: "xfs",
+			readOnly:      true,
+			uuidCmds:      []fakeCmd{uuidCmd("1b2a6ed4")},
+			expNouuid:     true,
+		},
+		{
+			name:          "different UUIDs",
+			fsType:        "xfs",
+			mountedFsType: "xfs",
+			uuidCmds:      []fakeCmd{uuidCmd("9f0c33a1")},
+		},
+		{
+			name:          "no mounted filesystem of the same type",
+			fsType:        "xfs",
+			mountedFsType: "ext4",
+		},
+		{
+			name:          "xfs UUIDs which cannot be read are mounted with nouuid",
+			fsType:        "xfs",
+			mountedFsType: "xfs",
+			uuidCmds: []fakeCmd{
+				{cmd: "blkid", args: "-s UUID -o export /dev/sdb /dev/sdc", err: exec.CodeExitError{Err: errors.New("exit status"), Code: 2}},
+			},
+			expNouuid: true,
+		},
+		{
+			name:          "ext4 is left as is",
+			fsType:        "ext4",
+			mountedFsType: "ext4",
+		},
+		{
+			name:          "writable btrfs gets a new fsid",
+			fsType:        "btrfs",
+			mountedFsType: "btrfs",
+			uuidCmds: []fakeCmd{
+				uuidCmd("1b2a6ed4"),
+				{cmd: "btrfstune", args: "-m /dev/disk/fake-path"},
+			},
+		},
+		{
+			name:          "btrfs fsid which cannot be changed",
+			fsType:        "btrfs",
+			mountedFsType: "btrfs",
+			uuidCmds: []fakeCmd{
+				uuidCmd("1b2a6ed4"),
+				{cmd: "btrfstune", args: "-m /dev/disk/fake-path", err: exec.CodeExitError{Err: errors.New("exit status"), Code: 1}},
+			},
+			expErrCode: codes.Internal,
+		},
+		{
+			name:          "read-only btrfs",
+			fsType:        "btrfs",
+			mountedFsType: "btrfs",
+			readOnly:      true,
+			uuidCmds:      []fakeCmd{uuidCmd("1b2a6ed4")},
+			expErrCode:    codes.FailedPrecondition,
+		},
+	}
+	// The disk links to /dev/sdb, like blkid prints it.
+	defer func(f func(string) (string, error)) { evalSymlinks = f }(evalSymlinks)
+	evalSymlinks = func(path string) (string, error) {
+		if path == "/dev/disk/fake-path" {
+			return "/dev/sdb", nil
+		}
+		return path, nil
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmds := slices.Clone(tc.uuidCmds)
+			mode := csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY
+			if !tc.readOnly {
+				mode = csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER
+			}
+			if tc.expErrCode == codes.OK {
+				cmds = append(cmds, fakeCmd{cmd: "blkid", args: "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path", stdout: "DEVNAME=/dev/sdb\nTYPE=" + tc.fsType})
+				if !tc.readOnly {
+					cmds = append(cmds,
+						fakeCmd{cmd: "fsck", args: "-a /dev/disk/fake-path"},
+						// The device is read-only, so that the filesystem is not
+						// resized.
+						fakeCmd{cmd: "blockdev", args: "--getro /dev/disk/fake-path", stdout: "1"})
+				}
+			}
+			actionList := []testingexec.FakeCommandAction{}
+			for _, cmd := range cmds {
+				action := []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(cmd.stdout), nil, cmd.err
+					},
+				}
+				actionList = append(actionList, makeFakeCmd(
+					&testingexec.FakeCmd{
+						CombinedOutputScript: action,
+						OutputScript:         action,
+					},
+					cmd.cmd,
+					strings.Split(cmd.args, " ")...,
+				))
+			}
+			fakeExec := &testingexec.FakeExec{CommandScript: actionList, ExactOrder: true}
+			mounter := mountmanager.NewFakeSafeMounterWithCustomExec(fakeExec)
+			fakeMounter := mounter.Interface.(*mount.FakeMounter)
+			fakeMounter.MountPoints = []mount.MountPoint{{Device: "/dev/sdc", Path: "/mnt/source", Type: tc.mountedFsType}}
+			ns := getTestGCEDriverWithCustomMounter(t, mounter).ns
+
+			stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+			_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{FsType: tc.fsType},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: mode},
+				},
+			})
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Fatalf("NodeStageVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+			if fakeExec.CommandCalls != len(actionList) {
+				t.Errorf("NodeStageVolume() ran %d commands, want %d", fakeExec.CommandCalls, len(actionList))
+			}
+			if err != nil {
+				return
+			}
+			i := slices.IndexFunc(fakeMounter.MountPoints, func(mp mount.MountPoint) bool { return mp.Path == stagingPath })
+			if i < 0 {
+				t.Fatalf("NodeStageVolume() did not mount the volume")
+			}
+			if got := slices.Contains(fakeMounter.MountPoints[i].Opts, "nouuid"); got != tc.expNouuid {
+				t.Errorf("NodeStageVolume() mounted with options %v, want nouuid %v", fakeMounter.MountPoints[i].Opts, tc.expNouuid)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"fmt"
+	"sync"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+// Results of the filesystem check of a volume, as reported in metrics.
+const (
+	// fsckResultSkipped means that the policy or the access mode of the
+	// volume did not call for a check, or that the check could not run.
+	fsckResultSkipped = "skipped"
+	fsckResultClean   = "clean"
+	// fsckResultCorrected means that errors were found and corrected.
+	fsckResultCorrected = "corrected"
+	// fsckResultErrors means that errors were found and left uncorrected.
+	fsckResultErrors = "errors"
+	// fsckResultFailed means that the check tool itself failed.
+	fsckResultFailed = "failed"
+)
+
+// extractFsckPolicy returns the fsck policy of a volume. The fsck-policy mount
+// flag takes precedence over the fsck-policy key of the volume context, which
+// takes precedence over defaultPolicy.
+func extractFsckPolicy(volumeContext map[string]string, mountFlags []string, defaultPolicy string) (string, error) {
+	policy := defaultPolicy
+	if v, ok := volumeContext[common.ParameterKeyFsckPolicy]; ok {
+		policy = v
+	}
+	for _, mountFlag := range mountFlags {
+		if got := fsckPolicyMountFlagRegex.FindStringSubmatch(mountFlag); len(got) == 2 {
+			policy = got[1]
+		}
+	}
+	if policy == "" {
+		return common.FsckPolicyOnDirty, nil
+	}
+	if err := common.ValidateFsckPolicy(policy); err != nil {
+		return "", err
+	}
+	return policy, nil
+}
+
+// fsckErrorMap keeps, by volume ID, a description of the errors the
+// filesystem check left on the filesystem of a staged volume.
+type fsckErrorMap struct {
+	mux    sync.Mutex
+	errors map[string]string
+}
+
+func newFsckErrorMap() *fsckErrorMap {
+	return &fsckErrorMap{errors: map[string]string{}}
+}
+
+// setResult records the result of the filesystem check of volumeID run with
+// tool on device.
+func (m *fsckErrorMap) setResult(volumeID, tool, device, result string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if result == fsckResultErrors {
+		m.errors[volumeID] = fmt.Sprintf("%s found errors on the filesystem of device %s when the volume was staged", tool, device)
+	} else {
+		delete(m.errors, volumeID)
+	}
+}
+
+// get returns the description of the errors left on the filesystem of
+// volumeID, or "" if there are none.
+func (m *fsckErrorMap) get(volumeID string) string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.errors[volumeID]
+}
+
+func (m *fsckErrorMap) delete(volumeID string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.errors, volumeID)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+	"k8s.io/utils/exec"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+const (
+	// Exit codes of fsck, see fsck(8). They are OR-ed together.
+	fsckErrorsUncorrected = 4
+	fsckOperationalError  = 8
+
+	// xfsRepairDirtyLog is the exit code of xfs_repair if the log of the
+	// filesystem must be replayed by mounting it first.
+	xfsRepairDirtyLog = 2
+)
+
+// checkFilesystemAndMount mounts source at target, formatting it with
+// formatOptions if it is not formatted yet, or checking its filesystem
+// according to fsckPolicy if it is. Newly formatted filesystems are never
+// checked.
+func (ns *GCENodeServer) checkFilesystemAndMount(volumeID, source, target, fstype string, options, formatOptions []string, fsckPolicy string, m *mount.SafeFormatAndMount) error {
+	readOnly := slices.Contains(options, "ro")
+	options = slices.Concat(options, []string{"defaults"})
+	if fstype == "" {
+		fstype = defaultLinuxFsType
+	}
+
+	existingFormat, err := m.GetDiskFormat(source)
+	if err != nil {
+		return mount.NewMountError(mount.GetDiskFormatFailed, "failed to get disk format of disk %s: %v", source, err)
+	}
+
+	mountErrorValue := mount.UnknownMountError
+	if existingFormat == "" {
+		if readOnly {
+			return mount.NewMountError(mount.UnformattedReadOnly, "cannot mount unformatted disk %s as we are manipulating it in read-only mode", source)
+		}
+		if err := ns.formatDisk(source, target, fstype, formatOptions, m); err != nil {
+			return err
+		}
+	} else {
+		if fstype != existingFormat {
+			mountErrorValue = mount.FilesystemMismatch
+			klog.Warningf("Configured to mount disk %s as %s but current format is %s, things might break", source, fstype, existingFormat)
+		}
+		if err := ns.checkFilesystem(volumeID, source, existingFormat, readOnly, fsckPolicy, m); err != nil {
+			return err
+		}
+	}
+
+	klog.V(4).Infof("Attempting to mount disk %s in %s format at %s", source, fstype, target)
+	if err := m.MountSensitive(source, target, fstype, options, nil /* sensitiveOptions */); err != nil {
+		return mount.NewMountError(mountErrorValue, "%s", err.Error())
+	}
+	return nil
+}
+
+// formatDisk formats the unformatted disk source as fstype with the same
+// arguments as mount-utils, preceded by formatOptions. The number of
+// concurrent formats is limited by WithMaxConcurrentFormat.
+func (ns *GCENodeServer) formatDisk(source, target, fstype string, formatOptions []string, m *mount.SafeFormatAndMount) error {
+	args := []string{source}
+	switch fstype {
+	case defaultLinuxFsType, fsTypeExt3:
+		// Force, and zero blocks reserved for the super-user.
+		args = []string{"-F", "-m0", source}
+	case fsTypeXFS:
+		args = []string{"-f", source}
+	}
+	args = slices.Concat(formatOptions, args)
+
+	if ns.formatSemaphore != nil {
+		defer holdSemaphore(ns.formatSemaphore, ns.formatTimeout)()
+	}
+	klog.Infof("Disk %q appears to be unformatted, attempting to format as type: %q with options: %v", source, fstype, args)
+	out, err := m.Exec.Command("mkfs."+fstype, args...).CombinedOutput()
+	if err != nil {
+		detailedErr := fmt.Sprintf("format of disk %q failed: type:(%q) target:(%q) errcode:(%v) output:(%v) ", source, fstype, target, err, string(out))
+		klog.Error(detailedErr)
+		return mount.NewMountError(mount.FormatFailed, "%s", detailedErr)
+	}
+	klog.Infof("Disk successfully formatted (mkfs): %s - %s %s", fstype, source, target)
+	return nil
+}
+
+// checkFilesystem checks the fstype filesystem of source according to
+// fsckPolicy, and records the result in metrics and in the volume condition
+// of volumeID. It returns an error if errors were found and left uncorrected,
+// except for the always-readonly-check policy, which only reports them.
+func (ns *GCENodeServer) checkFilesystem(volumeID, source, fstype string, readOnly bool, fsckPolicy string, m *mount.SafeFormatAndMount) error {
+	tool, args := fsckCommand(fstype, source, readOnly, fsckPolicy)
+	result := fsckResultSkipped
+	var out []byte
+	var duration time.Duration
+	if tool != "" {
+		klog.V(4).Infof("Checking for issues with %s %v on disk %s with fsck policy %s", tool, args, source, fsckPolicy)
+		start := time.Now()
+		var err error
+		out, err = m.Exec.Command(tool, args...).CombinedOutput()
+		duration = time.Since(start)
+		result = fsckResult(tool, fsckPolicy, err)
+		if result != fsckResultClean {
+			klog.Warningf("%s on disk %s returned %s (%v), output: %s", tool, source, result, err, string(out))
+		}
+	}
+
+	if ns.metricsManager != nil {
+		ns.metricsManager.RecordFsckMetric(fstype, fsckPolicy, result, duration)
+	}
+
+	if result == fsckResultErrors && fsckPolicy != common.FsckPolicyAlwaysReadOnlyCheck {
+		return mount.NewMountError(mount.HasFilesystemErrors, "'%s' found errors on device %s but could not correct them: %s", tool, source, string(out))
+	}
+	ns.fsckErrors.setResult(volumeID, tool, source, result)
+	return nil
+}
+
+// fsckCommand returns the command checking the fstype filesystem of source
+// according to fsckPolicy, or "" if it should not be checked. Filesystems
+// mounted read-only are only checked without modifying them.
+func fsckCommand(fstype, source string, readOnly bool, fsckPolicy string) (string, []string) {
+	switch fsckPolicy {
+	case common.FsckPolicyOnDirty:
+		// Same as mount-utils: e2fsck only checks filesystems which are not
+		// clean, and fsck.xfs does nothing as the log is replayed on mount.
+		if readOnly {
+			return "", nil
+		}
+		return "fsck", []string{"-a", source}
+	case common.FsckPolicyAlwaysReadOnlyCheck:
+		if fstype == fsTypeXFS {
+			return "xfs_repair", []string{"-n", source}
+		}
+		return "fsck", []string{"-n", "-f", source}
+	case common.FsckPolicyRepair:
+		if readOnly {
+			return "", nil
+		}
+		if fstype == fsTypeXFS {
+			return "xfs_repair", []string{source}
+		}
+		return "fsck", []string{"-y", "-f", source}
+	}
+	return "", nil
+}
+
+// fsckResult returns the result of a check run with tool according to
+// fsckPolicy from the error it returned.
+func fsckResult(tool, fsckPolicy string, err error) string {
+	if err == nil {
+		return fsckResultClean
+	}
+	if errors.Is(err, exec.ErrExecutableNotFound) {
+		return fsckResultSkipped
+	}
+	var exitErr exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fsckResultFailed
+	}
+	code := exitErr.ExitStatus()
+	if tool == "xfs_repair" {
+		if code == xfsRepairDirtyLog {
+			// The log is replayed when the filesystem is mounted.
+			return fsckResultSkipped
+		}
+		return fsckResultErrors
+	}
+	if fsckPolicy == common.FsckPolicyOnDirty {
+		// Same as mount-utils: only uncorrected errors alone keep the
+		// filesystem from being mounted.
+		switch {
+		case code == fsckErrorsUncorrected:
+			return fsckResultErrors
+		case code >= fsckOperationalError:
+			return fsckResultFailed
+		default:
+			return fsckResultCorrected
+		}
+	}
+	switch {
+	case code&fsckErrorsUncorrected != 0:
+		return fsckResultErrors
+	case code >= fsckOperationalError:
+		return fsckResultFailed
+	default:
+		return fsckResultCorrected
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+func TestNodeStageVolumeFsckPolicy(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+	mountCap := func(fsType string, mountFlags ...string) *csi.VolumeCapability {
+		return &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{FsType: fsType, MountFlags: mountFlags},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+			},
+		}
+	}
+	exitError := func(code int) error {
+		return exec.CodeExitError{Err: errors.New("exit status"), Code: code}
+	}
+
+	testCases := []struct {
+		name          string
+		volumeCap     *csi.VolumeCapability
+		volumeContext map[string]string
+		fsckCmd       *fakeCmd
+		expFsckErrors bool
+		expErrCode    codes.Code
+	}{
+		{
+			name:      "default policy",
+			volumeCap: mountCap("ext4"),
+			fsckCmd:   &fakeCmd{cmd: "fsck", args: "-a /dev/disk/fake-path", err: exitError(1)},
+		},
+		{
+			name:          "never",
+			volumeCap:     mountCap("ext4"),
+			volumeContext: map[string]string{common.ParameterKeyFsckPolicy: common.FsckPolicyNever},
+		},
+		{
+			name:          "repair from the volume context",
+			volumeCap:     mountCap("ext4"),
+			volumeContext: map[string]string{common.ParameterKeyFsckPolicy: common.FsckPolicyRepair},
+			fsckCmd:       &fakeCmd{cmd: "fsck", args: "-y -f /dev/disk/fake-path"},
+		},
+		{
+			name:          "mount flag takes precedence",
+			volumeCap:     mountCap("ext4", "fsck-policy=always-readonly-check"),
+			volumeContext: map[string]string{common.ParameterKeyFsckPolicy: common.FsckPolicyRepair},
+			fsckCmd:       &fakeCmd{cmd: "fsck", args: "-n -f /dev/disk/fake-path", err: exitError(4)},
+			expFsckErrors: true,
+		},
+		{
+			name:          "xfs read-only check",
+			volumeCap:     mountCap("xfs", "fsck-policy=always-readonly-check"),
+			fsckCmd:       &fakeCmd{cmd: "xfs_repair", args: "-n /dev/disk/fake-path", err: exitError(1)},
+			expFsckErrors: true,
+		},
+		{
+			name:       "uncorrected errors",
+			volumeCap:  mountCap("ext4", "fsck-policy=on-dirty"),
+			fsckCmd:    &fakeCmd{cmd: "fsck", args: "-a /dev/disk/fake-path", err: exitError(4)},
+			expErrCode: codes.Internal,
+		},
+		{
+			name:      "uncorrected errors with an operational error",
+			volumeCap: mountCap("ext4", "fsck-policy=on-dirty"),
+			fsckCmd:   &fakeCmd{cmd: "fsck", args: "-a /dev/disk/fake-path", err: exitError(12)},
+		},
+		{
+			name:       "uncorrected errors with an operational error on repair",
+			volumeCap:  mountCap("ext4", "fsck-policy=repair"),
+			fsckCmd:    &fakeCmd{cmd: "fsck", args: "-y -f /dev/disk/fake-path", err: exitError(12)},
+			expErrCode: codes.Internal,
+		},
+		{
+			name:       "invalid policy",
+			volumeCap:  mountCap("ext4", "fsck-policy=sometimes"),
+			expErrCode: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsType := tc.volumeCap.GetMount().GetFsType()
+			cmds := []fakeCmd{{cmd: "blkid", args: "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path", stdout: "DEVNAME=/dev/sdb\nTYPE=" + fsType}}
+			if tc.fsckCmd != nil {
+				cmds = append(cmds, *tc.fsckCmd)
+			}
+			if tc.expErrCode == codes.OK {
+				// The device is read-only, so that the filesystem is not resized.
+				cmds = append(cmds, fakeCmd{cmd: "blockdev", args: "--getro /dev/disk/fake-path", stdout: "1"})
+			}
+			actionList := []testingexec.FakeCommandAction{}
+			for _, cmd := range cmds {
+				action := []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(cmd.stdout), nil, cmd.err
+					},
+				}
+				actionList = append(actionList, makeFakeCmd(
+					&testingexec.FakeCmd{
+						CombinedOutputScript: action,
+						OutputScript:         action,
+					},
+					cmd.cmd,
+					strings.Split(cmd.args, " ")...,
+				))
+			}
+			mounter := mountmanager.NewFakeSafeMounterWithCustomExec(&testingexec.FakeExec{CommandScript: actionList, ExactOrder: true})
+			ns := getTestGCEDriverWithCustomMounter(t, mounter).ns
+
+			_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability:  tc.volumeCap,
+				VolumeContext:     tc.volumeContext,
+			})
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Fatalf("NodeStageVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+			if got := ns.fsckErrors.get(volumeID) != ""; got != tc.expFsckErrors {
+				t.Errorf("NodeStageVolume() recorded fsck errors %v, want %v", got, tc.expFsckErrors)
+			}
+		})
+	}
+}
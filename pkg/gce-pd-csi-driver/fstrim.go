@@ -0,0 +1,288 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+const (
+	// Triggers of the trims, as reported in metrics.
+	fstrimTriggerScheduled = "scheduled"
+	fstrimTriggerOnDemand  = "on-demand"
+
+	// Results of the trims, as reported in metrics.
+	fstrimResultSuccess = "success"
+	fstrimResultFailed  = "failed"
+
+	// fstrimCheckPeriod is how often the scheduler looks for volumes due for
+	// a trim.
+	fstrimCheckPeriod = time.Minute
+)
+
+// extractFstrimInterval returns the interval between two periodic trims of the
+// filesystem of a volume, or 0 if it is not trimmed periodically. The
+// fstrim-interval mount flag takes precedence over the fstrim-interval key of
+// the volume context.
+func extractFstrimInterval(volumeContext map[string]string, mountFlags []string) (time.Duration, error) {
+	interval := volumeContext[common.ParameterKeyFstrimInterval]
+	for _, mountFlag := range mountFlags {
+		if got := fstrimIntervalMountFlagRegex.FindStringSubmatch(mountFlag); len(got) == 2 {
+			interval = got[1]
+		}
+	}
+	if interval == "" {
+		return 0, nil
+	}
+	return common.ParseFstrimInterval(interval)
+}
+
+type fstrimVolume struct {
+	path     string
+	interval time.Duration
+	nextRun  time.Time
+	running  bool
+}
+
+// fstrimScheduler keeps, by volume ID, the staged filesystem volumes which may
+// be trimmed, and limits the number of trims running at the same time. Volumes
+// with an interval are trimmed periodically, the others only on demand.
+type fstrimScheduler struct {
+	mux     sync.Mutex
+	volumes map[string]*fstrimVolume
+	tokens  chan struct{}
+	now     func() time.Time
+}
+
+func newFstrimScheduler(maxConcurrent int) *fstrimScheduler {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &fstrimScheduler{
+		volumes: map[string]*fstrimVolume{},
+		tokens:  make(chan struct{}, maxConcurrent),
+		now:     time.Now,
+	}
+}
+
+// register records that the filesystem of volumeID is mounted at path. The
+// first periodic trim runs one interval later.
+func (s *fstrimScheduler) register(volumeID, path string, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	volume := &fstrimVolume{path: path, interval: interval}
+	if interval > 0 {
+		volume.nextRun = s.now().Add(interval)
+	}
+	if old, ok := s.volumes[volumeID]; ok {
+		// Repeated NodeStageVolume calls keep the schedule of the volume.
+		if old.path == path && old.interval == interval {
+			return
+		}
+		volume.running = old.running
+	}
+	s.volumes[volumeID] = volume
+}
+
+func (s *fstrimScheduler) unregister(volumeID string) {
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.volumes, volumeID)
+}
+
+// due returns the IDs of the volumes due for a periodic trim which are not
+// being trimmed.
+func (s *fstrimScheduler) due() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := s.now()
+	var volumeIDs []string
+	for volumeID, volume := range s.volumes {
+		if volume.interval > 0 && !volume.running && !now.Before(volume.nextRun) {
+			volumeIDs = append(volumeIDs, volumeID)
+		}
+	}
+	return volumeIDs
+}
+
+// start marks volumeID as being trimmed and returns the path where its
+// filesystem is mounted.
+func (s *fstrimScheduler) start(volumeID string) (string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	volume, ok := s.volumes[volumeID]
+	if !ok {
+		return "", status.Errorf(codes.NotFound, "volume %s is not staged with a filesystem on this node", volumeID)
+	}
+	if volume.running {
+		return "", status.Errorf(codes.Aborted, "volume %s is already being trimmed", volumeID)
+	}
+	volume.running = true
+	return volume.path, nil
+}
+
+// finish marks volumeID as no longer being trimmed, and schedules its next
+// periodic trim.
+func (s *fstrimScheduler) finish(volumeID string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if volume, ok := s.volumes[volumeID]; ok {
+		volume.running = false
+		if volume.interval > 0 {
+			volume.nextRun = s.now().Add(volume.interval)
+		}
+	}
+}
+
+func (s *fstrimScheduler) isRegistered(volumeID string) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	_, ok := s.volumes[volumeID]
+	return ok
+}
+
+// RunFstrimScheduler trims the filesystems of the staged volumes with an
+// fstrim interval, until ctx is done. It returns right away if the fstrim
+// scheduler is not enabled.
+func (ns *GCENodeServer) RunFstrimScheduler(ctx context.Context) {
+	if ns.fstrim == nil {
+		return
+	}
+	ticker := time.NewTicker(fstrimCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, volumeID := range ns.fstrim.due() {
+			go func() {
+				if _, err := ns.trimVolume(ctx, volumeID, fstrimTriggerScheduled); err != nil {
+					klog.Warningf("Scheduled trim of volume %s failed: %v", volumeID, err)
+				}
+			}()
+		}
+	}
+}
+
+// TrimVolume trims the filesystem of the staged volume volumeID on demand, and
+// returns the number of bytes trimmed.
+func (ns *GCENodeServer) TrimVolume(ctx context.Context, volumeID string) (int64, error) {
+	return ns.trimVolume(ctx, volumeID, fstrimTriggerOnDemand)
+}
+
+func (ns *GCENodeServer) trimVolume(ctx context.Context, volumeID, trigger string) (int64, error) {
+	if ns.fstrim == nil {
+		return 0, status.Error(codes.FailedPrecondition, "the fstrim scheduler is not enabled")
+	}
+	path, err := ns.fstrim.start(volumeID)
+	if err != nil {
+		return 0, err
+	}
+	defer ns.fstrim.finish(volumeID)
+
+	select {
+	case ns.fstrim.tokens <- struct{}{}:
+	case <-ctx.Done():
+		return 0, status.FromContextError(ctx.Err()).Err()
+	}
+	defer func() { <-ns.fstrim.tokens }()
+
+	// The volume lock keeps the volume from being unstaged while it is
+	// trimmed.
+	if acquired := ns.volumeLocks.Acquire(ctx, volumeID, "fstrim"); !acquired {
+		return 0, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
+	}
+	defer ns.volumeLocks.Release(volumeID)
+	if !ns.fstrim.isRegistered(volumeID) {
+		return 0, status.Errorf(codes.NotFound, "volume %s was unstaged", volumeID)
+	}
+
+	klog.V(4).Infof("Trimming the filesystem of volume %s at %s (%s)", volumeID, path, trigger)
+	start := time.Now()
+	trimmedBytes, err := trimFilesystem(path, ns.Mounter)
+	duration := time.Since(start)
+	result := fstrimResultSuccess
+	if err != nil {
+		result = fstrimResultFailed
+	}
+	if ns.metricsManager != nil {
+		ns.metricsManager.RecordFstrimMetric(trigger, result, trimmedBytes, duration)
+	}
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "failed to trim the filesystem of volume %s at %s: %v", volumeID, path, err.Error())
+	}
+	klog.V(4).Infof("Trimmed %d bytes from the filesystem of volume %s in %v", trimmedBytes, volumeID, duration)
+	return trimmedBytes, nil
+}
+
+type fstrimResponse struct {
+	VolumeID     string `json:"volumeID"`
+	TrimmedBytes int64  `json:"trimmedBytes"`
+}
+
+// FstrimHandler returns an HTTP handler trimming the filesystem of the staged
+// volume given by the volume_id query parameter of POST requests.
+func (ns *GCENodeServer) FstrimHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		volumeID := r.URL.Query().Get("volume_id")
+		if volumeID == "" {
+			http.Error(w, "the volume_id query parameter must be provided", http.StatusBadRequest)
+			return
+		}
+		trimmedBytes, err := ns.TrimVolume(r.Context(), volumeID)
+		if err != nil {
+			code := http.StatusInternalServerError
+			switch status.Code(err) {
+			case codes.NotFound:
+				code = http.StatusNotFound
+			case codes.Aborted:
+				code = http.StatusConflict
+			case codes.FailedPrecondition:
+				code = http.StatusPreconditionFailed
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(fstrimResponse{VolumeID: volumeID, TrimmedBytes: trimmedBytes}); err != nil {
+			klog.Errorf("Failed to write the fstrim response of volume %s: %v", volumeID, err)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/mount-utils"
+	testingexec "k8s.io/utils/exec/testing"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+func TestNodeStageVolumeFstrim(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+	stageCmds := []fakeCmd{
+		{cmd: "blkid", args: "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path", stdout: "DEVNAME=/dev/sdb\nTYPE=ext4"},
+		{cmd: "fsck", args: "-a /dev/disk/fake-path"},
+		// The device is read-only, so that the filesystem is not resized.
+		{cmd: "blockdev", args: "--getro /dev/disk/fake-path", stdout: "1"},
+	}
+
+	testCases := []struct {
+		name          string
+		mountFlags    []string
+		volumeContext map[string]string
+		disabled      bool
+		readOnly      bool
+		fstrimCmd     *fakeCmd
+		expInterval   time.Duration
+		expStageErr   codes.Code
+		expTrimErr    codes.Code
+		expTrimmed    int64
+	}{
+		{
+			name:          "interval from the volume context",
+			volumeContext: map[string]string{common.ParameterKeyFstrimInterval: "24h"},
+			fstrimCmd:     &fakeCmd{cmd: "fstrim", args: "-v " + stagingPath, stdout: stagingPath + ": 1 GiB (1073741824 bytes) trimmed\n"},
+			expInterval:   24 * time.Hour,
+			expTrimmed:    1073741824,
+		},
+		{
+			name:          "mount flag takes precedence",
+			mountFlags:    []string{"fstrim-interval=2h"},
+			volumeContext: map[string]string{common.ParameterKeyFstrimInterval: "24h"},
+			fstrimCmd:     &fakeCmd{cmd: "fstrim", args: "-v " + stagingPath, stdout: stagingPath + ": 4096 bytes were trimmed\n"},
+			expInterval:   2 * time.Hour,
+			expTrimmed:    4096,
+		},
+		{
+			name:       "only on demand",
+			fstrimCmd:  &fakeCmd{cmd: "fstrim", args: "-v " + stagingPath, err: &testingexec.FakeExitError{Status: 1}},
+			expTrimErr: codes.Internal,
+		},
+		{
+			name:          "read-only volume",
+			volumeContext: map[string]string{common.ParameterKeyFstrimInterval: "24h"},
+			readOnly:      true,
+			expTrimErr:    codes.NotFound,
+		},
+		{
+			name:        "interval too short",
+			mountFlags:  []string{"fstrim-interval=10m"},
+			expStageErr: codes.InvalidArgument,
+		},
+		{
+			name:       "scheduler disabled",
+			disabled:   true,
+			expTrimErr: codes.FailedPrecondition,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmds := []fakeCmd{}
+			mode := csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER
+			if tc.readOnly {
+				// Read-only volumes are neither checked nor resized.
+				cmds = append(cmds, stageCmds[0])
+				mode = csi.VolumeCapability_AccessMode_SINGLE_NODE_READER_ONLY
+			} else if tc.expStageErr == codes.OK {
+				cmds = append(cmds, stageCmds...)
+			}
+			if tc.fstrimCmd != nil {
+				cmds = append(cmds, *tc.fstrimCmd)
+			}
+			actionList := []testingexec.FakeCommandAction{}
+			for _, cmd := range cmds {
+				action := []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(cmd.stdout), nil, cmd.err
+					},
+				}
+				actionList = append(actionList, makeFakeCmd(
+					&testingexec.FakeCmd{
+						CombinedOutputScript: action,
+						OutputScript:         action,
+					},
+					cmd.cmd,
+					strings.Split(cmd.args, " ")...,
+				))
+			}
+			fakeExec := &testingexec.FakeExec{CommandScript: actionList, ExactOrder: true}
+			mounter := mountmanager.NewFakeSafeMounterWithCustomExec(fakeExec)
+			args := &NodeServerArgs{EnableFstrimScheduler: !tc.disabled}
+			ns := getCustomTestGCEDriver(t, mounter, deviceutils.NewFakeDeviceUtils(false), metadataservice.NewFakeService(), args).ns
+			now := time.Now()
+			if ns.fstrim != nil {
+				ns.fstrim.now = func() time.Time { return now }
+			}
+
+			_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{FsType: "ext4", MountFlags: tc.mountFlags},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: mode,
+					},
+				},
+				VolumeContext: tc.volumeContext,
+			})
+			if got := status.Code(err); got != tc.expStageErr {
+				t.Fatalf("NodeStageVolume() got error code %v, want %v: %v", got, tc.expStageErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if ns.fstrim != nil {
+				if due := ns.fstrim.due(); len(due) != 0 {
+					t.Errorf("Volumes %v are due for a trim right after they are staged", due)
+				}
+				now = now.Add(tc.expInterval)
+				due := ns.fstrim.due()
+				if expDue := tc.expInterval > 0 && !tc.readOnly; (len(due) == 1) != expDue {
+					t.Errorf("Volumes %v are due for a trim after %v, want volume due %v", due, tc.expInterval, expDue)
+				}
+			}
+
+			trimmed, err := ns.TrimVolume(context.Background(), volumeID)
+			if got := status.Code(err); got != tc.expTrimErr {
+				t.Fatalf("TrimVolume() got error code %v, want %v: %v", got, tc.expTrimErr, err)
+			}
+			if trimmed != tc.expTrimmed {
+				t.Errorf("TrimVolume() trimmed %d bytes, want %d", trimmed, tc.expTrimmed)
+			}
+			if fakeExec.CommandCalls != len(actionList) {
+				t.Errorf("Ran %d commands, want %d", fakeExec.CommandCalls, len(actionList))
+			}
+			if tc.expInterval > 0 {
+				if due := ns.fstrim.due(); len(due) != 0 {
+					t.Errorf("Volumes %v are due for a trim right after they are trimmed", due)
+				}
+			}
+		})
+	}
+}
+
+func TestNodeStageVolumeFstrimAlreadyMounted(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+	if err := os.MkdirAll(stagingPath, 0o750); err != nil {
+		t.Fatalf("Failed to create %s: %v", stagingPath, err)
+	}
+	mounter := mountmanager.NewFakeSafeMounter()
+	mounter.Interface.(*mount.FakeMounter).MountPoints = []mount.MountPoint{{Device: "/dev/sdb", Path: stagingPath, Type: "ext4"}}
+	args := &NodeServerArgs{EnableFstrimScheduler: true}
+	ns := getCustomTestGCEDriver(t, mounter, deviceutils.NewFakeDeviceUtils(false), metadataservice.NewFakeService(), args).ns
+	now := time.Now()
+	ns.fstrim.now = func() time.Time { return now }
+
+	// The volume is staged again after the driver restarted.
+	for i := 0; i < 2; i++ {
+		_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+			VolumeId:          volumeID,
+			StagingTargetPath: stagingPath,
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{FsType: "ext4", MountFlags: []string{"fstrim-interval=2h"}},
+				},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+			},
+		})
+		if err != nil {
+			t.Fatalf("NodeStageVolume() failed: %v", err)
+		}
+		// Repeated calls keep the schedule.
+		now = now.Add(time.Hour)
+	}
+	if due := ns.fstrim.due(); len(due) != 1 {
+		t.Errorf("Volumes %v are due for a trim two hours after they are staged, want %s", due, volumeID)
+	}
+}
+
+func TestFstrimHandler(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+	action := []testingexec.FakeAction{
+		func() ([]byte, []byte, error) {
+			return []byte(stagingPath + ": 1 MiB (1048576 bytes) trimmed\n"), nil, nil
+		},
+	}
+	fakeExec := &testingexec.FakeExec{CommandScript: []testingexec.FakeCommandAction{
+		makeFakeCmd(&testingexec.FakeCmd{CombinedOutputScript: action}, "fstrim", "-v", stagingPath),
+	}, ExactOrder: true}
+	args := &NodeServerArgs{EnableFstrimScheduler: true}
+	ns := getCustomTestGCEDriver(t, mountmanager.NewFakeSafeMounterWithCustomExec(fakeExec), deviceutils.NewFakeDeviceUtils(false), metadataservice.NewFakeService(), args).ns
+	ns.fstrim.register(volumeID, stagingPath, 0)
+	handler := ns.FstrimHandler()
+
+	testCases := []struct {
+		name     string
+		method   string
+		volumeID string
+		expCode  int
+		expBody  string
+	}{
+		{
+			name:     "trim",
+			method:   http.MethodPost,
+			volumeID: volumeID,
+			expCode:  http.StatusOK,
+			expBody:  `{"volumeID":"` + volumeID + `","trimmedBytes":1048576}`,
+		},
+		{
+			name:     "not a POST",
+			method:   http.MethodGet,
+			volumeID: volumeID,
+			expCode:  http.StatusMethodNotAllowed,
+		},
+		{
+			name:    "no volume ID",
+			method:  http.MethodPost,
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:     "volume not staged",
+			method:   http.MethodPost,
+			volumeID: "project/test001/zones/c1/disks/otherDisk",
+			expCode:  http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/fstrim?volume_id="+url.QueryEscape(tc.volumeID), nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.expCode {
+				t.Fatalf("Got status code %d, want %d: %s", rec.Code, tc.expCode, rec.Body.String())
+			}
+			if tc.expBody != "" && strings.TrimSpace(rec.Body.String()) != tc.expBody {
+				t.Errorf("Got body %s, want %s", rec.Body.String(), tc.expBody)
+			}
+		})
+	}
+}
@@ -81,6 +81,8 @@ func (gceDriver *GCEDriver) SetupGCEDriver(name, vendorVersion string, extraVolu
 		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
 		csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
 		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+		csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP,
 	}
 	gceDriver.AddNodeServiceCapabilities(ns)
 
@@ -146,12 +148,16 @@ func NewIdentityServer(gceDriver *GCEDriver) *GCEIdentityServer {
 }
 
 func NewNodeServer(gceDriver *GCEDriver, mounter *mount.SafeFormatAndMount, deviceUtils deviceutils.DeviceUtils, meta metadataservice.MetadataService, statter mountmanager.Statter, args *NodeServerArgs) *GCENodeServer {
+	var fstrim *fstrimScheduler
+	if args.EnableFstrimScheduler {
+		fstrim = newFstrimScheduler(args.FstrimMaxConcurrent)
+	}
 	return &GCENodeServer{
 		Driver:                   gceDriver,
 		Mounter:                  mounter,
 		DeviceUtils:              deviceUtils,
 		MetadataService:          meta,
-		volumeLocks:              common.NewVolumeLocks(),
+		volumeLocks:              common.NewQueuedVolumeLocks(common.NewVolumeLocks(), args.VolumeLockWait),
 		VolumeStatter:            statter,
 		enableDeviceInUseCheck:   args.EnableDeviceInUseCheck,
 		deviceInUseErrors:        newDeviceErrMap(args.DeviceInUseTimeout),
@@ -159,15 +165,27 @@ func NewNodeServer(gceDriver *GCEDriver, mounter *mount.SafeFormatAndMount, devi
 		DataCacheEnabledNodePool: args.DataCacheEnabledNodePool,
 		SysfsPath:                args.SysfsPath,
 		metricsManager:           args.MetricsManager,
+		nodeIDIncludesInstanceID: args.NodeIDIncludesInstanceID,
+		defaultFsckPolicy:        args.DefaultFsckPolicy,
+		fsckErrors:               newFsckErrorMap(),
+		queueSettings:            newQueueSettingsMap(),
+		diskTuningProfiles:       args.DiskTuningProfiles,
+		fstrim:                   fstrim,
+		kubeletRootDir:           args.KubeletRootDir,
+		staleMountDryRun:         args.StaleMountDryRun,
 	}
 }
 
 func NewControllerServer(gceDriver *GCEDriver, cloudProvider gce.GCECompute, errorBackoffInitialDuration, errorBackoffMaxDuration time.Duration, fallbackRequisiteZones []string, enableStoragePools bool, enableDataCache bool, multiZoneVolumeHandleConfig MultiZoneVolumeHandleConfig, listVolumesConfig ListVolumesConfig, provisionableDisksConfig ProvisionableDisksConfig, enableHdHA bool, args *GCEControllerServerArgs) *GCEControllerServer {
+	var volumeLocks common.VolumeLocker = common.NewVolumeLocks()
+	if args.VolumeLocks != nil {
+		volumeLocks = args.VolumeLocks
+	}
 	return &GCEControllerServer{
 		Driver:                      gceDriver,
 		CloudProvider:               cloudProvider,
 		volumeEntriesSeen:           map[string]int{},
-		volumeLocks:                 common.NewVolumeLocks(),
+		volumeLocks:                 common.NewQueuedVolumeLocks(volumeLocks, args.VolumeLockWait),
 		errorBackoff:                newCsiErrorBackoff(errorBackoffInitialDuration, errorBackoffMaxDuration),
 		fallbackRequisiteZones:      fallbackRequisiteZones,
 		enableStoragePools:          enableStoragePools,
@@ -177,6 +195,9 @@ func NewControllerServer(gceDriver *GCEDriver, cloudProvider gce.GCECompute, err
 		provisionableDisksConfig:    provisionableDisksConfig,
 		enableHdHA:                  enableHdHA,
 		EnableDiskTopology:          args.EnableDiskTopology,
+		accessPolicy:                args.AccessPolicy,
+		nodeIDIncludesInstanceID:    args.NodeIDIncludesInstanceID,
+		tenantResolver:              args.TenantResolver,
 	}
 }
 
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -56,8 +57,9 @@ type GCENodeServer struct {
 	SysfsPath                string
 
 	// A map storing all volumes with ongoing operations so that additional operations
-	// for that same volume (as defined by VolumeID) return an Aborted error
-	volumeLocks *common.VolumeLocks
+	// for that same volume (as defined by VolumeID) wait for it, up to the configured
+	// wait, then return an Aborted error
+	volumeLocks *common.QueuedVolumeLocks
 
 	// enableDeviceInUseCheck, if true, will block NodeUnstageVolume request if the specified
 	// device is still in use (or until --device-in-use-timeout is reached, if specified)
@@ -75,11 +77,41 @@ type GCENodeServer struct {
 	formatAndMountSemaphore chan any
 	formatAndMountTimeout   time.Duration
 
+	// If set, this semaphore limits the number of concurrent formats of
+	// disks, as the formats are run by the driver rather than by mount-utils.
+	// A format keeps its token until it is finished, or formatTimeout has
+	// expired.
+	formatSemaphore chan any
+	formatTimeout   time.Duration
+
 	// Embed UnimplementedNodeServer to ensure the driver returns Unimplemented for any
 	// new RPC methods that might be introduced in future versions of the spec.
 	csi.UnimplementedNodeServer
 
 	metricsManager *metrics.MetricsManager
+
+	// If set to true, NodeGetInfo qualifies the node ID with the numeric ID
+	// of the instance.
+	nodeIDIncludesInstanceID bool
+
+	// defaultFsckPolicy is the fsck policy of volumes without one in their
+	// volume context or mount flags.
+	defaultFsckPolicy string
+	// fsckErrors keeps the errors the filesystem checks left on the
+	// filesystems of staged volumes, reported as volume conditions.
+	fsckErrors *fsckErrorMap
+	// queueSettings keeps the block queue settings of staged volumes.
+	queueSettings *queueSettingsMap
+	// diskTuningProfiles are the mount flags applied by default to the
+	// volumes of each disk type.
+	diskTuningProfiles DiskTuningProfiles
+	// fstrim trims the filesystems of staged volumes, or is nil if the fstrim
+	// scheduler is not enabled.
+	fstrim *fstrimScheduler
+	// kubeletRootDir is the root directory of kubelet.
+	kubeletRootDir string
+	// If set to true, stale staging and publish directories are only logged.
+	staleMountDryRun bool
 }
 
 type NodeServerArgs struct {
@@ -97,6 +129,38 @@ type NodeServerArgs struct {
 	SysfsPath string
 
 	MetricsManager *metrics.MetricsManager
+
+	// VolumeLockWait configures waiting for the lock of a volume.
+	VolumeLockWait common.VolumeLockWaitConfig
+
+	// NodeIDIncludesInstanceID qualifies the node ID with the numeric ID of
+	// the instance, so that the controller does not attach or detach disks of
+	// another instance recreated with the same name.
+	NodeIDIncludesInstanceID bool
+
+	// DefaultFsckPolicy is the fsck policy of volumes without one in their
+	// volume context or mount flags. Defaults to on-dirty.
+	DefaultFsckPolicy string
+
+	// DiskTuningProfiles are the mount flags applied by default to the
+	// volumes of each disk type.
+	DiskTuningProfiles DiskTuningProfiles
+
+	// EnableFstrimScheduler enables the periodic trim of the filesystems of
+	// the staged volumes with an fstrim interval, and trims on demand.
+	EnableFstrimScheduler bool
+
+	// FstrimMaxConcurrent is the maximum number of trims running at the same
+	// time. Defaults to 1.
+	FstrimMaxConcurrent int
+
+	// KubeletRootDir is the root directory of kubelet, in which
+	// RunStaleMountReconciler looks for stale staging and publish directories.
+	KubeletRootDir string
+
+	// StaleMountDryRun makes RunStaleMountReconciler only log the stale
+	// directories, without unmounting or removing them.
+	StaleMountDryRun bool
 }
 
 var _ csi.NodeServer = &GCENodeServer{}
@@ -119,15 +183,19 @@ const (
 	fsTypeExt3                 = "ext3"
 	fsTypeBtrfs                = "btrfs"
 
-	readAheadKBMountFlagRegexPattern = "^read_ahead_kb=(.+)$"
-	btrfsReclaimDataRegexPattern     = "^btrfs-allocation-data-bg_reclaim_threshold=(\\d{1,2})$"     // 0-99 are valid, incl. 00
-	btrfsReclaimMetadataRegexPattern = "^btrfs-allocation-metadata-bg_reclaim_threshold=(\\d{1,2})$" // ditto ^
+	readAheadKBMountFlagRegexPattern    = "^read_ahead_kb=(.+)$"
+	btrfsReclaimDataRegexPattern        = "^btrfs-allocation-data-bg_reclaim_threshold=(\\d{1,2})$"     // 0-99 are valid, incl. 00
+	btrfsReclaimMetadataRegexPattern    = "^btrfs-allocation-metadata-bg_reclaim_threshold=(\\d{1,2})$" // ditto ^
+	fsckPolicyMountFlagRegexPattern     = "^fsck-policy=(.+)$"
+	fstrimIntervalMountFlagRegexPattern = "^fstrim-interval=(.+)$"
 )
 
 var (
-	readAheadKBMountFlagRegex = regexp.MustCompile(readAheadKBMountFlagRegexPattern)
-	btrfsReclaimDataRegex     = regexp.MustCompile(btrfsReclaimDataRegexPattern)
-	btrfsReclaimMetadataRegex = regexp.MustCompile(btrfsReclaimMetadataRegexPattern)
+	readAheadKBMountFlagRegex    = regexp.MustCompile(readAheadKBMountFlagRegexPattern)
+	btrfsReclaimDataRegex        = regexp.MustCompile(btrfsReclaimDataRegexPattern)
+	btrfsReclaimMetadataRegex    = regexp.MustCompile(btrfsReclaimMetadataRegexPattern)
+	fsckPolicyMountFlagRegex     = regexp.MustCompile(fsckPolicyMountFlagRegexPattern)
+	fstrimIntervalMountFlagRegex = regexp.MustCompile(fstrimIntervalMountFlagRegexPattern)
 )
 
 func getDefaultFsType() string {
@@ -161,6 +229,16 @@ func (ns *GCENodeServer) WithSerializedFormatAndMount(timeout time.Duration, max
 	return ns
 }
 
+// WithMaxConcurrentFormat limits the number of concurrent formats of disks to
+// maxConcurrent. It does not limit them if maxConcurrent < 1.
+func (ns *GCENodeServer) WithMaxConcurrentFormat(timeout time.Duration, maxConcurrent int) *GCENodeServer {
+	if maxConcurrent > 0 {
+		ns.formatSemaphore = make(chan any, maxConcurrent)
+		ns.formatTimeout = timeout
+	}
+	return ns
+}
+
 func (ns *GCENodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
 	// Validate Arguments
 	targetPath := req.GetTargetPath()
@@ -181,7 +259,7 @@ func (ns *GCENodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePub
 		return nil, status.Error(codes.InvalidArgument, "NodePublishVolume Volume Capability must be provided")
 	}
 
-	if acquired := ns.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := ns.volumeLocks.Acquire(ctx, volumeID, "NodePublishVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer ns.volumeLocks.Release(volumeID)
@@ -213,7 +291,22 @@ func (ns *GCENodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePub
 		}
 
 		klog.V(4).Infof("NodePublishVolume with filesystem %s", fstype)
-		options = append(options, collectMountOptions(fstype, mnt.MountFlags)...)
+		options = append(options, collectMountOptions(mnt.MountFlags)...)
+
+		volumeMountGroup, err := parseVolumeMountGroup(mnt.GetVolumeMountGroup())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		// The volume mount group may differ from the one the volume was
+		// staged with. It is set up at the staging path, so that the root
+		// directory is ready when the volume shows up at the target path.
+		// Filesystems with a gid mount option keep the group they were staged
+		// with.
+		if volumeMountGroup >= 0 && !readOnly && !supportsGidMountOption(fstype) {
+			if err := setVolumeMountGroup(stagingTargetPath, volumeMountGroup); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to set the volume mount group of %s to %d: %v", stagingTargetPath, volumeMountGroup, err.Error())
+			}
+		}
 
 		sourcePath = stagingTargetPath
 		if err := preparePublishPath(targetPath, ns.Mounter); err != nil {
@@ -305,7 +398,7 @@ func (ns *GCENodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeU
 		return nil, status.Error(codes.InvalidArgument, "NodeUnpublishVolume Target Path must be provided")
 	}
 
-	if acquired := ns.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := ns.volumeLocks.Acquire(ctx, volumeID, "NodeUnpublishVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer ns.volumeLocks.Release(volumeID)
@@ -333,7 +426,7 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		return nil, status.Error(codes.InvalidArgument, "NodeStageVolume Volume Capability must be provided")
 	}
 
-	if acquired := ns.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := ns.volumeLocks.Acquire(ctx, volumeID, "NodeStageVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer ns.volumeLocks.Release(volumeID)
@@ -349,6 +442,18 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("NodeStageVolume Volume ID is invalid: %v", err.Error()))
 	}
 
+	diskType := req.GetPublishContext()[common.ContextDiskType]
+	profile := ns.diskTuningProfiles[diskType]
+	if len(profile) > 0 {
+		klog.V(4).Infof("Applying tuning profile %v of disk type %s (provisioned IOPS %q, throughput %q) to volume %s", profile, diskType,
+			req.GetPublishContext()[common.ContextProvisionedIOPS], req.GetPublishContext()[common.ContextProvisionedThroughput], volumeID)
+	}
+
+	queueSettings, err := extractQueueSettings(req.GetVolumeContext(), profile, volumeCapability.GetMount().GetMountFlags())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failure parsing block queue settings: %v", err.Error())
+	}
+
 	// Part 1: Get device path of attached device
 	partition := ""
 
@@ -387,8 +492,29 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		}
 	}
 
+	// The block queue settings are applied even if the volume is already
+	// mounted, as they are lost when the disk is attached again.
+	if err := ns.applyQueueSettings(volumeID, queueSettings); err != nil {
+		return nil, status.Errorf(codes.Internal, "failure applying block queue settings: %v", err.Error())
+	}
+	ns.queueSettings.set(volumeID, queueSettings)
+
+	readonly, _ := getReadOnlyFromCapability(volumeCapability)
+	var fstrimInterval time.Duration
+	if mnt := volumeCapability.GetMount(); mnt != nil {
+		fstrimInterval, err = extractFstrimInterval(req.GetVolumeContext(), applyTuningProfile(profile, mnt.MountFlags))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failure parsing fstrim interval: %v", err.Error())
+		}
+	}
+
 	// Part 2: Check if mount already exists at stagingTargetPath
 	if ns.isVolumePathMounted(stagingTargetPath) {
+		// The fstrim scheduler only keeps its volumes in memory, so the
+		// volumes staged before the driver restarted are registered again.
+		if volumeCapability.GetMount() != nil && !readonly {
+			ns.fstrim.register(volumeID, stagingTargetPath, fstrimInterval)
+		}
 		klog.V(4).Infof("NodeStageVolume succeeded on volume %v to %s, mount already exists.", volumeID, stagingTargetPath)
 		return &csi.NodeStageVolumeResponse{}, nil
 	}
@@ -404,19 +530,42 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 	shouldUpdateReadAhead := false
 	var readAheadKB int64
 	options := []string{}
+	var formatOptions []string
+	var fsckPolicy string
+	volumeMountGroup := -1
 	if mnt := volumeCapability.GetMount(); mnt != nil {
 		if mnt.FsType != "" {
 			fstype = mnt.FsType
 		}
-		options = collectMountOptions(fstype, mnt.MountFlags)
+		mountFlags := applyTuningProfile(profile, mnt.MountFlags)
+		options = collectMountOptions(mountFlags)
 
-		readAheadKB, shouldUpdateReadAhead, err = extractReadAheadKBMountFlag(mnt.MountFlags)
+		volumeMountGroup, err = parseVolumeMountGroup(mnt.GetVolumeMountGroup())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		options = append(options, volumeMountGroupOptions(fstype, volumeMountGroup, options)...)
+
+		readAheadKB, shouldUpdateReadAhead, err = extractReadAheadKBMountFlag(mountFlags)
 		if err != nil {
 			return nil, status.Errorf(codes.InvalidArgument, "failure parsing mount flags: %v", err.Error())
 		}
 
 		if mnt.FsType == fsTypeBtrfs {
-			btrfsReclaimData, btrfsReclaimMetadata = extractBtrfsReclaimFlags(mnt.MountFlags)
+			btrfsReclaimData, btrfsReclaimMetadata = extractBtrfsReclaimFlags(mountFlags)
+		}
+
+		// The volume context of statically provisioned volumes is not validated
+		// by the controller, so the mkfs options are validated again. They are
+		// only used if the disk is not formatted yet.
+		formatOptions, err = common.ParseMkfsOptions(fstype, req.GetVolumeContext()[common.MkfsOptionsKey(fstype)])
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failure parsing mkfs options: %v", err.Error())
+		}
+
+		fsckPolicy, err = extractFsckPolicy(req.GetVolumeContext(), mnt.MountFlags, ns.defaultFsckPolicy)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failure parsing fsck policy: %v", err.Error())
 		}
 	} else if blk := volumeCapability.GetBlock(); blk != nil {
 		// Noop for Block NodeStageVolume
@@ -424,13 +573,20 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		return &csi.NodeStageVolumeResponse{}, nil
 	}
 
-	readonly, _ := getReadOnlyFromCapability(volumeCapability)
 	if readonly {
 		options = append(options, "ro")
 		klog.V(4).Infof("CSI volume is read-only, mounting with extra option ro")
 	}
 
-	err = ns.formatAndMount(devicePath, stagingTargetPath, fstype, options, ns.Mounter)
+	// By default, xfs and btrfs do not allow mounting two filesystems with the
+	// same UUID, like a volume and its clone or restored snapshot.
+	uuidOptions, err := ns.resolveDuplicateFilesystemUUID(volumeID, devicePath, fstype, readonly)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, uuidOptions...)
+
+	err = ns.formatAndMount(volumeID, devicePath, stagingTargetPath, fstype, options, formatOptions, fsckPolicy, ns.Mounter)
 	if err != nil {
 		// If a volume is created from a content source like snapshot or cloning, the filesystem might get marked
 		// as "dirty" even if it is otherwise consistent and ext3/4 will try to restore to a consistent state by replaying
@@ -441,7 +597,7 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 			klog.V(4).Infof("Failed to mount CSI volume read-only, retry mounting with extra option noload")
 
 			options = append(options, "noload")
-			err = ns.formatAndMount(devicePath, stagingTargetPath, fstype, options, ns.Mounter)
+			err = ns.formatAndMount(volumeID, devicePath, stagingTargetPath, fstype, options, formatOptions, fsckPolicy, ns.Mounter)
 			if err == nil {
 				klog.V(4).Infof("NodeStageVolume succeeded with \"noload\" option on %v to %s", volumeID, stagingTargetPath)
 				return &csi.NodeStageVolumeResponse{}, nil
@@ -507,6 +663,19 @@ func (ns *GCENodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStage
 		}
 	}
 
+	// Part 7: Give the volume mount group ownership of the root directory,
+	// so that kubelet does not change the group of all the files.
+	if volumeMountGroup >= 0 && !readonly && !hasGidMountOption(fstype, options) {
+		if err := setVolumeMountGroup(stagingTargetPath, volumeMountGroup); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to set the volume mount group of %s to %d: %v", stagingTargetPath, volumeMountGroup, err.Error())
+		}
+	}
+
+	// Read-only filesystems cannot be trimmed.
+	if !readonly {
+		ns.fstrim.register(volumeID, stagingTargetPath, fstrimInterval)
+	}
+
 	klog.V(4).Infof("NodeStageVolume succeeded on %v to %s", volumeID, stagingTargetPath)
 	return &csi.NodeStageVolumeResponse{}, nil
 }
@@ -587,7 +756,7 @@ func (ns *GCENodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUns
 		return nil, status.Error(codes.InvalidArgument, "NodeUnstageVolume Staging Target Path must be provided")
 	}
 
-	if acquired := ns.volumeLocks.TryAcquire(volumeID); !acquired {
+	if acquired := ns.volumeLocks.Acquire(ctx, volumeID, "NodeUnstageVolume"); !acquired {
 		return nil, status.Errorf(codes.Aborted, common.VolumeOperationAlreadyExistsFmt, volumeID)
 	}
 	defer ns.volumeLocks.Release(volumeID)
@@ -611,6 +780,10 @@ func (ns *GCENodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUns
 		ns.deviceInUseErrors.deleteDevice(volumeID)
 	}
 
+	ns.fsckErrors.delete(volumeID)
+	ns.queueSettings.delete(volumeID)
+	ns.fstrim.unregister(volumeID)
+
 	// The NodeUnstageVolume does not have any volume or publish context, we need to get the info from LVM locally
 	// Check if cache group cache-{volumeID} exist in LVM
 	if ns.EnableDataCache && ns.DataCacheEnabledNodePool {
@@ -628,23 +801,37 @@ func (ns *GCENodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUns
 type ignoreableError struct{ error }
 
 func (ns *GCENodeServer) confirmDeviceUnused(volumeID string) error {
+	devFsPath, holders, err := ns.deviceHolders(volumeID)
+	if err != nil {
+		return &ignoreableError{err}
+	}
+	// Only a mounted filesystem blocks unstaging. The other holders, like the
+	// device-mapper device of the data cache which is torn down afterwards,
+	// are only reported.
+	if holders.FilesystemInUse {
+		return fmt.Errorf("device %s is still in use: %s", devFsPath, holders)
+	}
+
+	return nil
+}
+
+// deviceHolders returns the device of a volume, and what still holds it.
+func (ns *GCENodeServer) deviceHolders(volumeID string) (string, *deviceutils.DeviceHolders, error) {
 	devicePath, err := getDevicePath(ns, volumeID, "" /* partition, which is unused */)
 	if err != nil {
-		return &ignoreableError{fmt.Errorf("failed to find device path for volume %s: %v", volumeID, err.Error())}
+		return "", nil, fmt.Errorf("failed to find device path for volume %s: %v", volumeID, err.Error())
 	}
 
 	devFsPath, err := filepath.EvalSymlinks(devicePath)
 	if err != nil {
-		return &ignoreableError{fmt.Errorf("filepath.EvalSymlinks(%q) failed: %v", devicePath, err)}
+		return "", nil, fmt.Errorf("filepath.EvalSymlinks(%q) failed: %v", devicePath, err)
 	}
 
-	if inUse, err := ns.DeviceUtils.IsDeviceFilesystemInUse(ns.Mounter, devicePath, devFsPath); err != nil {
-		return &ignoreableError{fmt.Errorf("failed to check if device %s (aka %s) is in use: %v", devicePath, devFsPath, err)}
-	} else if inUse {
-		return fmt.Errorf("device %s (aka %s) is still in use", devicePath, devFsPath)
+	holders, err := ns.DeviceUtils.IsDeviceFilesystemInUse(ns.Mounter, devicePath, devFsPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to check if device %s (aka %s) is in use: %v", devicePath, devFsPath, err)
 	}
-
-	return nil
+	return devFsPath, holders, nil
 }
 
 func (ns *GCENodeServer) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
@@ -659,6 +846,9 @@ func (ns *GCENodeServer) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoRe
 	}
 
 	nodeID := common.CreateNodeID(ns.MetadataService.GetProject(), ns.MetadataService.GetZone(), ns.MetadataService.GetName())
+	if ns.nodeIDIncludesInstanceID {
+		nodeID = common.CreateNodeIDWithInstanceID(ns.MetadataService.GetProject(), ns.MetadataService.GetZone(), ns.MetadataService.GetName(), ns.MetadataService.GetInstanceID())
+	}
 
 	volumeLimits, err := ns.GetVolumeLimits(ctx)
 	if err != nil {
@@ -707,6 +897,7 @@ func (ns *GCENodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGe
 					Total: bcap,
 				},
 			},
+			VolumeCondition: ns.getVolumeCondition(req.VolumeId, req.VolumePath),
 		}, nil
 	}
 	available, capacity, used, inodesFree, inodes, inodesUsed, err := ns.VolumeStatter.StatFS(req.VolumePath)
@@ -729,6 +920,7 @@ func (ns *GCENodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGe
 				Used:      inodesUsed,
 			},
 		},
+		VolumeCondition: ns.getVolumeCondition(req.VolumeId, req.VolumePath),
 	}, nil
 }
 
@@ -758,6 +950,9 @@ func (ns *GCENodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpa
 		return nil, status.Error(codes.Internal, fmt.Sprintf("error when getting device path for %s: %v", volumeID, err.Error()))
 	}
 
+	// The block queue settings of the volume are applied again once it is
+	// expanded.
+	queueSettings := ns.queueSettings.get(volumeID)
 	volumeCapability := req.GetVolumeCapability()
 	if volumeCapability != nil {
 		// VolumeCapability is optional, if specified, validate it
@@ -765,7 +960,16 @@ func (ns *GCENodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpa
 			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("VolumeCapability is invalid: %v", err.Error()))
 		}
 
+		mountFlagSettings, err := common.ParseQueueSettings(volumeCapability.GetMount().GetMountFlags())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failure parsing block queue settings: %v", err.Error())
+		}
+		maps.Copy(queueSettings, mountFlagSettings)
+
 		if blk := volumeCapability.GetBlock(); blk != nil {
+			if err := ns.applyQueueSettings(volumeID, queueSettings); err != nil {
+				return nil, status.Errorf(codes.Internal, "failure applying block queue settings: %v", err.Error())
+			}
 			// Noop for Block NodeExpandVolume
 			klog.V(4).Infof("NodeExpandVolume succeeded on %v to %s, capability is block so this is a no-op", volumeID, volumePath)
 			return &csi.NodeExpandVolumeResponse{}, nil
@@ -789,6 +993,10 @@ func (ns *GCENodeServer) NodeExpandVolume(ctx context.Context, req *csi.NodeExpa
 
 	}
 
+	if err := ns.applyQueueSettings(volumeID, queueSettings); err != nil {
+		return nil, status.Errorf(codes.Internal, "failure applying block queue settings: %v", err.Error())
+	}
+
 	diskSizeBytes, err := getBlockSizeBytes(devicePath, ns.Mounter)
 	if diskSizeBytes < reqBytes {
 		// It's possible that the somewhere the volume size was rounded up, getting more size than requested is a success :)
@@ -17,6 +17,7 @@ package gceGCEDriver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -32,6 +33,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"k8s.io/mount-utils"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
 	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
 	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
@@ -371,6 +373,22 @@ func TestNodeGetVolumeLimits(t *testing.T) {
 	}
 }
 
+func TestNodeGetInfoNodeID(t *testing.T) {
+	for includeInstanceID, want := range map[bool]string{
+		false: common.CreateNodeID(metadataservice.FakeProject, metadataservice.FakeZone, metadataservice.FakeName),
+		true:  common.CreateNodeIDWithInstanceID(metadataservice.FakeProject, metadataservice.FakeZone, metadataservice.FakeName, metadataservice.FakeInstanceID),
+	} {
+		gceDriver := getCustomTestGCEDriver(t, mountmanager.NewFakeSafeMounter(), deviceutils.NewFakeDeviceUtils(false), metadataservice.NewFakeService(), &NodeServerArgs{NodeIDIncludesInstanceID: includeInstanceID})
+		res, err := gceDriver.ns.NodeGetInfo(context.Background(), &csi.NodeGetInfoRequest{})
+		if err != nil {
+			t.Fatalf("NodeGetInfo() failed: %v", err)
+		}
+		if res.GetNodeId() != want {
+			t.Errorf("NodeGetInfo() with instance ID %v got node ID %q, want %q", includeInstanceID, res.GetNodeId(), want)
+		}
+	}
+}
+
 func TestNodePublishVolume(t *testing.T) {
 	gceDriver := getTestGCEDriver(t)
 	ns := gceDriver.ns
@@ -654,6 +672,75 @@ func TestNodeStageVolume(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid request, mkfs options on first format",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability:  stdVolCap,
+				VolumeContext:     map[string]string{common.ParameterKeyMkfsOptionsExt4: "-i 8192 -O ^metadata_csum"},
+			},
+			deviceSize:   1,
+			blockExtSize: 1,
+			readonlyBit:  "1",
+			expResize:    false,
+			expCommandList: []fakeCmd{
+				{
+					cmd:  "blkid",
+					args: "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path",
+					err:  exec.CodeExitError{Err: errors.New("exit status 2"), Code: 2},
+				},
+				{
+					cmd:  "mkfs.ext4",
+					args: "-i 8192 -O ^metadata_csum -F -m0 /dev/disk/fake-path",
+				},
+				{
+					cmd:    "blockdev",
+					args:   "--getro /dev/disk/fake-path",
+					stdout: "%v",
+				},
+			},
+		},
+		{
+			name: "Valid request, mkfs options ignored on formatted disk",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability:  stdVolCap,
+				VolumeContext:     map[string]string{common.ParameterKeyMkfsOptionsExt4: "-i 8192"},
+			},
+			deviceSize:   1,
+			blockExtSize: 1,
+			readonlyBit:  "1",
+			expResize:    false,
+			expCommandList: []fakeCmd{
+				{
+					cmd:    "blkid",
+					args:   "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path",
+					stdout: "DEVNAME=/dev/sdb\nTYPE=%v",
+				},
+				{
+					cmd:    "fsck",
+					args:   "-a /dev/disk/fake-path",
+					stdout: "",
+				},
+				{
+					cmd:    "blockdev",
+					args:   "--getro /dev/disk/fake-path",
+					stdout: "%v",
+				},
+			},
+		},
+		{
+			name: "Invalid request (disallowed mkfs option)",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability:  stdVolCap,
+				VolumeContext:     map[string]string{common.ParameterKeyMkfsOptionsExt4: "-i 8192 /dev/sdc"},
+			},
+			expErrCode: codes.InvalidArgument,
+		},
 		{
 			name: "Valid request, resize bc size",
 			req: &csi.NodeStageVolumeRequest{
@@ -1131,7 +1218,7 @@ func TestNodeStageVolume(t *testing.T) {
 						cmd.args = fmt.Sprintf(cmd.args, tc.readAheadSectors)
 					}
 				case "blkid":
-					if strings.Contains(cmd.args, "TYPE") {
+					if strings.Contains(cmd.args, "TYPE") && cmd.stdout != "" {
 						cmd.stdout = fmt.Sprintf(cmd.stdout, fsType)
 					}
 				case "btrfs":
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+// queueSchedulerSetting is applied before the other block queue settings, as
+// changing the scheduler resets nr_requests.
+const queueSchedulerSetting = "scheduler"
+
+// extractQueueSettings returns the block queue settings of a volume, by
+// attribute name. The queue/<name>=<value> mount flags take precedence over
+// the queue-settings key of the volume context, which takes precedence over
+// the tuning profile of the disk type.
+func extractQueueSettings(volumeContext map[string]string, profile, mountFlags []string) (map[string]string, error) {
+	settings, err := common.ParseQueueSettings(profile)
+	if err != nil {
+		return nil, err
+	}
+	contextSettings, err := common.ParseQueueSettingsParameter(volumeContext[common.ParameterKeyQueueSettings])
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(settings, contextSettings)
+	mountFlagSettings, err := common.ParseQueueSettings(mountFlags)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(settings, mountFlagSettings)
+	return settings, nil
+}
+
+// applyQueueSettings writes the block queue settings of volumeID to the queue
+// attributes of its disk under SysfsPath.
+func (ns *GCENodeServer) applyQueueSettings(volumeID string, settings map[string]string) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	// The settings apply to the disk, even if a partition or a data cache
+	// logical volume of it is mounted.
+	devicePath, err := getDevicePath(ns, volumeID, "" /* partition */)
+	if err != nil {
+		return err
+	}
+	devFsPath, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return fmt.Errorf("filepath.EvalSymlinks(%q) failed: %w", devicePath, err)
+	}
+	queuePath := filepath.Join(ns.SysfsPath, "block", filepath.Base(devFsPath), "queue")
+
+	names := slices.Sorted(maps.Keys(settings))
+	if i := slices.Index(names, queueSchedulerSetting); i > 0 {
+		names = slices.Insert(slices.Delete(names, i, i+1), 0, queueSchedulerSetting)
+	}
+	for _, name := range names {
+		path := filepath.Join(queuePath, name)
+		if err := writeSysfs(path, settings[name]); err != nil {
+			return fmt.Errorf("failed to set block queue attribute %s to %s: %w", path, settings[name], err)
+		}
+		klog.V(4).Infof("Set block queue attribute %s of volume %s to %s", path, volumeID, settings[name])
+	}
+	return nil
+}
+
+// queueSettingsMap keeps, by volume ID, the block queue settings of staged
+// volumes, so that they are applied again after the volumes are expanded.
+type queueSettingsMap struct {
+	mux      sync.Mutex
+	settings map[string]map[string]string
+}
+
+func newQueueSettingsMap() *queueSettingsMap {
+	return &queueSettingsMap{settings: map[string]map[string]string{}}
+}
+
+func (m *queueSettingsMap) set(volumeID string, settings map[string]string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if len(settings) == 0 {
+		delete(m.settings, volumeID)
+		return
+	}
+	m.settings[volumeID] = settings
+}
+
+// get returns a copy of the block queue settings of volumeID.
+func (m *queueSettingsMap) get(volumeID string) map[string]string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if settings, ok := m.settings[volumeID]; ok {
+		return maps.Clone(settings)
+	}
+	return map[string]string{}
+}
+
+func (m *queueSettingsMap) delete(volumeID string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.settings, volumeID)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+func TestNodeQueueSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	volumeID := "projects/test-project/zones/test-zone/disks/test-disk"
+
+	// The /dev/disk/by-id path of the disk links to /dev/sdb.
+	devicePath := filepath.Join(tempDir, "by-id", "google-test-disk")
+	for _, dir := range []string{filepath.Join(tempDir, "dev"), filepath.Dir(devicePath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "dev", "sdb"), nil, 0600); err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(tempDir, "dev", "sdb"), devicePath); err != nil {
+		t.Fatalf("Failed to link device: %v", err)
+	}
+	queuePath := filepath.Join(tempDir, "sys", "block", "sdb", "queue")
+	if err := os.MkdirAll(queuePath, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", queuePath, err)
+	}
+	resetQueue := func() {
+		for _, name := range []string{"scheduler", "nr_requests"} {
+			if err := os.WriteFile(filepath.Join(queuePath, name), nil, 0644); err != nil {
+				t.Fatalf("Failed to reset %s: %v", name, err)
+			}
+		}
+	}
+	checkQueue := func(op string, want map[string]string) {
+		t.Helper()
+		for name, value := range want {
+			got, err := os.ReadFile(filepath.Join(queuePath, name))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", name, err)
+			}
+			if string(got) != value {
+				t.Errorf("%s set block queue attribute %s to %q, want %q", op, name, got, value)
+			}
+		}
+	}
+
+	deviceUtils := &devicePathsDeviceUtils{DeviceUtils: deviceutils.NewFakeDeviceUtils(false), devicePaths: []string{devicePath}}
+	ns := getCustomTestGCEDriver(t, mountmanager.NewFakeSafeMounter(), deviceUtils, metadataservice.NewFakeService(), &NodeServerArgs{SysfsPath: filepath.Join(tempDir, "sys")}).ns
+	blockCap := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Block{Block: &csi.VolumeCapability_BlockVolume{}},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+	}
+
+	resetQueue()
+	_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+		VolumeId:          volumeID,
+		StagingTargetPath: filepath.Join(tempDir, "staging"),
+		VolumeCapability:  blockCap,
+		VolumeContext:     map[string]string{common.ParameterKeyQueueSettings: "queue/scheduler=none,queue/nr_requests=256"},
+	})
+	if err != nil {
+		t.Fatalf("NodeStageVolume() failed: %v", err)
+	}
+	checkQueue("NodeStageVolume()", map[string]string{"scheduler": "none", "nr_requests": "256"})
+
+	// The settings are applied again once the volume is expanded.
+	resetQueue()
+	_, err = ns.NodeExpandVolume(context.Background(), &csi.NodeExpandVolumeRequest{
+		VolumeId:         volumeID,
+		VolumePath:       filepath.Join(tempDir, "staging"),
+		CapacityRange:    &csi.CapacityRange{RequiredBytes: common.GbToBytes(10)},
+		VolumeCapability: blockCap,
+	})
+	if err != nil {
+		t.Fatalf("NodeExpandVolume() failed: %v", err)
+	}
+	checkQueue("NodeExpandVolume()", map[string]string{"scheduler": "none", "nr_requests": "256"})
+
+	_, err = ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+		VolumeId:          volumeID,
+		StagingTargetPath: filepath.Join(tempDir, "staging"),
+		VolumeCapability: &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{MountFlags: []string{"queue/iosched=none"}},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("NodeStageVolume() with a block queue attribute not allowed got error code %v, want InvalidArgument: %v", got, err)
+	}
+}
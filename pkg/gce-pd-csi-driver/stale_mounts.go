@@ -0,0 +1,237 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+)
+
+const (
+	// volDataFileName is the file in which kubelet keeps the driver and the
+	// handle of the volume of a staging or publish directory, next to it.
+	volDataFileName = "vol_data.json"
+	// Names of the staging and publish directories of the volumes in the
+	// kubelet root directory.
+	stagingDirName = "globalmount"
+	publishDirName = "mount"
+)
+
+// volData is the content of a vol_data.json file written by kubelet.
+type volData struct {
+	DriverName   string `json:"driverName"`
+	VolumeHandle string `json:"volumeHandle"`
+}
+
+// volumeDir is a staging or publish directory of a volume of the driver.
+type volumeDir struct {
+	volumeID string
+	path     string
+}
+
+// RunStaleMountReconciler cleans up the staging and publish directories of the
+// volumes whose disk is no longer attached once, then every interval until ctx
+// is done if interval is positive.
+func (ns *GCENodeServer) RunStaleMountReconciler(ctx context.Context, interval time.Duration) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		if stale := ns.reconcileStaleMounts(ctx); len(stale) > 0 {
+			klog.Infof("Found %d stale staging or publish directories: %v", len(stale), stale)
+		}
+		if tick == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		}
+	}
+}
+
+// reconcileStaleMounts lazily unmounts the staging and publish directories of
+// the volumes of the driver in the kubelet root directory whose disk is not
+// attached anymore, e.g. after a node crash, and removes them once empty. Only
+// the stale directories are logged in dry-run mode. It returns the stale
+// directories.
+func (ns *GCENodeServer) reconcileStaleMounts(ctx context.Context) []string {
+	mountPoints, err := ns.Mounter.List()
+	if err != nil {
+		klog.Warningf("Failed to list mounts to find stale mounts: %v", err)
+		return nil
+	}
+	mounted := map[string]bool{}
+	for _, mp := range mountPoints {
+		mounted[mp.Path] = true
+	}
+
+	// Staging directories are in the plugin directory of the driver, e.g.
+	// plugins/kubernetes.io/csi/pd.csi.storage.gke.io/<hash>/globalmount.
+	var dirs []volumeDir
+	pluginDir := filepath.Join(ns.kubeletRootDir, "plugins", "kubernetes.io", "csi", ns.Driver.name)
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to list the staging directories in %s: %v", pluginDir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		volDir := filepath.Join(pluginDir, entry.Name())
+		if volumeID, ok := ns.readVolData(volDir); ok {
+			dirs = append(dirs, volumeDir{volumeID: volumeID, path: filepath.Join(volDir, stagingDirName)})
+		}
+	}
+
+	// Publish directories are mounted in the pod directories, e.g.
+	// pods/<uid>/volumes/kubernetes.io~csi/<pv>/mount.
+	podsDir := filepath.Join(ns.kubeletRootDir, "pods") + string(filepath.Separator)
+	for _, mp := range mountPoints {
+		if !strings.HasPrefix(mp.Path, podsDir) || filepath.Base(mp.Path) != publishDirName || filepath.Base(filepath.Dir(filepath.Dir(mp.Path))) != "kubernetes.io~csi" {
+			continue
+		}
+		if volumeID, ok := ns.readVolData(filepath.Dir(mp.Path)); ok {
+			dirs = append(dirs, volumeDir{volumeID: volumeID, path: mp.Path})
+		}
+	}
+
+	var stale []string
+	for _, dir := range dirs {
+		if ns.reconcileVolumeDir(ctx, dir, mounted[dir.path]) {
+			stale = append(stale, dir.path)
+		}
+	}
+	return stale
+}
+
+// readVolData returns the handle of the volume of the driver in the
+// vol_data.json file of dir, if any.
+func (ns *GCENodeServer) readVolData(dir string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, volDataFileName))
+	if err != nil {
+		klog.V(4).Infof("Failed to read the volume data of %s: %v", dir, err)
+		return "", false
+	}
+	var vd volData
+	if err := json.Unmarshal(data, &vd); err != nil {
+		klog.Warningf("Failed to parse the volume data of %s: %v", dir, err)
+		return "", false
+	}
+	if vd.DriverName != ns.Driver.name || vd.VolumeHandle == "" {
+		return "", false
+	}
+	return vd.VolumeHandle, true
+}
+
+// reconcileVolumeDir lazily unmounts the staging or publish directory dir if
+// the disk of its volume is not attached, and removes it if it is empty. It
+// returns true if dir is stale.
+func (ns *GCENodeServer) reconcileVolumeDir(ctx context.Context, dir volumeDir, isMounted bool) bool {
+	if !isMounted {
+		if _, err := os.Stat(dir.path); os.IsNotExist(err) {
+			return false
+		}
+	}
+	if !ns.isDiskDetached(dir) {
+		return false
+	}
+
+	// The volume lock keeps stale directories from being cleaned up while the
+	// volume is staged or published. The disk may have been attached and
+	// staged before the lock was acquired, so it is checked again.
+	if acquired := ns.volumeLocks.Acquire(ctx, dir.volumeID, "StaleMountReconcile"); !acquired {
+		klog.V(4).Infof("Skipping %s of volume %s, the volume has an operation in progress", dir.path, dir.volumeID)
+		return false
+	}
+	defer ns.volumeLocks.Release(dir.volumeID)
+	if !ns.isDiskDetached(dir) {
+		return false
+	}
+
+	if ns.staleMountDryRun {
+		klog.Infof("Dry run: %s of volume %s is stale, the disk is not attached (mounted: %v)", dir.path, dir.volumeID, isMounted)
+		return true
+	}
+	if isMounted {
+		klog.Infof("Lazily unmounting %s of volume %s, the disk is not attached", dir.path, dir.volumeID)
+		if err := lazyUnmount(dir.path, ns.Mounter); err != nil {
+			klog.Warningf("Failed to unmount stale mount %s of volume %s: %v", dir.path, dir.volumeID, err)
+			return true
+		}
+	}
+	// os.Remove leaves directories which are not empty.
+	if err := os.Remove(dir.path); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to remove stale directory %s of volume %s: %v", dir.path, dir.volumeID, err)
+	}
+	return true
+}
+
+// isDiskDetached returns true if the disk of the volume of dir is known not to
+// be attached. Errors are logged, and the disk is then assumed to be attached.
+func (ns *GCENodeServer) isDiskDetached(dir volumeDir) bool {
+	attached, err := ns.isDiskAttached(dir.volumeID)
+	if err != nil {
+		klog.V(4).Infof("Failed to check if the disk of volume %s of %s is attached: %v", dir.volumeID, dir.path, err)
+		return false
+	}
+	return !attached
+}
+
+// isDiskAttached returns true if one of the /dev/disk/by-id paths of the disk
+// of volumeID exists. Paths which cannot be checked are assumed to exist. If
+// none exists, the disk is looked up like when it is staged: udev may have
+// missed its links, so it is only detached if no device has its serial
+// number.
+func (ns *GCENodeServer) isDiskAttached(volumeID string) (bool, error) {
+	_, volumeKey, err := common.VolumeIDToKey(volumeID)
+	if err != nil {
+		return false, err
+	}
+	deviceName, err := common.GetDeviceName(volumeKey)
+	if err != nil {
+		return false, fmt.Errorf("error getting device name: %w", err)
+	}
+	devicePaths := ns.DeviceUtils.GetDiskByIdPaths(deviceName, "")
+	for _, path := range devicePaths {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			return true, nil
+		}
+	}
+	if _, err := ns.DeviceUtils.VerifyDevicePath(devicePaths, deviceName); err != nil {
+		if errors.Is(err, deviceutils.ErrDiskNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
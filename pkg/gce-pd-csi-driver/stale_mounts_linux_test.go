@@ -0,0 +1,140 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"k8s.io/mount-utils"
+	testingexec "k8s.io/utils/exec/testing"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+// byIdDeviceUtils has a /dev/disk/by-id path per disk under dir. The disks
+// in relinked are attached, but have no /dev/disk/by-id path until
+// VerifyDevicePath finds them by their serial number.
+type byIdDeviceUtils struct {
+	deviceutils.DeviceUtils
+	dir      string
+	relinked map[string]bool
+}
+
+func (d *byIdDeviceUtils) GetDiskByIdPaths(deviceName string, partition string) []string {
+	return []string{filepath.Join(d.dir, deviceName)}
+}
+
+func (d *byIdDeviceUtils) VerifyDevicePath(devicePaths []string, deviceName string) (string, error) {
+	for _, path := range devicePaths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	if d.relinked[deviceName] {
+		return filepath.Join(d.dir, deviceName), nil
+	}
+	return "", fmt.Errorf("disk %s: %w", deviceName, deviceutils.ErrDiskNotFound)
+}
+
+func TestReconcileStaleMounts(t *testing.T) {
+	kubeletDir := t.TempDir()
+	devDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(devDir, "attached"), nil, 0o644); err != nil {
+		t.Fatalf("Failed to create the attached device: %v", err)
+	}
+	pluginDir := filepath.Join(kubeletDir, "plugins/kubernetes.io/csi", driver)
+	makeVolumeDir := func(t *testing.T, dir, driverName, disk string) string {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		volData := `{"driverName":"` + driverName + `","volumeHandle":"projects/test001/zones/c1/disks/` + disk + `"}`
+		if err := os.WriteFile(filepath.Join(filepath.Dir(dir), volDataFileName), []byte(volData), 0o644); err != nil {
+			t.Fatalf("Failed to write the volume data of %s: %v", dir, err)
+		}
+		return dir
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		name := "cleanup"
+		if dryRun {
+			name = "dry run"
+		}
+		t.Run(name, func(t *testing.T) {
+			for _, dir := range []string{pluginDir, filepath.Join(kubeletDir, "pods")} {
+				if err := os.RemoveAll(dir); err != nil {
+					t.Fatalf("Failed to remove %s: %v", dir, err)
+				}
+			}
+			staleStaging := makeVolumeDir(t, filepath.Join(pluginDir, "0a1b", stagingDirName), driver, "detached")
+			emptyStaging := makeVolumeDir(t, filepath.Join(pluginDir, "2c3d", stagingDirName), driver, "detached2")
+			liveStaging := makeVolumeDir(t, filepath.Join(pluginDir, "4e5f", stagingDirName), driver, "attached")
+			relinkedStaging := makeVolumeDir(t, filepath.Join(pluginDir, "6a7b", stagingDirName), driver, "relinked")
+			stalePublish := makeVolumeDir(t, filepath.Join(kubeletDir, "pods/uid1/volumes/kubernetes.io~csi/pvc-1", publishDirName), driver, "detached")
+			livePublish := makeVolumeDir(t, filepath.Join(kubeletDir, "pods/uid1/volumes/kubernetes.io~csi/pvc-2", publishDirName), driver, "attached")
+			otherPublish := makeVolumeDir(t, filepath.Join(kubeletDir, "pods/uid1/volumes/kubernetes.io~csi/pvc-3", publishDirName), "other.csi.k8s.io", "detached")
+
+			var actionList []testingexec.FakeCommandAction
+			if !dryRun {
+				for _, path := range []string{staleStaging, stalePublish} {
+					actionList = append(actionList, makeFakeCmd(&testingexec.FakeCmd{
+						CombinedOutputScript: []testingexec.FakeAction{
+							func() ([]byte, []byte, error) { return nil, nil, nil },
+						},
+					}, "umount", "-l", path))
+				}
+			}
+			fakeExec := &testingexec.FakeExec{CommandScript: actionList, ExactOrder: true}
+			mounter := mountmanager.NewFakeSafeMounterWithCustomExec(fakeExec)
+			mounter.Interface.(*mount.FakeMounter).MountPoints = []mount.MountPoint{
+				{Device: "/dev/sdb", Path: staleStaging, Type: "ext4"},
+				{Device: "/dev/sdc", Path: liveStaging, Type: "ext4"},
+				{Device: "/dev/sde", Path: relinkedStaging, Type: "ext4"},
+				{Device: "/dev/sdb", Path: stalePublish, Type: "ext4"},
+				{Device: "/dev/sdc", Path: livePublish, Type: "ext4"},
+				{Device: "/dev/sdd", Path: otherPublish, Type: "ext4"},
+			}
+			args := &NodeServerArgs{KubeletRootDir: kubeletDir, StaleMountDryRun: dryRun}
+			deviceUtils := &byIdDeviceUtils{DeviceUtils: deviceutils.NewFakeDeviceUtils(false), dir: devDir, relinked: map[string]bool{"relinked": true}}
+			ns := getCustomTestGCEDriver(t, mounter, deviceUtils, metadataservice.NewFakeService(), args).ns
+
+			stale := ns.reconcileStaleMounts(context.Background())
+			if want := []string{staleStaging, emptyStaging, stalePublish}; !slices.Equal(stale, want) {
+				t.Errorf("reconcileStaleMounts() found stale directories %v, want %v", stale, want)
+			}
+			if fakeExec.CommandCalls != len(actionList) {
+				t.Errorf("reconcileStaleMounts() ran %d commands, want %d", fakeExec.CommandCalls, len(actionList))
+			}
+			for _, path := range []string{staleStaging, emptyStaging, stalePublish} {
+				if _, err := os.Stat(path); os.IsNotExist(err) != !dryRun {
+					t.Errorf("reconcileStaleMounts() left %s: %v, want removed %v", path, err, !dryRun)
+				}
+			}
+			for _, path := range []string{liveStaging, relinkedStaging, livePublish, otherPublish} {
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("reconcileStaleMounts() removed %s: %v", path, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
+)
+
+var (
+	// Fields required to tally the disks owned by a tenant.
+	tenantUsageFields = []googleapi.Field{
+		"items/labels",
+		"items/name",
+		"items/sizeGb",
+		"nextPageToken",
+	}
+)
+
+// TenantResolver resolves the tenant that the PVCs of a namespace belong to.
+type TenantResolver interface {
+	// GetTenantProjectNumber returns the tenant project number of namespace.
+	// The boolean is false if the namespace does not belong to a tenant.
+	GetTenantProjectNumber(ctx context.Context, namespace string) (string, bool, error)
+}
+
+// tenantLocks serializes the check of the limits of each tenant with the
+// start of the operation changing its usage, so that concurrent requests
+// cannot all fit in the capacity left. A lock is held from the tally of the
+// usage until GCE accepted the operation, not while the operation runs.
+//
+// The locks and reservations are held by this replica only: with several
+// active replicas, e.g. with Lease volume locks, requests served by different
+// replicas at the same time may still exceed the limits.
+type tenantLocks struct {
+	mux     sync.Mutex
+	tenants map[string]*tenantLock
+}
+
+type tenantLock struct {
+	// sem holds a token while the tenant is locked.
+	sem chan struct{}
+	// refs counts the holders and waiters of the lock and the reservations of
+	// the tenant. The tenant is removed from tenantLocks once it drops to 0.
+	refs int
+	// reservedGiB is the capacity of the resizes started but not completed,
+	// which the disks of the tenant do not report yet.
+	reservedGiB int64
+}
+
+// ref returns the lock of the tenant, referenced until unref is called.
+func (l *tenantLocks) ref(projectNumber string) *tenantLock {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.tenants == nil {
+		l.tenants = map[string]*tenantLock{}
+	}
+	tl, ok := l.tenants[projectNumber]
+	if !ok {
+		tl = &tenantLock{sem: make(chan struct{}, 1)}
+		l.tenants[projectNumber] = tl
+	}
+	tl.refs++
+	return tl
+}
+
+func (l *tenantLocks) unref(projectNumber string, tl *tenantLock) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	tl.refs--
+	if tl.refs == 0 {
+		delete(l.tenants, projectNumber)
+	}
+}
+
+// lock locks the tenant with the given project number, waiting at most until
+// ctx is done, and returns the function unlocking it. The function may be
+// called more than once.
+func (l *tenantLocks) lock(ctx context.Context, projectNumber string) (func(), error) {
+	tl := l.ref(projectNumber)
+	select {
+	case tl.sem <- struct{}{}:
+	case <-ctx.Done():
+		l.unref(projectNumber, tl)
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-tl.sem
+			l.unref(projectNumber, tl)
+		})
+	}, nil
+}
+
+// reserve adds gib to the capacity reserved by the tenant, and returns the
+// function releasing it.
+func (l *tenantLocks) reserve(projectNumber string, gib int64) func() {
+	tl := l.ref(projectNumber)
+	l.mux.Lock()
+	tl.reservedGiB += gib
+	l.mux.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mux.Lock()
+			tl.reservedGiB -= gib
+			l.mux.Unlock()
+			l.unref(projectNumber, tl)
+		})
+	}
+}
+
+// reservedGiB returns the capacity reserved by the tenant.
+func (l *tenantLocks) reservedGiB(projectNumber string) int64 {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if tl, ok := l.tenants[projectNumber]; ok {
+		return tl.reservedGiB
+	}
+	return 0
+}
+
+// attributeDiskToTenant labels the disk about to be created with the tenant
+// of the namespace of its PVC, replacing any label set by the StorageClass,
+// so that the usage of each tenant is tallied from all of its disks. It does
+// nothing if the driver does not run in multi-tenant mode.
+func (gceCS *GCEControllerServer) attributeDiskToTenant(ctx context.Context, parameters map[string]string, params *common.DiskParameters) error {
+	if gceCS.tenantResolver == nil {
+		return nil
+	}
+	delete(params.Labels, tenancy.TenantDiskLabelKey)
+	namespace := parameters[common.ParameterKeyPVCNamespace]
+	if namespace == "" {
+		return status.Errorf(codes.InvalidArgument, "CreateVolume parameter %s must be provided in multi-tenant mode, the external-provisioner must run with --extra-create-metadata", common.ParameterKeyPVCNamespace)
+	}
+	projectNumber, ok, err := gceCS.tenantResolver.GetTenantProjectNumber(ctx, namespace)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to resolve the tenant of namespace %s: %v", namespace, err.Error())
+	}
+	if ok {
+		if params.Labels == nil {
+			params.Labels = map[string]string{}
+		}
+		params.Labels[tenancy.TenantDiskLabelKey] = "t" + projectNumber
+	}
+	return nil
+}
+
+// tenantUsage is the tally of the disks attributed to a tenant.
+type tenantUsage struct {
+	disks    int64
+	totalGiB int64
+}
+
+// getTenantLimitsForLabels returns the tenant project number and limits for
+// a disk with the given labels. The boolean is false if the disk is not
+// attributed to a tenant, or the tenant has no limits configured.
+func (gceCS *GCEControllerServer) getTenantLimitsForLabels(labels map[string]string) (string, tenancy.Limits, bool) {
+	projectNumber, ok := tenancy.GetTenantProjectNumberFromDiskLabels(labels)
+	if !ok {
+		return "", tenancy.Limits{}, false
+	}
+	limits, ok := gceCS.CloudProvider.GetTenantLimits(projectNumber)
+	if !ok {
+		return "", tenancy.Limits{}, false
+	}
+	return projectNumber, limits, true
+}
+
+// getTenantUsage tallies the disks labeled as belonging to the given tenant.
+func (gceCS *GCEControllerServer) getTenantUsage(ctx context.Context, projectNumber string) (tenantUsage, error) {
+	labelValue := "t" + projectNumber
+	filter := fmt.Sprintf("labels.%s=%s", tenancy.TenantDiskLabelKey, labelValue)
+	disks, _, err := gceCS.CloudProvider.ListDisksWithFilter(ctx, tenantUsageFields, filter)
+	if err != nil {
+		return tenantUsage{}, err
+	}
+	usage := tenantUsage{}
+	for _, d := range disks {
+		if d.Labels[tenancy.TenantDiskLabelKey] != labelValue {
+			continue
+		}
+		usage.disks++
+		usage.totalGiB += d.SizeGb
+	}
+	return usage, nil
+}
+
+// validateTenantDiskType returns a PermissionDenied error if the disk type or
+// KMS key is not allowed by the tenant limits.
+func validateTenantDiskType(projectNumber string, limits tenancy.Limits, diskType, kmsKey string) error {
+	if len(limits.AllowedDiskTypes) > 0 && !slices.Contains(limits.AllowedDiskTypes, diskType) {
+		return status.Errorf(codes.PermissionDenied, "disk type %q is not in allowedDiskTypes %v of tenant project %s", diskType, limits.AllowedDiskTypes, projectNumber)
+	}
+	if len(limits.AllowedKMSKeys) > 0 {
+		// When a tenant restricts KMS keys every disk must be encrypted with one
+		// of them, Google-managed encryption is not allowed.
+		allowed := slices.ContainsFunc(limits.AllowedKMSKeys, func(k string) bool {
+			return kmsKey != "" && gce.KmsKeyEqual(kmsKey, k)
+		})
+		if !allowed {
+			return status.Errorf(codes.PermissionDenied, "disk encryption KMS key %q is not in allowedKmsKeys %v of tenant project %s", kmsKey, limits.AllowedKMSKeys, projectNumber)
+		}
+	}
+	return nil
+}
+
+// validateTenantPerformance returns a ResourceExhausted error if the IOPS or
+// throughput provisioned for a disk exceeds the tenant limits. Zero values
+// are not provisioned and not checked.
+func validateTenantPerformance(projectNumber string, limits tenancy.Limits, iops, throughput int64) error {
+	if limits.MaxDiskIOPS > 0 && iops > limits.MaxDiskIOPS {
+		return status.Errorf(codes.ResourceExhausted, "provisioned IOPS %d exceed maxDiskIops limit of %d of tenant project %s", iops, limits.MaxDiskIOPS, projectNumber)
+	}
+	if limits.MaxDiskThroughputMiBps > 0 && throughput > limits.MaxDiskThroughputMiBps {
+		return status.Errorf(codes.ResourceExhausted, "provisioned throughput %d MiB/s exceeds maxDiskThroughputMiBps limit of %d of tenant project %s", throughput, limits.MaxDiskThroughputMiBps, projectNumber)
+	}
+	return nil
+}
+
+// validateTenantCapacity returns a ResourceExhausted error if adding
+// newDisks disks and additionalGiB GiB to the tenant usage would exceed the
+// tenant limits. Otherwise it returns the function to call once GCE accepted
+// the operation changing the usage, which lets the next request of the tenant
+// be checked, and the function to call once the operation is done. If
+// reserve is set, additionalGiB is reserved while the operation
+// runs, for operations like resizes whose change of the usage only shows
+// once they are done.
+func (gceCS *GCEControllerServer) validateTenantCapacity(ctx context.Context, projectNumber string, limits tenancy.Limits, newDisks, additionalGiB int64, reserve bool) (func(), func(), error) {
+	if limits.MaxDisks == 0 && limits.MaxTotalGiB == 0 {
+		return func() {}, func() {}, nil
+	}
+	unlock, err := gceCS.tenantLocks.lock(ctx, projectNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	usage, err := gceCS.getTenantUsage(ctx, projectNumber)
+	if err != nil {
+		unlock()
+		return nil, nil, common.LoggedError(fmt.Sprintf("Failed to tally disk usage of tenant project %s: ", projectNumber), err)
+	}
+	usage.totalGiB += gceCS.tenantLocks.reservedGiB(projectNumber)
+	klog.V(4).Infof("Tenant project %s uses %d disks and %d GiB", projectNumber, usage.disks, usage.totalGiB)
+	if limits.MaxDisks > 0 && usage.disks+newDisks > limits.MaxDisks {
+		unlock()
+		return nil, nil, status.Errorf(codes.ResourceExhausted, "tenant project %s would exceed maxDisks limit of %d (in use: %d)", projectNumber, limits.MaxDisks, usage.disks)
+	}
+	if limits.MaxTotalGiB > 0 && usage.totalGiB+additionalGiB > limits.MaxTotalGiB {
+		unlock()
+		return nil, nil, status.Errorf(codes.ResourceExhausted, "tenant project %s would exceed maxTotalGiB limit of %d (in use: %d GiB, requested: %d GiB)", projectNumber, limits.MaxTotalGiB, usage.totalGiB, additionalGiB)
+	}
+
+	var (
+		mux       sync.Mutex
+		unreserve func()
+	)
+	started := func() {
+		mux.Lock()
+		defer mux.Unlock()
+		if reserve && unreserve == nil {
+			unreserve = gceCS.tenantLocks.reserve(projectNumber, additionalGiB)
+		}
+		unlock()
+	}
+	done := func() {
+		mux.Lock()
+		defer mux.Unlock()
+		unlock()
+		if unreserve != nil {
+			unreserve()
+		}
+	}
+	return started, done, nil
+}
+
+// validateTenantLimitsForCreate enforces the tenant limits on a disk about to
+// be created. The returned functions must be called once GCE accepted the
+// creation, see gce.WithOperationStarted, and once the disk is created. GCE lists the disks it is
+// creating, so their capacity is not reserved.
+func (gceCS *GCEControllerServer) validateTenantLimitsForCreate(ctx context.Context, params common.DiskParameters, capBytes int64) (func(), func(), error) {
+	projectNumber, limits, ok := gceCS.getTenantLimitsForLabels(params.Labels)
+	if !ok {
+		return func() {}, func() {}, nil
+	}
+	if err := validateTenantDiskType(projectNumber, limits, params.DiskType, params.DiskEncryptionKMSKey); err != nil {
+		return nil, nil, err
+	}
+	if err := validateTenantPerformance(projectNumber, limits, params.ProvisionedIOPSOnCreate, params.ProvisionedThroughputOnCreate); err != nil {
+		return nil, nil, err
+	}
+	return gceCS.validateTenantCapacity(ctx, projectNumber, limits, 1, common.BytesToGbRoundUp(capBytes), false)
+}
+
+// validateTenantLimitsForExpand enforces the tenant limits on an existing
+// disk about to be resized to requestBytes. The returned functions must be
+// called once GCE accepted the resize and once the disk is resized.
+func (gceCS *GCEControllerServer) validateTenantLimitsForExpand(ctx context.Context, disk *gce.CloudDisk, requestBytes int64) (func(), func(), error) {
+	projectNumber, limits, ok := gceCS.getTenantLimitsForLabels(disk.GetLabels())
+	if !ok {
+		return func() {}, func() {}, nil
+	}
+	additionalGiB := common.BytesToGbRoundUp(requestBytes) - disk.GetSizeGb()
+	if additionalGiB <= 0 {
+		return func() {}, func() {}, nil
+	}
+	return gceCS.validateTenantCapacity(ctx, projectNumber, limits, 0, additionalGiB, true)
+}
+
+// validateTenantLimitsForModify enforces the tenant limits on an existing
+// disk about to be modified: the disk type and KMS key of the disk must still
+// be allowed, and the IOPS and throughput it is modified to must fit in the
+// limits.
+func (gceCS *GCEControllerServer) validateTenantLimitsForModify(disk *gce.CloudDisk, params common.ModifyVolumeParameters) error {
+	projectNumber, limits, ok := gceCS.getTenantLimitsForLabels(disk.GetLabels())
+	if !ok {
+		return nil
+	}
+	if err := validateTenantDiskType(projectNumber, limits, disk.GetPDType(), disk.GetKMSKeyName()); err != nil {
+		return err
+	}
+	var iops, throughput int64
+	if params.IOPS != nil {
+		iops = *params.IOPS
+	}
+	if params.Throughput != nil {
+		throughput = *params.Throughput
+	}
+	return validateTenantPerformance(projectNumber, limits, iops, throughput)
+}
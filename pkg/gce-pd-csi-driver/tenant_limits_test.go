@@ -0,0 +1,428 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/k8s-cloud-provider/pkg/cloud/meta"
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+	gce "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/compute/tenancy"
+)
+
+const (
+	tenantProjectNumber = "123456"
+	tenantLabels        = tenancy.TenantDiskLabelKey + "=t" + tenantProjectNumber
+)
+
+// fakeTenantResolver resolves the tenants of namespaces from a map.
+type fakeTenantResolver map[string]string
+
+func (r fakeTenantResolver) GetTenantProjectNumber(_ context.Context, namespace string) (string, bool, error) {
+	if namespace == "unreachable" {
+		return "", false, errors.New("connection refused")
+	}
+	projectNumber, ok := r[namespace]
+	return projectNumber, ok, nil
+}
+
+// createTenantDisks inserts count disks of sizeGb attributed to the test tenant.
+func createTenantDisks(t *testing.T, fcp *gce.FakeCloudProvider, count int, sizeGb int64) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		params := common.DiskParameters{
+			DiskType: stdDiskType,
+			Labels:   map[string]string{tenancy.TenantDiskLabelKey: "t" + tenantProjectNumber},
+		}
+		volKey := meta.ZonalKey(fmt.Sprintf("tenant-disk-%d", i), zone)
+		if err := fcp.InsertDisk(context.Background(), project, volKey, params, common.GbToBytes(sizeGb), nil, nil, "", "", false, ""); err != nil {
+			t.Fatalf("Failed to insert disk: %v", err)
+		}
+	}
+}
+
+func TestCreateVolumeTenantLimits(t *testing.T) {
+	testCases := []struct {
+		name          string
+		limits        *tenancy.Limits
+		existingDisks int
+		multiTenant   bool
+		params        map[string]string
+		capacityGb    int64
+		expErrCode    codes.Code
+	}{
+		{
+			name:          "no limits configured",
+			existingDisks: 5,
+			params:        map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.OK,
+		},
+		{
+			name:          "disk not attributed to a tenant",
+			limits:        &tenancy.Limits{MaxDisks: 1},
+			existingDisks: 1,
+			params:        map[string]string{},
+			capacityGb:    20,
+			expErrCode:    codes.OK,
+		},
+		{
+			name:          "within limits",
+			limits:        &tenancy.Limits{MaxDisks: 2, MaxTotalGiB: 40, AllowedDiskTypes: []string{stdDiskType}},
+			existingDisks: 1,
+			params:        map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.OK,
+		},
+		{
+			name:          "max disks exceeded",
+			limits:        &tenancy.Limits{MaxDisks: 2},
+			existingDisks: 2,
+			params:        map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.ResourceExhausted,
+		},
+		{
+			name:          "max total GiB exceeded",
+			limits:        &tenancy.Limits{MaxTotalGiB: 50},
+			existingDisks: 2,
+			params:        map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.ResourceExhausted,
+		},
+		{
+			name:       "disk type not allowed",
+			limits:     &tenancy.Limits{AllowedDiskTypes: []string{"pd-ssd"}},
+			params:     map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: "pd-balanced"},
+			capacityGb: 20,
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "KMS key required",
+			limits:     &tenancy.Limits{AllowedKMSKeys: []string{testDiskEncryptionKmsKey}},
+			params:     map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType},
+			capacityGb: 20,
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:          "tenant of the namespace without StorageClass labels",
+			limits:        &tenancy.Limits{MaxDisks: 2},
+			existingDisks: 2,
+			multiTenant:   true,
+			params:        map[string]string{common.ParameterKeyPVCNamespace: "tenant-ns", common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.ResourceExhausted,
+		},
+		{
+			name:          "StorageClass labels do not change the tenant",
+			limits:        &tenancy.Limits{MaxDisks: 2},
+			existingDisks: 2,
+			multiTenant:   true,
+			params:        map[string]string{common.ParameterKeyPVCNamespace: "tenant-ns", common.ParameterKeyLabels: tenancy.TenantDiskLabelKey + "=t999", common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.ResourceExhausted,
+		},
+		{
+			name:          "StorageClass labels do not attribute disks of other namespaces",
+			limits:        &tenancy.Limits{MaxDisks: 2},
+			existingDisks: 2,
+			multiTenant:   true,
+			params:        map[string]string{common.ParameterKeyPVCNamespace: "default", common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType},
+			capacityGb:    20,
+			expErrCode:    codes.OK,
+		},
+		{
+			name:        "PVC namespace missing",
+			limits:      &tenancy.Limits{MaxDisks: 2},
+			multiTenant: true,
+			params:      map[string]string{common.ParameterKeyType: stdDiskType},
+			capacityGb:  20,
+			expErrCode:  codes.InvalidArgument,
+		},
+		{
+			name:        "tenant of the namespace unavailable",
+			limits:      &tenancy.Limits{MaxDisks: 2},
+			multiTenant: true,
+			params:      map[string]string{common.ParameterKeyPVCNamespace: "unreachable", common.ParameterKeyType: stdDiskType},
+			capacityGb:  20,
+			expErrCode:  codes.Unavailable,
+		},
+		{
+			name:       "provisioned IOPS over the limit",
+			limits:     &tenancy.Limits{MaxDiskIOPS: 10000},
+			params:     map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: "hyperdisk-balanced", common.ParameterKeyProvisionedIOPSOnCreate: "20000"},
+			capacityGb: 20,
+			expErrCode: codes.ResourceExhausted,
+		},
+		{
+			name:       "KMS key allowed",
+			limits:     &tenancy.Limits{AllowedKMSKeys: []string{testDiskEncryptionKmsKey}},
+			params:     map[string]string{common.ParameterKeyLabels: tenantLabels, common.ParameterKeyType: stdDiskType, common.ParameterKeyDiskEncryptionKmsKey: testDiskEncryptionKmsKey},
+			capacityGb: 20,
+			expErrCode: codes.OK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fcp, err := gce.CreateFakeCloudProvider(project, zone, nil)
+			if err != nil {
+				t.Fatalf("Failed to create fake cloud provider: %v", err)
+			}
+			if tc.limits != nil {
+				fcp.SetTenantLimits(tenantProjectNumber, *tc.limits)
+			}
+			createTenantDisks(t, fcp, tc.existingDisks, 20)
+			args := &GCEControllerServerArgs{}
+			if tc.multiTenant {
+				args.TenantResolver = fakeTenantResolver{"tenant-ns": tenantProjectNumber}
+			}
+			gceDriver := initGCEDriverWithCloudProvider(t, fcp, args)
+
+			req := &csi.CreateVolumeRequest{
+				Name:               name,
+				CapacityRange:      &csi.CapacityRange{RequiredBytes: common.GbToBytes(tc.capacityGb)},
+				VolumeCapabilities: stdVolCaps,
+				Parameters:         tc.params,
+			}
+			_, err = gceDriver.cs.CreateVolume(context.Background(), req)
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Errorf("CreateVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+		})
+	}
+}
+
+func TestControllerExpandVolumeTenantLimits(t *testing.T) {
+	testCases := []struct {
+		name       string
+		limits     tenancy.Limits
+		requestGb  int64
+		expErrCode codes.Code
+	}{
+		{
+			name:       "within limits",
+			limits:     tenancy.Limits{MaxTotalGiB: 100},
+			requestGb:  80,
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "max total GiB exceeded",
+			limits:     tenancy.Limits{MaxTotalGiB: 100},
+			requestGb:  81,
+			expErrCode: codes.ResourceExhausted,
+		},
+		{
+			name:       "max disks does not apply to resizes",
+			limits:     tenancy.Limits{MaxDisks: 2},
+			requestGb:  100,
+			expErrCode: codes.OK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fcp, err := gce.CreateFakeCloudProvider(project, zone, nil)
+			if err != nil {
+				t.Fatalf("Failed to create fake cloud provider: %v", err)
+			}
+			fcp.SetTenantLimits(tenantProjectNumber, tc.limits)
+			createTenantDisks(t, fcp, 2, 20)
+			gceDriver := initGCEDriverWithCloudProvider(t, fcp, &GCEControllerServerArgs{})
+
+			req := &csi.ControllerExpandVolumeRequest{
+				VolumeId:      fmt.Sprintf("projects/%s/zones/%s/disks/tenant-disk-0", project, zone),
+				CapacityRange: &csi.CapacityRange{RequiredBytes: common.GbToBytes(tc.requestGb)},
+			}
+			_, err = gceDriver.cs.ControllerExpandVolume(context.Background(), req)
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Errorf("ControllerExpandVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+		})
+	}
+}
+
+func TestControllerModifyVolumeTenantLimits(t *testing.T) {
+	testCases := []struct {
+		name       string
+		limits     tenancy.Limits
+		params     map[string]string
+		expErrCode codes.Code
+	}{
+		{
+			name:       "disk type allowed",
+			limits:     tenancy.Limits{AllowedDiskTypes: []string{"hyperdisk-balanced"}},
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "disk type no longer allowed",
+			limits:     tenancy.Limits{AllowedDiskTypes: []string{"pd-ssd"}},
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "KMS key no longer allowed",
+			limits:     tenancy.Limits{AllowedKMSKeys: []string{testDiskEncryptionKmsKey}},
+			expErrCode: codes.PermissionDenied,
+		},
+		{
+			name:       "IOPS within the limit",
+			limits:     tenancy.Limits{MaxDiskIOPS: 20000},
+			expErrCode: codes.OK,
+		},
+		{
+			name:       "IOPS over the limit",
+			limits:     tenancy.Limits{MaxDiskIOPS: 15000},
+			expErrCode: codes.ResourceExhausted,
+		},
+		{
+			name:       "throughput over the limit",
+			limits:     tenancy.Limits{MaxDiskThroughputMiBps: 500},
+			params:     map[string]string{"throughput": "600Mi"},
+			expErrCode: codes.ResourceExhausted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fcp, err := gce.CreateFakeCloudProvider(project, zone, nil)
+			if err != nil {
+				t.Fatalf("Failed to create fake cloud provider: %v", err)
+			}
+			fcp.SetTenantLimits(tenantProjectNumber, tc.limits)
+			gceDriver := initGCEDriverWithCloudProvider(t, fcp, &GCEControllerServerArgs{})
+			params := common.DiskParameters{
+				DiskType:                      "hyperdisk-balanced",
+				ProvisionedIOPSOnCreate:       10000,
+				ProvisionedThroughputOnCreate: 500,
+				Labels:                        map[string]string{tenancy.TenantDiskLabelKey: "t" + tenantProjectNumber},
+			}
+			if err := fcp.InsertDisk(context.Background(), project, meta.ZonalKey(name, zone), params, common.GbToBytes(20), nil, nil, "", "", false, ""); err != nil {
+				t.Fatalf("Failed to insert disk: %v", err)
+			}
+
+			req := &csi.ControllerModifyVolumeRequest{
+				VolumeId:          testVolumeID,
+				MutableParameters: map[string]string{"iops": "20000"},
+			}
+			if tc.params != nil {
+				req.MutableParameters = tc.params
+			}
+			_, err = gceDriver.cs.ControllerModifyVolume(context.Background(), req)
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Errorf("ControllerModifyVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+		})
+	}
+}
+
+func TestTenantLocks(t *testing.T) {
+	ctx := context.Background()
+	var locks tenantLocks
+	unlock, err := locks.lock(ctx, tenantProjectNumber)
+	if err != nil {
+		t.Fatalf("lock() failed: %v", err)
+	}
+
+	// Other tenants are not blocked.
+	unlockOther, err := locks.lock(ctx, "789")
+	if err != nil {
+		t.Fatalf("lock() of another tenant failed: %v", err)
+	}
+	unlockOther()
+
+	// Waiting for a locked tenant ends with the context.
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := locks.lock(shortCtx, tenantProjectNumber); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("lock() of a locked tenant got error %v, want DeadlineExceeded", err)
+	}
+
+	locked := make(chan struct{})
+	go func() {
+		if unlock, err := locks.lock(ctx, tenantProjectNumber); err == nil {
+			unlock()
+		}
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatalf("lock() of a locked tenant did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lock() of an unlocked tenant blocked")
+	}
+
+	// Reservations are counted until released.
+	release := locks.reserve(tenantProjectNumber, 10)
+	locks.reserve(tenantProjectNumber, 5)()
+	if got := locks.reservedGiB(tenantProjectNumber); got != 10 {
+		t.Errorf("reservedGiB() = %d, want 10", got)
+	}
+	release()
+	release()
+	if got := locks.reservedGiB(tenantProjectNumber); got != 0 {
+		t.Errorf("reservedGiB() after release = %d, want 0", got)
+	}
+
+	// Tenants no longer locked or reserved are forgotten.
+	if len(locks.tenants) != 0 {
+		t.Errorf("tenantLocks kept %d unused tenants", len(locks.tenants))
+	}
+}
+
+func TestTenantLockReleasedOnceOperationStarted(t *testing.T) {
+	fcp, err := gce.CreateFakeCloudProvider(project, zone, nil)
+	if err != nil {
+		t.Fatalf("Failed to create fake cloud provider: %v", err)
+	}
+	fcp.SetTenantLimits(tenantProjectNumber, tenancy.Limits{MaxTotalGiB: 90})
+	createTenantDisks(t, fcp, 2, 20)
+	gceDriver := initGCEDriverWithCloudProvider(t, fcp, &GCEControllerServerArgs{})
+	cs := gceDriver.cs
+	disk, err := fcp.GetDisk(context.Background(), project, meta.ZonalKey("tenant-disk-0", zone))
+	if err != nil {
+		t.Fatalf("GetDisk() failed: %v", err)
+	}
+
+	started, done, err := cs.validateTenantLimitsForExpand(context.Background(), disk, common.GbToBytes(50))
+	if err != nil {
+		t.Fatalf("validateTenantLimitsForExpand() failed: %v", err)
+	}
+	// Once the resize started, the tenant is unlocked but the capacity of the
+	// resize stays reserved until it is done.
+	started()
+	lockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _, err = cs.validateTenantLimitsForExpand(lockCtx, disk, common.GbToBytes(50))
+	if got := status.Code(err); got != codes.ResourceExhausted {
+		t.Errorf("validateTenantLimitsForExpand() during a resize got error code %v, want ResourceExhausted: %v", got, err)
+	}
+	done()
+	if len(cs.tenantLocks.tenants) != 0 {
+		t.Errorf("tenantLocks kept %d tenants once the resize is done", len(cs.tenantLocks.tenants))
+	}
+}
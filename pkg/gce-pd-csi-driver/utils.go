@@ -302,7 +302,7 @@ func getHyperdiskAccessModeFromCapabilities(vcs []*csi.VolumeCapability) (string
 	return "", errors.New("volume capabilities is nil")
 }
 
-func collectMountOptions(fsType string, mntFlags []string) []string {
+func collectMountOptions(mntFlags []string) []string {
 	var options []string
 
 	for _, opt := range mntFlags {
@@ -318,15 +318,19 @@ func collectMountOptions(fsType string, mntFlags []string) []string {
 		if btrfsReclaimMetadataRegex.FindString(opt) != "" {
 			continue
 		}
+		if fsckPolicyMountFlagRegex.FindString(opt) != "" {
+			continue
+		}
+		if fstrimIntervalMountFlagRegex.FindString(opt) != "" {
+			continue
+		}
+		if common.IsQueueSetting(opt) {
+			continue
+		}
 
 		options = append(options, opt)
 	}
 
-	// By default, xfs does not allow mounting of two volumes with the same filesystem uuid.
-	// Force ignore this uuid to be able to mount volume + its clone / restored snapshot on the same node.
-	if fsType == fsTypeXFS {
-		options = append(options, "nouuid")
-	}
 	return options
 }
 
@@ -18,8 +18,11 @@ package gceGCEDriver
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -28,6 +31,10 @@ import (
 	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
 )
 
+// procMountInfoPath is the mountinfo of the driver, which shares the mounts
+// of kubelet.
+var procMountInfoPath = "/proc/self/mountinfo"
+
 func getDevicePath(ns *GCENodeServer, volumeID, partition string) (string, error) {
 	_, volumeKey, err := common.VolumeIDToKey(volumeID)
 	if err != nil {
@@ -48,41 +55,47 @@ func getDevicePath(ns *GCENodeServer, volumeID, partition string) (string, error
 	return devicePath, nil
 }
 
-func (ns *GCENodeServer) formatAndMount(source, target, fstype string, options []string, m *mount.SafeFormatAndMount) error {
+// formatAndMount formats source with formatOptions if it is not formatted yet,
+// or checks its filesystem according to fsckPolicy if it is, and mounts it at
+// target.
+func (ns *GCENodeServer) formatAndMount(volumeID, source, target, fstype string, options, formatOptions []string, fsckPolicy string, m *mount.SafeFormatAndMount) error {
 	if ns.formatAndMountSemaphore != nil {
-		done := make(chan any)
-		defer close(done)
-
-		// Aquire the semaphore. This will block if another formatAndMount has put an item
-		// into the semaphore channel.
-		ns.formatAndMountSemaphore <- struct{}{}
-
-		go func() {
-			defer func() { <-ns.formatAndMountSemaphore }()
-
-			// Add a timeout where so the semaphore will be released even if
-			// formatAndMount is still working. This allows the node to make progress on
-			// volumes if some error causes one formatAndMount to get stuck. The
-			// motivation for this serialization is to reduce memory usage; if stuck
-			// processes cause OOMs then the containers will be killed and restarted,
-			// including the stuck threads and with any luck making progress.
-			timeout := time.NewTimer(ns.formatAndMountTimeout)
-			defer timeout.Stop()
-
-			select {
-			case <-done:
-			case <-timeout.C:
-			}
-		}()
+		defer holdSemaphore(ns.formatAndMountSemaphore, ns.formatAndMountTimeout)()
 	}
 
-	err := m.FormatAndMount(source, target, fstype, options)
+	err := ns.checkFilesystemAndMount(volumeID, source, target, fstype, options, formatOptions, fsckPolicy, m)
 	if ns.metricsManager != nil {
 		ns.metricsManager.RecordMountErrorMetric(fstype, err)
 	}
 	return err
 }
 
+// holdSemaphore acquires a token of semaphore, blocking while all of them are
+// held, and returns the function to call once the operation is done. The
+// token is released when that function is called, or when timeout has
+// expired. This allows the node to make progress on volumes if some error
+// causes one operation to get stuck. The motivation for this serialization is
+// to reduce memory usage; if stuck processes cause OOMs then the containers
+// will be killed and restarted, including the stuck threads and with any luck
+// making progress.
+func holdSemaphore(semaphore chan any, timeout time.Duration) func() {
+	done := make(chan any)
+	semaphore <- struct{}{}
+
+	go func() {
+		defer func() { <-semaphore }()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+		}
+	}()
+	return func() { close(done) }
+}
+
 func preparePublishPath(path string, m *mount.SafeFormatAndMount) error {
 	return os.MkdirAll(path, 0750)
 }
@@ -132,3 +145,81 @@ func setReadAheadKB(devicePath string, readAheadKB int64, m *mount.SafeFormatAnd
 	}
 	return nil
 }
+
+// filesystemRemountedReadOnly returns true if the filesystem mounted at path
+// is read-only while the mount itself is read-write, which is the case when
+// the filesystem was remounted read-only after errors. Volumes mounted
+// read-only on purpose have read-only mounts.
+func filesystemRemountedReadOnly(path string) (bool, error) {
+	mountInfos, err := mount.ParseMountInfo(procMountInfoPath)
+	if err != nil {
+		return false, err
+	}
+	for _, mi := range mountInfos {
+		if mi.MountPoint != path {
+			continue
+		}
+		return slices.Contains(mi.SuperOptions, "ro") && !slices.Contains(mi.MountOptions, "ro"), nil
+	}
+	return false, fmt.Errorf("mount point %s not found in %s", path, procMountInfoPath)
+}
+
+// fstrimTrimmedRegex matches the number of bytes trimmed in the output of
+// fstrim -v, like "/mnt: 1 GiB (1073741824 bytes) trimmed", or
+// "/mnt: 1073741824 bytes were trimmed" for older versions.
+var fstrimTrimmedRegex = regexp.MustCompile(`(\d+) bytes\)? (?:were )?trimmed`)
+
+// trimFilesystem discards the unused blocks of the filesystem mounted at path,
+// and returns the number of bytes trimmed.
+func trimFilesystem(path string, m *mount.SafeFormatAndMount) (int64, error) {
+	output, err := m.Exec.Command("fstrim", "-v", path).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("fstrim failed: output: %s, err: %w", string(output), err)
+	}
+	match := fstrimTrimmedRegex.FindStringSubmatch(string(output))
+	if match == nil {
+		return 0, nil
+	}
+	trimmedBytes, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q into an int size", match[1])
+	}
+	return trimmedBytes, nil
+}
+
+// setVolumeMountGroup gives the group gid ownership of the root directory of
+// the filesystem mounted at path, along with read, write and execute
+// permissions and the setgid bit, so that the files created in it belong to
+// the group. Existing files are left as is, and nothing is changed if the root
+// directory is already set up.
+func setVolumeMountGroup(path string, gid int) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("failed to get the owner of %s", path)
+	}
+	mode := info.Mode()
+	wantMode := mode | os.ModeSetgid | 0o070
+	if int(stat.Gid) == gid && mode == wantMode {
+		return nil
+	}
+	if int(stat.Gid) != gid {
+		if err := os.Lchown(path, -1, gid); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(path, wantMode)
+}
+
+// lazyUnmount detaches the mount at path right away, and lets the kernel
+// clean it up once it is no longer busy, without accessing the device.
+func lazyUnmount(path string, m *mount.SafeFormatAndMount) error {
+	output, err := m.Exec.Command("umount", "-l", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("umount -l failed: output: %s, err: %w", string(output), err)
+	}
+	return nil
+}
@@ -24,7 +24,7 @@ import (
 	mounter "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
 )
 
-func (ns *GCENodeServer) formatAndMount(source, target, fstype string, options []string, m *mount.SafeFormatAndMount) error {
+func (ns *GCENodeServer) formatAndMount(_, source, target, fstype string, options, _ []string, _ string, m *mount.SafeFormatAndMount) error {
 	if !strings.EqualFold(fstype, defaultWindowsFsType) {
 		return fmt.Errorf("GCE PD CSI driver can only supports %s file system, it does not support %s", defaultWindowsFsType, fstype)
 	}
@@ -110,3 +110,27 @@ func setReadAheadKB(devicePath string, readAheadKB int64, m *mount.SafeFormatAnd
 	// This is a no-op on windows.
 	return nil
 }
+
+// filesystemRemountedReadOnly returns false, NTFS volumes are not remounted
+// read-only after errors.
+func filesystemRemountedReadOnly(path string) (bool, error) {
+	return false, nil
+}
+
+func trimFilesystem(path string, m *mount.SafeFormatAndMount) (int64, error) {
+	return 0, fmt.Errorf("trimming filesystems is not supported on windows")
+}
+
+func setVolumeMountGroup(path string, gid int) error {
+	// This is a no-op on windows.
+	return nil
+}
+
+func (ns *GCENodeServer) resolveDuplicateFilesystemUUID(_, _, _ string, _ bool) ([]string, error) {
+	// NTFS volumes with the same serial number may be mounted together.
+	return nil, nil
+}
+
+func lazyUnmount(path string, m *mount.SafeFormatAndMount) error {
+	return fmt.Errorf("lazy unmounts are not supported on windows")
+}
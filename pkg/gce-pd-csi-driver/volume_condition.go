@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"fmt"
+	"os"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/common"
+)
+
+const volumeConditionHealthyMessage = "volume is healthy"
+
+// getVolumeCondition returns the condition of the volume published at
+// volumePath. Kubelet reports the message of an abnormal condition as an
+// event of the PVC. Checks that fail to run are logged and skipped, so that a
+// volume is only reported abnormal when it is known to be.
+func (ns *GCENodeServer) getVolumeCondition(volumeID, volumePath string) *csi.VolumeCondition {
+	for _, check := range []func(volumeID, volumePath string) (string, error){
+		ns.checkVolumeMounted,
+		ns.checkVolumeDevice,
+		ns.checkVolumeFilesystemWritable,
+		ns.checkVolumeFilesystemErrors,
+		ns.checkVolumeDataCache,
+	} {
+		message, err := check(volumeID, volumePath)
+		if err != nil {
+			klog.V(4).Infof("Failed to check the condition of volume %s at %s: %v", volumeID, volumePath, err)
+			continue
+		}
+		if message != "" {
+			return &csi.VolumeCondition{Abnormal: true, Message: message}
+		}
+	}
+	return &csi.VolumeCondition{Abnormal: false, Message: volumeConditionHealthyMessage}
+}
+
+// checkVolumeMounted returns a message if volumePath is not mounted.
+func (ns *GCENodeServer) checkVolumeMounted(_, volumePath string) (string, error) {
+	notMnt, err := ns.Mounter.IsLikelyNotMountPoint(volumePath)
+	if err != nil {
+		return "", err
+	}
+	if notMnt {
+		return fmt.Sprintf("volume path %s is not mounted", volumePath), nil
+	}
+	return "", nil
+}
+
+// checkVolumeDevice returns a message if the device of the disk of volumeID
+// disappeared, e.g. because the disk was detached out of band.
+func (ns *GCENodeServer) checkVolumeDevice(volumeID, _ string) (string, error) {
+	_, volumeKey, err := common.VolumeIDToKey(volumeID)
+	if err != nil {
+		return "", err
+	}
+	deviceName, err := common.GetDeviceName(volumeKey)
+	if err != nil {
+		return "", err
+	}
+	devicePaths := ns.DeviceUtils.GetDiskByIdPaths(deviceName, "")
+	for _, devicePath := range devicePaths {
+		if _, err := os.Stat(devicePath); err == nil {
+			return "", nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("device of disk %s is missing, none of %v exist", deviceName, devicePaths), nil
+}
+
+// checkVolumeFilesystemWritable returns a message if the filesystem of
+// volumePath was remounted read-only, e.g. by ext4 after errors, while the
+// volume was mounted read-write.
+func (ns *GCENodeServer) checkVolumeFilesystemWritable(_, volumePath string) (string, error) {
+	remounted, err := filesystemRemountedReadOnly(volumePath)
+	if err != nil {
+		return "", err
+	}
+	if remounted {
+		return fmt.Sprintf("filesystem of volume path %s was remounted read-only, likely after errors", volumePath), nil
+	}
+	return "", nil
+}
+
+// checkVolumeFilesystemErrors returns a message if the filesystem check run
+// when volumeID was staged found errors it did not correct.
+func (ns *GCENodeServer) checkVolumeFilesystemErrors(volumeID, _ string) (string, error) {
+	return ns.fsckErrors.get(volumeID), nil
+}
+
+// checkVolumeDataCache returns a message if the data cache logical volume of
+// volumeID is degraded.
+func (ns *GCENodeServer) checkVolumeDataCache(volumeID, _ string) (string, error) {
+	if !ns.EnableDataCache || !ns.DataCacheEnabledNodePool {
+		return "", nil
+	}
+	health, err := dataCacheHealthStatus(volumeID)
+	if err != nil {
+		return "", err
+	}
+	if health != "" {
+		return fmt.Sprintf("data cache of the volume is degraded: %s", health), nil
+	}
+	return "", nil
+}
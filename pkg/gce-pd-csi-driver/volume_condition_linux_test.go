@@ -0,0 +1,138 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/deviceutils"
+	metadataservice "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/gce-cloud-provider/metadata"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+// devicePathsDeviceUtils returns fixed /dev/disk/by-id paths, and verifies
+// the first one.
+type devicePathsDeviceUtils struct {
+	deviceutils.DeviceUtils
+	devicePaths []string
+}
+
+func (du *devicePathsDeviceUtils) GetDiskByIdPaths(deviceName, partition string) []string {
+	return du.devicePaths
+}
+
+func (du *devicePathsDeviceUtils) VerifyDevicePath(devicePaths []string, deviceName string) (string, error) {
+	return devicePaths[0], nil
+}
+
+func TestNodeGetVolumeStatsVolumeCondition(t *testing.T) {
+	tempDir := t.TempDir()
+	targetPath := filepath.Join(tempDir, "target")
+	devicePath := filepath.Join(tempDir, "device")
+	for _, path := range []string{targetPath, devicePath} {
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+	volumeID := "projects/test-project/zones/test-zone/disks/test-disk"
+
+	testCases := []struct {
+		name        string
+		mounted     bool
+		devicePath  string
+		mountInfo   string
+		expAbnormal bool
+		expMessage  string
+	}{
+		{
+			name:       "healthy",
+			mounted:    true,
+			devicePath: devicePath,
+			mountInfo:  "rw,relatime shared:1 - ext4 /dev/sdb rw",
+			expMessage: volumeConditionHealthyMessage,
+		},
+		{
+			name:       "mounted read-only on purpose",
+			mounted:    true,
+			devicePath: devicePath,
+			mountInfo:  "ro,relatime shared:1 - ext4 /dev/sdb ro",
+			expMessage: volumeConditionHealthyMessage,
+		},
+		{
+			name:        "not mounted",
+			devicePath:  devicePath,
+			mountInfo:   "rw,relatime shared:1 - ext4 /dev/sdb rw",
+			expAbnormal: true,
+			expMessage:  "is not mounted",
+		},
+		{
+			name:        "device missing",
+			mounted:     true,
+			devicePath:  filepath.Join(tempDir, "missing"),
+			mountInfo:   "rw,relatime shared:1 - ext4 /dev/sdb rw",
+			expAbnormal: true,
+			expMessage:  "device of disk test-disk is missing",
+		},
+		{
+			name:        "remounted read-only after errors",
+			mounted:     true,
+			devicePath:  devicePath,
+			mountInfo:   "rw,relatime shared:1 - ext4 /dev/sdb ro,errors=remount-ro",
+			expAbnormal: true,
+			expMessage:  "remounted read-only",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mountInfoPath := filepath.Join(t.TempDir(), "mountinfo")
+			mountInfo := fmt.Sprintf("30 20 8:16 / %s %s\n", targetPath, tc.mountInfo)
+			if err := os.WriteFile(mountInfoPath, []byte(mountInfo), 0600); err != nil {
+				t.Fatalf("Failed to write mountinfo: %v", err)
+			}
+			defer func(path string) { procMountInfoPath = path }(procMountInfoPath)
+			procMountInfoPath = mountInfoPath
+
+			fakeMounter := &mount.FakeMounter{MountPoints: []mount.MountPoint{}}
+			if tc.mounted {
+				fakeMounter.MountPoints = append(fakeMounter.MountPoints, mount.MountPoint{Device: "/dev/sdb", Path: targetPath, Type: "ext4"})
+			}
+			mounter := mountmanager.NewCustomFakeSafeMounter(fakeMounter, nil)
+			gceDriver := GetGCEDriver()
+			deviceUtils := &devicePathsDeviceUtils{DeviceUtils: deviceutils.NewFakeDeviceUtils(false), devicePaths: []string{tc.devicePath}}
+			ns := NewNodeServer(gceDriver, mounter, deviceUtils, metadataservice.NewFakeService(), mountmanager.NewFakeStatterWithOptions(mounter, mountmanager.FakeStatterOptions{}), &NodeServerArgs{})
+
+			resp, err := ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+				VolumeId:   volumeID,
+				VolumePath: targetPath,
+			})
+			if err != nil {
+				t.Fatalf("NodeGetVolumeStats() failed: %v", err)
+			}
+			condition := resp.GetVolumeCondition()
+			if condition.GetAbnormal() != tc.expAbnormal || !strings.Contains(condition.GetMessage(), tc.expMessage) {
+				t.Errorf("NodeGetVolumeStats() got condition %+v, want abnormal %v with message %q", condition, tc.expAbnormal, tc.expMessage)
+			}
+		})
+	}
+}
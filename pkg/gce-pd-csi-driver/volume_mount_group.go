@@ -0,0 +1,66 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// fsTypesWithGidMountOption are the filesystem types without owners on disk,
+// whose files are all owned by the group of the gid mount option.
+var fsTypesWithGidMountOption = []string{"vfat", "exfat", "ntfs3"}
+
+// parseVolumeMountGroup returns the group ID of the volume mount group of a
+// volume capability, or -1 if there is none.
+func parseVolumeMountGroup(group string) (int, error) {
+	if group == "" {
+		return -1, nil
+	}
+	gid, err := strconv.Atoi(group)
+	if err != nil || gid < 0 {
+		return -1, fmt.Errorf("volume mount group %q must be a numeric group ID", group)
+	}
+	return gid, nil
+}
+
+// supportsGidMountOption returns true if the files of fstype filesystems may
+// be given to a group with the gid mount option.
+func supportsGidMountOption(fstype string) bool {
+	return slices.Contains(fsTypesWithGidMountOption, fstype)
+}
+
+// hasGidMountOption returns true if the files of the fstype filesystem mounted
+// with options are all owned by the group of the gid mount option.
+func hasGidMountOption(fstype string, options []string) bool {
+	return supportsGidMountOption(fstype) && slices.ContainsFunc(options, func(option string) bool {
+		return strings.HasPrefix(option, "gid=")
+	})
+}
+
+// volumeMountGroupOptions returns the mount options giving the group gid
+// ownership of the files of the fstype filesystem mounted with options, for
+// filesystem types which have a gid mount option. Options setting the group
+// explicitly take precedence.
+func volumeMountGroupOptions(fstype string, gid int, options []string) []string {
+	if gid < 0 || !supportsGidMountOption(fstype) || hasGidMountOption(fstype, options) {
+		return nil
+	}
+	return []string{fmt.Sprintf("gid=%d", gid)}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gceGCEDriver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/mount-utils"
+	testingexec "k8s.io/utils/exec/testing"
+	mountmanager "sigs.k8s.io/gcp-compute-persistent-disk-csi-driver/pkg/mount-manager"
+)
+
+// checkVolumeMountGroup checks that the group gid owns path, with the setgid
+// bit and read, write and execute permissions.
+func checkVolumeMountGroup(t *testing.T, path string, gid int) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+	if got := int(info.Sys().(*syscall.Stat_t).Gid); got != gid {
+		t.Errorf("%s is owned by group %d, want %d", path, got, gid)
+	}
+	if info.Mode()&os.ModeSetgid == 0 || info.Mode().Perm()&0o070 != 0o070 {
+		t.Errorf("%s has mode %v, want the setgid bit and group permissions", path, info.Mode())
+	}
+}
+
+func TestSetVolumeMountGroup(t *testing.T) {
+	path := t.TempDir()
+	if err := os.Chmod(path, 0o750); err != nil {
+		t.Fatalf("Failed to chmod %s: %v", path, err)
+	}
+	gid := os.Getgid()
+
+	if err := setVolumeMountGroup(path, gid); err != nil {
+		t.Fatalf("setVolumeMountGroup() failed: %v", err)
+	}
+	checkVolumeMountGroup(t, path, gid)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+	if got, want := info.Mode(), os.ModeDir|os.ModeSetgid|0o770; got != want {
+		t.Errorf("setVolumeMountGroup() set mode %v, want %v", got, want)
+	}
+
+	// Nothing changes once the root directory is set up.
+	if err := setVolumeMountGroup(path, gid); err != nil {
+		t.Fatalf("setVolumeMountGroup() failed the second time: %v", err)
+	}
+	checkVolumeMountGroup(t, path, gid)
+}
+
+func TestNodeStageVolumeMountGroup(t *testing.T) {
+	volumeID := "project/test001/zones/c1/disks/testDisk"
+	gid := os.Getgid()
+
+	testCases := []struct {
+		name       string
+		fsType     string
+		group      string
+		mountFlags []string
+		expOption  string
+		expRootGrp bool
+		expErrCode codes.Code
+	}{
+		{
+			name:       "ext4 root directory",
+			fsType:     "ext4",
+			group:      strconv.Itoa(gid),
+			expRootGrp: true,
+		},
+		{
+			name:      "vfat gid mount option",
+			fsType:    "vfat",
+			group:     "2000",
+			expOption: "gid=2000",
+		},
+		{
+			name:       "vfat explicit gid mount option",
+			fsType:     "vfat",
+			group:      "2000",
+			mountFlags: []string{"gid=3000"},
+			expOption:  "gid=3000",
+		},
+		{
+			name:       "invalid group",
+			fsType:     "ext4",
+			group:      "wheel",
+			expErrCode: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stagingPath := filepath.Join(t.TempDir(), defaultStagingPath)
+			cmds := []fakeCmd{}
+			if tc.expErrCode == codes.OK {
+				cmds = []fakeCmd{
+					{cmd: "blkid", args: "-p -s TYPE -s PTTYPE -o export /dev/disk/fake-path", stdout: "DEVNAME=/dev/sdb\nTYPE=" + tc.fsType},
+					{cmd: "fsck", args: "-a /dev/disk/fake-path"},
+					// The device is read-only, so that the filesystem is not
+					// resized.
+					{cmd: "blockdev", args: "--getro /dev/disk/fake-path", stdout: "1"},
+				}
+			}
+			actionList := []testingexec.FakeCommandAction{}
+			for _, cmd := range cmds {
+				action := []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(cmd.stdout), nil, cmd.err
+					},
+				}
+				actionList = append(actionList, makeFakeCmd(
+					&testingexec.FakeCmd{
+						CombinedOutputScript: action,
+						OutputScript:         action,
+					},
+					cmd.cmd,
+					strings.Split(cmd.args, " ")...,
+				))
+			}
+			mounter := mountmanager.NewFakeSafeMounterWithCustomExec(&testingexec.FakeExec{CommandScript: actionList, ExactOrder: true})
+			ns := getTestGCEDriverWithCustomMounter(t, mounter).ns
+
+			_, err := ns.NodeStageVolume(context.Background(), &csi.NodeStageVolumeRequest{
+				VolumeId:          volumeID,
+				StagingTargetPath: stagingPath,
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{FsType: tc.fsType, MountFlags: tc.mountFlags, VolumeMountGroup: tc.group},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+				},
+			})
+			if got := status.Code(err); got != tc.expErrCode {
+				t.Fatalf("NodeStageVolume() got error code %v, want %v: %v", got, tc.expErrCode, err)
+			}
+			if err != nil {
+				return
+			}
+
+			mountPoints := mounter.Interface.(*mount.FakeMounter).MountPoints
+			if len(mountPoints) != 1 {
+				t.Fatalf("NodeStageVolume() mounted %d times, want 1", len(mountPoints))
+			}
+			gidOptions := slices.DeleteFunc(slices.Clone(mountPoints[0].Opts), func(option string) bool {
+				return !strings.HasPrefix(option, "gid=")
+			})
+			if tc.expOption == "" && len(gidOptions) != 0 || tc.expOption != "" && !slices.Equal(gidOptions, []string{tc.expOption}) {
+				t.Errorf("NodeStageVolume() mounted with gid options %v, want %q", gidOptions, tc.expOption)
+			}
+			if tc.expRootGrp {
+				checkVolumeMountGroup(t, stagingPath, gid)
+			} else if info, err := os.Stat(stagingPath); err == nil && info.Mode()&os.ModeSetgid != 0 {
+				t.Errorf("NodeStageVolume() set the setgid bit of %s", stagingPath)
+			}
+		})
+	}
+}
+
+func TestNodePublishVolumeMountGroup(t *testing.T) {
+	tempDir := t.TempDir()
+	stagingPath := filepath.Join(tempDir, defaultStagingPath)
+	if err := os.MkdirAll(stagingPath, 0o750); err != nil {
+		t.Fatalf("Failed to create %s: %v", stagingPath, err)
+	}
+	gid := os.Getgid()
+	ns := getTestGCEDriver(t).ns
+
+	_, err := ns.NodePublishVolume(context.Background(), &csi.NodePublishVolumeRequest{
+		VolumeId:          "project/test001/zones/c1/disks/testDisk",
+		TargetPath:        filepath.Join(tempDir, defaultTargetPath),
+		StagingTargetPath: stagingPath,
+		VolumeCapability: &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{VolumeMountGroup: strconv.Itoa(gid)},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NodePublishVolume() failed: %v", err)
+	}
+	checkVolumeMountGroup(t, stagingPath, gid)
+}
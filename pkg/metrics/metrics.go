@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"k8s.io/component-base/metrics"
@@ -63,6 +64,44 @@ var (
 	},
 		[]string{"driver_name", "file_system_format", "error_type"},
 	)
+
+	fsckMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "node",
+		Name:           "fsck_runs",
+		Help:           "Node server file system checks of staged volumes, by result",
+		StabilityLevel: metrics.ALPHA,
+	},
+		[]string{"driver_name", "file_system_format", "fsck_policy", "result"},
+	)
+
+	fsckDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      "node",
+		Name:           "fsck_duration_seconds",
+		Help:           "Node server file system check duration of staged volumes",
+		Buckets:        metrics.ExponentialBuckets(0.1, 2, 14),
+		StabilityLevel: metrics.ALPHA,
+	},
+		[]string{"driver_name", "file_system_format", "fsck_policy"},
+	)
+
+	fstrimBytesMetric = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "node",
+		Name:           "fstrim_trimmed_bytes",
+		Help:           "Node server bytes trimmed from the file systems of staged volumes",
+		StabilityLevel: metrics.ALPHA,
+	},
+		[]string{"driver_name", "trigger"},
+	)
+
+	fstrimDurationMetric = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      "node",
+		Name:           "fstrim_duration_seconds",
+		Help:           "Node server trim duration of the file systems of staged volumes, by result",
+		Buckets:        metrics.ExponentialBuckets(0.1, 2, 14),
+		StabilityLevel: metrics.ALPHA,
+	},
+		[]string{"driver_name", "trigger", "result"},
+	)
 )
 
 type MetricsManager struct {
@@ -88,10 +127,27 @@ func (mm *MetricsManager) RegisterPDCSIMetric() {
 	mm.registry.MustRegister(pdcsiOperationErrorsMetric)
 }
 
+// RegisterVolumeLockMetrics registers the metrics of the volume locks.
+func (mm *MetricsManager) RegisterVolumeLockMetrics() {
+	mm.registry.MustRegister(common.VolumeLockMetrics()...)
+}
+
 func (mm *MetricsManager) RegisterMountMetric() {
 	mm.registry.MustRegister(mountErrorMetric)
 }
 
+// RegisterFsckMetrics registers the metrics of the file system checks run
+// when volumes are staged.
+func (mm *MetricsManager) RegisterFsckMetrics() {
+	mm.registry.MustRegister(fsckMetric, fsckDurationMetric)
+}
+
+// RegisterFstrimMetrics registers the metrics of the trims of the file
+// systems of staged volumes.
+func (mm *MetricsManager) RegisterFstrimMetrics() {
+	mm.registry.MustRegister(fstrimBytesMetric, fstrimDurationMetric)
+}
+
 func (mm *MetricsManager) recordComponentVersionMetric() error {
 	v := getEnvVar(envGKEPDCSIVersion)
 	if v == "" {
@@ -121,6 +177,24 @@ func (mm *MetricsManager) RecordMountErrorMetric(fs_format string, err error) {
 	klog.Infof("Recorded mount error type: %q", errType)
 }
 
+// RecordFsckMetric records the result and duration of a file system check.
+// The duration is not recorded if the check did not run.
+func (mm *MetricsManager) RecordFsckMetric(fsFormat, policy, result string, duration time.Duration) {
+	fsckMetric.WithLabelValues(pdcsiDriverName, fsFormat, policy, result).Inc()
+	if duration > 0 {
+		fsckDurationMetric.WithLabelValues(pdcsiDriverName, fsFormat, policy).Observe(duration.Seconds())
+	}
+}
+
+// RecordFstrimMetric records the bytes trimmed by a trim of a file system,
+// and its duration.
+func (mm *MetricsManager) RecordFstrimMetric(trigger, result string, trimmedBytes int64, duration time.Duration) {
+	if trimmedBytes > 0 {
+		fstrimBytesMetric.WithLabelValues(pdcsiDriverName, trigger).Add(float64(trimmedBytes))
+	}
+	fstrimDurationMetric.WithLabelValues(pdcsiDriverName, trigger, result).Observe(duration.Seconds())
+}
+
 func (mm *MetricsManager) EmmitProcessStartTime() error {
 	return metrics.RegisterProcessStartTime(mm.registry.Register)
 }